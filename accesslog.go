@@ -0,0 +1,52 @@
+package xmlrpc
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+)
+
+/*
+AccessLogRecord is structured record about single xmlrpc call
+*/
+type AccessLogRecord struct {
+	Time        time.Time     `json:"time"`
+	RequestID   string        `json:"request_id"`
+	RemoteAddr  string        `json:"remote_addr"`
+	Caller      string        `json:"caller,omitempty"`
+	Method      string        `json:"method"`
+	Duration    time.Duration `json:"duration"`
+	FaultCode   int           `json:"fault_code,omitempty"`
+	FaultString string        `json:"fault_string,omitempty"`
+}
+
+/*
+AccessLogger receives access log record for every call served by handler
+*/
+type AccessLogger interface {
+	LogAccess(record AccessLogRecord)
+}
+
+/*
+NewAccessLogger returns AccessLogger that writes records as json lines to given writer
+*/
+func NewAccessLogger(w io.Writer) AccessLogger {
+	return &jsonAccessLogger{
+		encoder: json.NewEncoder(w),
+	}
+}
+
+/*
+jsonAccessLogger writes records as json lines
+*/
+type jsonAccessLogger struct {
+	mutex   sync.Mutex
+	encoder *json.Encoder
+}
+
+func (j *jsonAccessLogger) LogAccess(record AccessLogRecord) {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+	j.encoder.Encode(record)
+}
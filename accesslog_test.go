@@ -0,0 +1,79 @@
+package xmlrpc
+
+import (
+	"strings"
+	"sync"
+	"testing"
+)
+
+/*
+testAccessLogger keeps logged records
+*/
+type testAccessLogger struct {
+	mutex   sync.Mutex
+	records []AccessLogRecord
+}
+
+func (t *testAccessLogger) LogAccess(record AccessLogRecord) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.records = append(t.records, record)
+}
+
+/*
+last returns last logged record
+*/
+func (t *testAccessLogger) last() AccessLogRecord {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if len(t.records) == 0 {
+		return AccessLogRecord{}
+	}
+	return t.records[len(t.records)-1]
+}
+
+func TestRequestID(t *testing.T) {
+	logger := &testAccessLogger{}
+	service := &testService{name: "test"}
+	h := NewHandler(WithAccessLogger(logger), WithFaultRequestID())
+	if err := h.AddService(service, "test"); err != nil {
+		t.Fatalf("cannot add service: %v", err)
+	}
+
+	// request id of caller is passed to method, returned and logged
+	request := newTestCall("test.Echo", "")
+	request.Header.Set(RequestIDHeader, "caller-id")
+	response := serveTestCall(h, request)
+
+	if id := response.Header().Get(RequestIDHeader); id != "caller-id" {
+		t.Errorf("expected response request id caller-id, got %q", id)
+	}
+	if _, ctx := service.called(); ctx == nil || RequestIDFromContext(ctx) != "caller-id" {
+		t.Errorf("expected request id caller-id in context of method")
+	}
+	if record := logger.last(); record.RequestID != "caller-id" || record.Method != "test.Echo" || record.FaultCode != 0 {
+		t.Errorf("unexpected access log record %+v", record)
+	}
+
+	// request id is generated when caller doesn't send it
+	response = serveTestCall(h, newTestCall("test.Echo", ""))
+	id := response.Header().Get(RequestIDHeader)
+	if id == "" {
+		t.Errorf("expected generated request id")
+	}
+	if record := logger.last(); record.RequestID != id {
+		t.Errorf("expected logged request id %v, got %v", id, record.RequestID)
+	}
+
+	// faults carry request id and their code is logged
+	request = newTestCall("test.Missing", "")
+	request.Header.Set(RequestIDHeader, "fault-id")
+	response = serveTestCall(h, request)
+
+	if body := response.Body.String(); !strings.Contains(body, "<fault>") || !strings.Contains(body, "fault-id") {
+		t.Errorf("expected fault with request id, got %v", body)
+	}
+	if record := logger.last(); record.Method != "test.Missing" || record.FaultCode == 0 {
+		t.Errorf("expected logged fault, got %+v", record)
+	}
+}
@@ -0,0 +1,53 @@
+package xmlrpc
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"time"
+)
+
+const (
+	// ApacheNamespace is namespace of extensions of Apache ws-xmlrpc library (ex:nil, ex:i8, ex:dateTime)
+	ApacheNamespace = "http://ws.apache.org/xmlrpc/namespaces/extensions"
+
+	// apacheDateTimeLayout is layout of ex:dateTime values
+	apacheDateTimeLayout = "2006-01-02T15:04:05.000Z07:00"
+)
+
+/*
+ApacheExtension returns raw value of Apache extension element with given name (e.g. "i8") and text
+*/
+func ApacheExtension(name, text string) RawValue {
+	buf := &bytes.Buffer{}
+	writeApacheExtension(buf, name, text)
+	return RawValue(buf.Bytes())
+}
+
+/*
+ApacheDateTime returns raw value of <ex:dateTime>, t is converted to location of policy stored in context
+*/
+func ApacheDateTime(ctx context.Context, t time.Time) RawValue {
+	return ApacheExtension("dateTime", t.In(DateTimePolicyFromContext(ctx).location()).Format(apacheDateTimeLayout))
+}
+
+/*
+RawApache returns value encoded as content of value element with Apache extensions (see gen.WithApacheExtensions),
+ints that don't fit 32 bits are written as <ex:i8>
+*/
+func (v *Value) RawApache() RawValue {
+	buf := &bytes.Buffer{}
+	v.writeTo(buf, true)
+	return RawValue(buf.Bytes())
+}
+
+func writeApacheExtension(buf io.StringWriter, name, text string) {
+	buf.WriteString(`<ex:` + name + ` xmlns:ex="` + ApacheNamespace + `"`)
+	if text == "" {
+		buf.WriteString("/>")
+		return
+	}
+	buf.WriteString(">")
+	XMLWriteEscaped(buf, text)
+	buf.WriteString("</ex:" + name + ">")
+}
@@ -0,0 +1,183 @@
+package xmlrpc
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/textproto"
+	"strings"
+)
+
+/*
+Attachment is binary part of multipart/related request, params reference it by its content id as string
+"cid:<content id>"
+*/
+type Attachment struct {
+	// ContentID identifies attachment (without angle brackets)
+	ContentID string
+
+	ContentType string
+
+	Data []byte
+}
+
+/*
+String returns reference of attachment used in params ("cid:<content id>")
+*/
+func (a Attachment) String() string {
+	return "cid:" + a.ContentID
+}
+
+const (
+	// attachmentsRootID is content id of root part written by WriteMultipart
+	attachmentsRootID = "xmlrpc-call"
+)
+
+/*
+WithAttachments accepts multipart/related requests, root part (part named by "start" parameter or first part) is
+the call and other parts are attachments available to service methods by AttachmentFromContext. Total size of
+attachments is limited by maxSize (0 means unlimited).
+*/
+func WithAttachments(maxSize int64) HandlerOption {
+	return func(h *handler) {
+		h.attachments = true
+		h.maxAttachmentsSize = maxSize
+	}
+}
+
+/*
+ContextWithAttachments returns context with attachments of call
+*/
+func ContextWithAttachments(ctx context.Context, attachments []Attachment) context.Context {
+	return context.WithValue(ctx, attachmentsContextKey, attachments)
+}
+
+/*
+AttachmentFromContext returns attachment of call referenced by ref ("cid:id", "<id>" or "id")
+*/
+func AttachmentFromContext(ctx context.Context, ref string) (attachment Attachment, ok bool) {
+	id := strings.Trim(strings.TrimPrefix(strings.TrimSpace(ref), "cid:"), "<>")
+	attachments, _ := ctx.Value(attachmentsContextKey).([]Attachment)
+	for _, attachment = range attachments {
+		if attachment.ContentID == id {
+			return attachment, true
+		}
+	}
+	return Attachment{}, false
+}
+
+/*
+isMultipart returns whether content type is multipart/related
+*/
+func isMultipart(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && strings.EqualFold(mediaType, "multipart/related")
+}
+
+/*
+readMultipart reads parts of multipart/related request, returns root part and context with attachments. Media
+type of root part is checked against allowed content types.
+*/
+func readMultipart(ctx context.Context, contentType string, body io.Reader, allowed []string, maxSize int64) (context.Context, io.Reader, error) {
+	_, params, err := mime.ParseMediaType(contentType)
+	if err != nil || params["boundary"] == "" {
+		return ctx, nil, Errorf(FaultInvalidRequest, "invalid content type %v", contentType)
+	}
+	start := strings.Trim(params["start"], "<>")
+
+	var (
+		root        []byte
+		attachments []Attachment
+		size        int64
+	)
+
+	reader := multipart.NewReader(body, params["boundary"])
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return ctx, nil, Errorf(FaultInvalidRequest, "invalid multipart request: %v", err)
+		}
+
+		id := strings.Trim(part.Header.Get("Content-Id"), "<>")
+		if root == nil && (start == "" || start == id) {
+			if err = checkContentType(part.Header.Get("Content-Type"), allowed); err != nil {
+				return ctx, nil, err
+			}
+			if root, err = ioutil.ReadAll(part); err != nil {
+				return ctx, nil, Errorf(FaultInvalidRequest, "cannot read call: %v", err)
+			}
+			continue
+		}
+
+		var data io.Reader = part
+		if maxSize > 0 {
+			data = io.LimitReader(part, maxSize-size+1)
+		}
+		attachment := Attachment{ContentID: id, ContentType: part.Header.Get("Content-Type")}
+		if attachment.Data, err = ioutil.ReadAll(data); err != nil {
+			return ctx, nil, Errorf(FaultInvalidRequest, "cannot read attachment %v: %v", id, err)
+		}
+		if size += int64(len(attachment.Data)); maxSize > 0 && size > maxSize {
+			return ctx, nil, Errorf(FaultInvalidRequest, "attachments exceed %v bytes", maxSize)
+		}
+		attachments = append(attachments, attachment)
+	}
+
+	if root == nil {
+		return ctx, nil, Errorf(FaultInvalidRequest, "multipart request without call")
+	}
+
+	return ContextWithAttachments(ctx, attachments), bytes.NewReader(root), nil
+}
+
+/*
+WriteMultipart writes call with attachments as multipart/related body and returns its content type (clients set
+it as Content-Type header)
+*/
+func WriteMultipart(w io.Writer, call io.Reader, attachments ...Attachment) (contentType string, err error) {
+	writer := multipart.NewWriter(w)
+
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Type", "text/xml; charset=utf-8")
+	header.Set("Content-Id", "<"+attachmentsRootID+">")
+
+	var part io.Writer
+	if part, err = writer.CreatePart(header); err != nil {
+		return
+	}
+	if _, err = io.Copy(part, call); err != nil {
+		return
+	}
+
+	for _, attachment := range attachments {
+		header = textproto.MIMEHeader{}
+		if attachment.ContentType != "" {
+			header.Set("Content-Type", attachment.ContentType)
+		} else {
+			header.Set("Content-Type", "application/octet-stream")
+		}
+		header.Set("Content-Id", "<"+attachment.ContentID+">")
+		if part, err = writer.CreatePart(header); err != nil {
+			return
+		}
+		if _, err = part.Write(attachment.Data); err != nil {
+			return
+		}
+	}
+
+	if err = writer.Close(); err != nil {
+		return
+	}
+
+	return mime.FormatMediaType("multipart/related", map[string]string{
+		"type":     "text/xml",
+		"start":    "<" + attachmentsRootID + ">",
+		"boundary": writer.Boundary(),
+	}), nil
+}
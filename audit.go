@@ -0,0 +1,186 @@
+package xmlrpc
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/beevik/etree"
+)
+
+const (
+	// auditMaxString is length of strings kept in audited params
+	auditMaxString = 64
+
+	// auditMaxItems is count of array items kept in audited params
+	auditMaxItems = 16
+)
+
+/*
+AuditRecord is record about single call. Params are summary of call params (struct with param names as members),
+redacted values are "***", long strings and arrays are shortened and binary data is replaced by its size.
+*/
+type AuditRecord struct {
+	Time        time.Time     `json:"time"`
+	RequestID   string        `json:"request_id"`
+	Caller      string        `json:"caller,omitempty"`
+	Method      string        `json:"method"`
+	Params      *Value        `json:"params,omitempty"`
+	Result      string        `json:"result"`
+	FaultCode   int           `json:"fault_code,omitempty"`
+	FaultString string        `json:"fault_string,omitempty"`
+	Duration    time.Duration `json:"duration"`
+}
+
+/*
+AuditSink receives audit record for every call served by handler
+*/
+type AuditSink interface {
+	Audit(record AuditRecord)
+}
+
+/*
+WithAudit sets sink that receives audit record for every call. Params and struct members with given names
+(case insensitive, e.g. "password", "token") are redacted, params are named by ParamNamesLister of service (or by
+their position).
+*/
+func WithAudit(sink AuditSink, redact ...string) HandlerOption {
+	return func(h *handler) {
+		h.auditSink = sink
+		h.auditRedact = map[string]bool{}
+		for _, name := range redact {
+			h.auditRedact[strings.ToLower(name)] = true
+		}
+	}
+}
+
+/*
+audit sends audit record of call to audit sink
+*/
+func (h *handler) audit(ctx context.Context, start time.Time, method string, params *etree.Element, err error) {
+	record := AuditRecord{
+		Time:      start,
+		RequestID: RequestIDFromContext(ctx),
+		Method:    method,
+		Result:    "ok",
+		Duration:  time.Since(start),
+	}
+	if identity := IdentityFromContext(ctx); identity != nil {
+		record.Caller = identity.Name
+	}
+	if params != nil {
+		record.Params = h.auditParams(ctx, method, params)
+	}
+	if err != nil {
+		record.Result = "fault"
+		record.FaultCode = errorCode(err)
+		record.FaultString = err.Error()
+	}
+	h.auditSink.Audit(record)
+}
+
+/*
+auditParams returns summary of params
+*/
+func (h *handler) auditParams(ctx context.Context, method string, params *etree.Element) *Value {
+	var names []string
+	service, serviceMethod := splitMethod(method)
+	if s, ok := h.service(ctx, service); ok {
+		if lister, ok := s.(ParamNamesLister); ok {
+			names = lister.MethodParamNames()[serviceMethod]
+		}
+	}
+
+	result := NewStruct()
+	for i, element := range XPathParams(params) {
+		name := strconv.Itoa(i)
+		if i < len(names) {
+			name = names[i]
+		}
+
+		value, err := XPathValueGetValue(element, name)
+		if err != nil {
+			result.Set(name, NewString("<invalid>"))
+			continue
+		}
+		result.Set(name, h.auditValue(name, value))
+	}
+	return result
+}
+
+/*
+auditValue returns redacted and shortened value
+*/
+func (h *handler) auditValue(name string, value *Value) *Value {
+	if h.auditRedact[strings.ToLower(name)] {
+		return NewString("***")
+	}
+
+	switch value.Kind() {
+	case KindString:
+		if text := value.Text(); len(text) > auditMaxString {
+			return NewString(text[:auditMaxString] + "...")
+		}
+	case KindBase64:
+		return NewString("<" + strconv.Itoa(len(value.Bytes())) + " bytes>")
+	case KindStruct:
+		result := NewStruct()
+		for _, member := range value.Names() {
+			result.Set(member, h.auditValue(member, value.Member(member)))
+		}
+		return result
+	case KindArray:
+		result := NewArray()
+		for i, item := range value.Items() {
+			if i == auditMaxItems {
+				result.Append(NewString("<" + strconv.Itoa(value.Len()-auditMaxItems) + " more>"))
+				break
+			}
+			result.Append(h.auditValue("", item))
+		}
+		return result
+	}
+	return value
+}
+
+/*
+NewAuditFileSink opens (or creates) file and returns sink that appends records to it as json lines
+*/
+func NewAuditFileSink(filename string) (*AuditFileSink, error) {
+	file, err := os.OpenFile(filename, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, err
+	}
+	return &AuditFileSink{file: file, encoder: json.NewEncoder(file)}, nil
+}
+
+/*
+AuditFileSink writes audit records as json lines to file
+*/
+type AuditFileSink struct {
+	mutex   sync.Mutex
+	file    *os.File
+	encoder *json.Encoder
+}
+
+/*
+Audit satisfies AuditSink interface
+*/
+func (a *AuditFileSink) Audit(record AuditRecord) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	a.encoder.Encode(record)
+}
+
+/*
+Close closes file
+*/
+func (a *AuditFileSink) Close() error {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return a.file.Close()
+}
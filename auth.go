@@ -0,0 +1,154 @@
+package xmlrpc
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+var (
+	ErrUnauthorized = Errorf(401, "unauthorized")
+	ErrForbidden    = Errorf(403, "forbidden")
+)
+
+/*
+CredentialChecker checks credentials given by caller and returns its identity.
+For basic auth username and password are given, for token auth username is empty and secret is token.
+*/
+type CredentialChecker interface {
+	CheckCredentials(username, secret string) (identity *Identity, ok bool)
+}
+
+/*
+CredentialCheckerFunc is function that satisfies CredentialChecker
+*/
+type CredentialCheckerFunc func(username, secret string) (*Identity, bool)
+
+/*
+CheckCredentials satisfies CredentialChecker interface
+*/
+func (c CredentialCheckerFunc) CheckCredentials(username, secret string) (*Identity, bool) {
+	return c(username, secret)
+}
+
+/*
+BasicAuth returns middleware that authenticates callers with http basic authentication.
+Identity returned by checker is stored in request context (see IdentityFromContext).
+*/
+func BasicAuth(realm string, checker CredentialChecker) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			username, password, ok := r.BasicAuth()
+			if !ok {
+				w.Header().Set("WWW-Authenticate", fmt.Sprintf("Basic realm=%q", realm))
+				writeFault(w, r, ErrUnauthorized)
+				return
+			}
+
+			identity, ok := checker.CheckCredentials(username, password)
+			if !ok {
+				writeFault(w, r, ErrUnauthorized)
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(ContextWithIdentity(r.Context(), identity)))
+		})
+	}
+}
+
+/*
+TokenAuth returns middleware that authenticates callers by token. Token is read from given header,
+if header is empty token is read from "Authorization: Bearer <token>".
+Identity returned by checker is stored in request context (see IdentityFromContext).
+*/
+func TokenAuth(header string, checker CredentialChecker) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var token string
+
+			if header == "" {
+				if auth := r.Header.Get("Authorization"); strings.HasPrefix(auth, "Bearer ") {
+					token = strings.TrimSpace(strings.TrimPrefix(auth, "Bearer "))
+				}
+			} else {
+				token = r.Header.Get(header)
+			}
+
+			if token == "" {
+				writeFault(w, r, ErrUnauthorized)
+				return
+			}
+
+			identity, ok := checker.CheckCredentials("", token)
+			if !ok {
+				writeFault(w, r, ErrUnauthorized)
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(ContextWithIdentity(r.Context(), identity)))
+		})
+	}
+}
+
+/*
+IPAllowlist returns middleware that allows only callers from given addresses. Addresses can be either
+single ips ("10.0.0.1") or networks in CIDR notation ("10.0.0.0/8").
+Address is taken from connection, X-Forwarded-For is not trusted.
+*/
+func IPAllowlist(addresses ...string) (Middleware, error) {
+	networks := make([]*net.IPNet, 0, len(addresses))
+
+	for _, address := range addresses {
+		if !strings.Contains(address, "/") {
+			ip := net.ParseIP(address)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid ip address %v", address)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			networks = append(networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+
+		_, network, err := net.ParseCIDR(address)
+		if err != nil {
+			return nil, err
+		}
+		networks = append(networks, network)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			host, _, err := net.SplitHostPort(r.RemoteAddr)
+			if err != nil {
+				host = r.RemoteAddr
+			}
+
+			if ip := net.ParseIP(host); ip != nil {
+				for _, network := range networks {
+					if network.Contains(ip) {
+						next.ServeHTTP(w, r)
+						return
+					}
+				}
+			}
+
+			writeFault(w, r, ErrForbidden)
+		})
+	}, nil
+}
+
+/*
+MustIPAllowlist is IPAllowlist that panics when address is invalid, so it can be used directly in middleware chains
+with addresses known at compile time
+*/
+func MustIPAllowlist(addresses ...string) Middleware {
+	allowlist, err := IPAllowlist(addresses...)
+	if err != nil {
+		panic(err)
+	}
+	return allowlist
+}
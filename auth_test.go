@@ -0,0 +1,139 @@
+package xmlrpc
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+/*
+serveAuth serves request by middleware and returns identity seen by next handler and whether it was called
+*/
+func serveAuth(middleware Middleware, request *http.Request) (identity *Identity, called bool, response *httptest.ResponseRecorder) {
+	response = httptest.NewRecorder()
+	middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		identity, called = IdentityFromContext(r.Context()), true
+	})).ServeHTTP(response, request)
+	return
+}
+
+var testChecker = CredentialCheckerFunc(func(username, secret string) (*Identity, bool) {
+	if (username == "admin" || username == "") && secret == "secret" {
+		return &Identity{Name: "admin"}, true
+	}
+	return nil, false
+})
+
+func TestBasicAuth(t *testing.T) {
+	for _, item := range []struct {
+		username string
+		password string
+		ok       bool
+	}{
+		{"admin", "secret", true},
+		{"admin", "wrong", false},
+		{"other", "secret", false},
+		{"", "", false},
+	} {
+		request := httptest.NewRequest("POST", "/", strings.NewReader("<methodCall/>"))
+		if item.username != "" {
+			request.SetBasicAuth(item.username, item.password)
+		}
+
+		identity, called, response := serveAuth(BasicAuth("xmlrpc", testChecker), request)
+		if called != item.ok {
+			t.Errorf("%v:%v: expected call %v, got %v", item.username, item.password, item.ok, called)
+			continue
+		}
+		if item.ok && (identity == nil || identity.Name != "admin") {
+			t.Errorf("%v:%v: expected identity admin, got %v", item.username, item.password, identity)
+		}
+		if !item.ok && !strings.Contains(response.Body.String(), "<fault>") {
+			t.Errorf("%v:%v: expected fault, got %v", item.username, item.password, response.Body.String())
+		}
+		if item.username == "" && response.Header().Get("WWW-Authenticate") != `Basic realm="xmlrpc"` {
+			t.Errorf("expected challenge, got %q", response.Header().Get("WWW-Authenticate"))
+		}
+	}
+}
+
+func TestTokenAuth(t *testing.T) {
+	for _, item := range []struct {
+		header string
+		name   string
+		value  string
+		ok     bool
+	}{
+		{"", "Authorization", "Bearer secret", true},
+		{"", "Authorization", "Bearer wrong", false},
+		{"", "Authorization", "Basic secret", false},
+		{"X-Token", "X-Token", "secret", true},
+		{"X-Token", "Authorization", "Bearer secret", false},
+	} {
+		request := httptest.NewRequest("POST", "/", strings.NewReader("<methodCall/>"))
+		request.Header.Set(item.name, item.value)
+
+		identity, called, response := serveAuth(TokenAuth(item.header, testChecker), request)
+		if called != item.ok {
+			t.Errorf("%v: %v: expected call %v, got %v", item.name, item.value, item.ok, called)
+			continue
+		}
+		if item.ok && (identity == nil || identity.Name != "admin") {
+			t.Errorf("%v: %v: expected identity admin, got %v", item.name, item.value, identity)
+		}
+		if !item.ok && !strings.Contains(response.Body.String(), "<fault>") {
+			t.Errorf("%v: %v: expected fault, got %v", item.name, item.value, response.Body.String())
+		}
+	}
+}
+
+func TestIPAllowlist(t *testing.T) {
+	allowlist, err := IPAllowlist("10.0.0.0/8", "192.168.1.1", "::1")
+	if err != nil {
+		t.Fatalf("cannot create allowlist: %v", err)
+	}
+
+	for _, item := range []struct {
+		address string
+		ok      bool
+	}{
+		{"10.1.2.3:1234", true},
+		{"192.168.1.1:1234", true},
+		{"[::1]:1234", true},
+		{"192.168.1.2:1234", false},
+		{"11.0.0.1:1234", false},
+		{"invalid", false},
+	} {
+		request := httptest.NewRequest("POST", "/", strings.NewReader("<methodCall/>"))
+		request.RemoteAddr = item.address
+		request.Header.Set("X-Forwarded-For", "10.0.0.1")
+
+		_, called, response := serveAuth(allowlist, request)
+		if called != item.ok {
+			t.Errorf("%v: expected call %v, got %v", item.address, item.ok, called)
+		}
+		if !item.ok && !strings.Contains(response.Body.String(), "<fault>") {
+			t.Errorf("%v: expected fault, got %v", item.address, response.Body.String())
+		}
+	}
+
+	for _, address := range []string{"10.0.0.256", "10.0.0.0/33", "host"} {
+		if _, err := IPAllowlist(address); err == nil {
+			t.Errorf("%v: expected error", address)
+		}
+	}
+}
+
+func TestMustIPAllowlist(t *testing.T) {
+	if MustIPAllowlist("10.0.0.0/8") == nil {
+		t.Errorf("expected middleware")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected panic")
+		}
+	}()
+	MustIPAllowlist("host")
+}
@@ -0,0 +1,180 @@
+package xmlrpc
+
+import (
+	"context"
+	"encoding/base64"
+	"io"
+	"strings"
+
+	"github.com/beevik/etree"
+)
+
+const (
+	// base64ChunkSize is size of chunk read from reader when base64 response is written
+	base64ChunkSize = 32 * 1024
+
+	// MaxReaderSize is maximum decoded size of base64 value read by XPathValueGetReader
+	MaxReaderSize = 64 << 20
+)
+
+/*
+FormatBase64 returns base64 representation of data
+*/
+func FormatBase64(data []byte) string {
+	return base64.StdEncoding.EncodeToString(data)
+}
+
+/*
+XPathValueGetBase64 Returns decoded data of base64 value
+*/
+func XPathValueGetBase64(element *etree.Element, name string) (result []byte, err error) {
+	var tmp *etree.Element
+
+	if tmp = childElement(element, "base64"); tmp == nil {
+		err = Errorf(FaultInvalidParams, "not found %v", name)
+		return
+	}
+
+	if result, err = base64.StdEncoding.DecodeString(stripSpace(tmp.Text())); err != nil {
+		err = Errorf(FaultInvalidParams, "invalid base64 %v", name)
+	}
+
+	return
+}
+
+/*
+XPathValueGetReader Returns reader that decodes base64 value while it's read, so decoded data are never held in
+memory (text of value is part of request document though). Values bigger than MaxReaderSize are rejected.
+Invalid base64 is reported by Read.
+*/
+func XPathValueGetReader(element *etree.Element, name string) (result io.Reader, err error) {
+	return XPathValueGetReaderLimit(element, name, MaxReaderSize)
+}
+
+/*
+XPathValueGetReaderLimit is XPathValueGetReader with values bigger than max bytes (when decoded) rejected
+*/
+func XPathValueGetReaderLimit(element *etree.Element, name string, max int) (result io.Reader, err error) {
+	var tmp *etree.Element
+
+	if tmp = childElement(element, "base64"); tmp == nil {
+		err = Errorf(FaultInvalidParams, "not found %v", name)
+		return
+	}
+
+	text := tmp.Text()
+	if base64.RawStdEncoding.DecodedLen(countData(text)) > max {
+		err = Errorf(FaultInvalidParams, "base64 value %v has more than %d bytes", name, max)
+		return
+	}
+
+	result = base64.NewDecoder(base64.StdEncoding, &spaceSkippingReader{reader: strings.NewReader(text)})
+	return
+}
+
+/*
+Base64Response returns response with data read from reader written as base64 value. Data are read and encoded in
+chunks, so whole result never needs to be in memory. Reader is closed if it's io.Closer, nil reader is empty value.
+As with streamed arrays, fault cannot be returned once writing starts, so when reading fails (or reader panics)
+response is left unfinished.
+*/
+func Base64Response(ctx context.Context, reader io.Reader) io.WriterTo {
+	if reader == nil {
+		reader = strings.NewReader("")
+	}
+	return WriterToFunc(func(w io.Writer) (int64, error) {
+		if closer, ok := reader.(io.Closer); ok {
+			defer closer.Close()
+		}
+
+		stream := NewStreamWriter(w)
+		stream.Buffer().WriteString("<?xml version=\"1.0\" encoding=\"UTF-8\"?><methodResponse><params><param><value><base64>")
+		if err := stream.Flush(); err != nil {
+			return stream.Result()
+		}
+
+		encoder := base64.NewEncoder(base64.StdEncoding, stream.Buffer())
+		chunk := make([]byte, base64ChunkSize)
+
+		for {
+			// stop writing when call is cancelled (e.g. client disconnected)
+			if err := ctx.Err(); err != nil {
+				n, _ := stream.Result()
+				return n, err
+			}
+
+			read, err := reader.Read(chunk)
+			if read > 0 {
+				encoder.Write(chunk[:read])
+				if werr := stream.Flush(); werr != nil {
+					return stream.Result()
+				}
+			}
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				n, _ := stream.Result()
+				return n, err
+			}
+		}
+
+		encoder.Close()
+		stream.Buffer().WriteString("</base64></value></param></params></methodResponse>")
+		stream.Flush()
+		return stream.Result()
+	})
+}
+
+/*
+stripSpace removes all whitespace from base64 text (encoders often wrap lines)
+*/
+func stripSpace(text string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '\t', '\r', '\n':
+			return -1
+		}
+		return r
+	}, text)
+}
+
+/*
+countData returns length of base64 text without whitespace and padding (without copying it as stripSpace does)
+*/
+func countData(text string) (count int) {
+	for i := 0; i < len(text); i++ {
+		switch text[i] {
+		case ' ', '\t', '\r', '\n', '=':
+		default:
+			count++
+		}
+	}
+	return
+}
+
+/*
+spaceSkippingReader removes whitespace from base64 text while it's read
+*/
+type spaceSkippingReader struct {
+	reader io.Reader
+}
+
+/*
+Read reads from underlying reader and drops whitespace, it reads again when whole chunk was whitespace
+*/
+func (s *spaceSkippingReader) Read(p []byte) (n int, err error) {
+	for n == 0 && err == nil {
+		var read int
+		read, err = s.reader.Read(p)
+		for _, c := range p[:read] {
+			switch c {
+			case ' ', '\t', '\r', '\n':
+			default:
+				p[n] = c
+				n++
+			}
+		}
+	}
+	return
+}
@@ -0,0 +1,139 @@
+package xmlrpc
+
+import (
+	"context"
+)
+
+/*
+Caller calls method with params (e.g. PipeClient or ConnClient)
+*/
+type Caller interface {
+	Call(ctx context.Context, method string, params ...*Value) (*Value, error)
+}
+
+/*
+BatchCall is single call of batch
+*/
+type BatchCall struct {
+	Method string
+	Params []*Value
+}
+
+/*
+BatchResult is result of BatchCall, Err is *Fault when call failed with fault (each call has its own fault)
+*/
+type BatchResult struct {
+	Value *Value
+	Err   error
+}
+
+/*
+Batch runs independent calls concurrently by at most workers calls at once and returns their results in order of
+calls, it's fan-out for servers without system.multicall. Batch returns when all calls finish or when context is
+done, calls that didn't finish by then have context error as result. Caller is called concurrently by workers, it
+must be safe for concurrent use (all clients of this package are).
+*/
+func Batch(ctx context.Context, caller Caller, workers int, calls []BatchCall) []BatchResult {
+	if workers < 1 {
+		workers = 1
+	}
+
+	type indexed struct {
+		index  int
+		result BatchResult
+	}
+
+	// channels are buffered so workers never block after Batch returned
+	jobs := make(chan int, len(calls))
+	for i := range calls {
+		jobs <- i
+	}
+	close(jobs)
+	finished := make(chan indexed, len(calls))
+
+	for w := 0; w < workers && w < len(calls); w++ {
+		go func() {
+			for i := range jobs {
+				if err := ctx.Err(); err != nil {
+					finished <- indexed{i, BatchResult{Err: err}}
+					continue
+				}
+				value, err := caller.Call(ctx, calls[i].Method, calls[i].Params...)
+				finished <- indexed{i, BatchResult{Value: value, Err: err}}
+			}
+		}()
+	}
+
+	results := make([]BatchResult, len(calls))
+	done := make([]bool, len(calls))
+	for remaining := len(calls); remaining > 0; remaining-- {
+		select {
+		case f := <-finished:
+			results[f.index] = f.result
+			done[f.index] = true
+		case <-ctx.Done():
+			for i := range results {
+				if !done[i] {
+					results[i] = BatchResult{Err: ctx.Err()}
+				}
+			}
+			return results
+		}
+	}
+
+	return results
+}
+
+/*
+Multicall sends calls in single system.multicall call and returns their results in order of calls. Faults of
+calls are returned as *Fault in their result (with reconstructed error chains, see RegisterErrorKind), error is
+returned only when system.multicall itself fails.
+*/
+func Multicall(ctx context.Context, caller Caller, calls []BatchCall) ([]BatchResult, error) {
+	param := NewArray()
+	for _, call := range calls {
+		param.Append(NewStruct().
+			Set("methodName", NewString(call.Method)).
+			Set("params", NewArray(call.Params...)))
+	}
+
+	response, err := caller.Call(ctx, "system.multicall", param)
+	if err != nil {
+		return nil, err
+	}
+	if response.Kind() != KindArray || response.Len() != len(calls) {
+		return nil, Errorf(FaultTransportError, "system.multicall returned %v results for %v calls", response.Len(), len(calls))
+	}
+
+	results := make([]BatchResult, len(calls))
+	for i, item := range response.Items() {
+		switch item.Kind() {
+		case KindArray:
+			if item.Len() != 1 {
+				results[i].Err = Errorf(FaultTransportError, "result %v of system.multicall has %v values", i, item.Len())
+				continue
+			}
+			results[i].Value = item.Index(0)
+		case KindStruct:
+			results[i].Err = multicallFault(item)
+		default:
+			results[i].Err = Errorf(FaultTransportError, "result %v of system.multicall is %v", i, item.Kind())
+		}
+	}
+	return results, nil
+}
+
+/*
+multicallFault returns fault of call from fault struct of system.multicall result
+*/
+func multicallFault(value *Value) error {
+	element := NewElement("fault")
+	if err := XMLWriteRaw(element.CreateElement("value"), value.Raw()); err != nil {
+		return err
+	}
+	fault, err := ParseFault(element)
+	if err != nil {
+		return err
+	}
+	return fault
+}
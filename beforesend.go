@@ -0,0 +1,65 @@
+package xmlrpc
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"net/http"
+)
+
+/*
+BeforeSendFunc adjusts call before it's sent to endpoint with quirks (headers, User-Agent), returned body replaces
+body of request (e.g. with nonstandard prolog or comments). Request is copy, it can be modified.
+*/
+type BeforeSendFunc func(request *http.Request, body []byte) ([]byte, error)
+
+/*
+NewBeforeSendTransport returns http transport for clients that calls hook with every request and its body before
+it's sent, error of hook is returned by RoundTrip. Hook is called concurrently by concurrent requests. Nil base is
+http.DefaultTransport.
+*/
+func NewBeforeSendTransport(base http.RoundTripper, hook BeforeSendFunc) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return beforeSendTransport{base: base, hook: hook}
+}
+
+/*
+beforeSendTransport calls hook before requests are sent
+*/
+type beforeSendTransport struct {
+	base http.RoundTripper
+	hook BeforeSendFunc
+}
+
+func (b beforeSendTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	var body []byte
+	if request.Body != nil {
+		var err error
+		body, err = ioutil.ReadAll(request.Body)
+		request.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// request must not be modified, it's cloned
+	clone := request.WithContext(request.Context())
+	clone.Header = http.Header{}
+	for name, values := range request.Header {
+		clone.Header[name] = values
+	}
+
+	body, err := b.hook(clone, body)
+	if err != nil {
+		return nil, err
+	}
+
+	clone.Body = ioutil.NopCloser(bytes.NewReader(body))
+	clone.ContentLength = int64(len(body))
+	clone.GetBody = func() (io.ReadCloser, error) {
+		return ioutil.NopCloser(bytes.NewReader(body)), nil
+	}
+	return b.base.RoundTrip(clone)
+}
@@ -0,0 +1,53 @@
+package benchmarks
+
+import (
+	"context"
+	"io/ioutil"
+	"testing"
+
+	"github.com/beevik/etree"
+)
+
+const (
+	// profileAllocs is budget of generated code that writes small struct result (etree backend) and its response,
+	// it was 120 when budget was set
+	profileAllocs = 130
+
+	// smallAllocs is budget of generated code that decodes small struct param and writes it back, it was 139 when
+	// budget was set
+	smallAllocs = 150
+)
+
+/*
+TestAllocs checks that generated code stays in allocation budget, raise budget only when more allocations are
+worth it (templates must not regress silently)
+*/
+func TestAllocs(t *testing.T) {
+	service := &BenchService{}
+
+	for _, item := range []struct {
+		method string
+		call   []byte
+		budget float64
+	}{
+		{"Profile", []byte(`<methodCall><methodName>bench.Profile</methodName><params/></methodCall>`), profileAllocs},
+		{"Small", callBody(smallPayload()), smallAllocs},
+	} {
+		doc := etree.NewDocument()
+		if err := doc.ReadFromBytes(item.call); err != nil {
+			t.Fatalf("%v: invalid call: %v", item.method, err)
+		}
+		root := doc.FindElement("methodCall/params")
+
+		allocs := testing.AllocsPerRun(100, func() {
+			res, err := service.Dispatch(context.Background(), item.method, root)
+			if err != nil {
+				t.Fatalf("%v: %v", item.method, err)
+			}
+			res.WriteTo(ioutil.Discard)
+		})
+		if allocs > item.budget {
+			t.Errorf("%v: %v allocs, budget is %v", item.method, allocs, item.budget)
+		}
+	}
+}
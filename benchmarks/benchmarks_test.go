@@ -0,0 +1,191 @@
+package benchmarks
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/beevik/etree"
+	xmlrpc "github.com/phonkee/go-xmlrpc"
+)
+
+/*
+payload is value sent as only param of benchmark method
+*/
+type payload struct {
+	method string
+	value  *xmlrpc.Value
+}
+
+/*
+smallPayload returns small struct
+*/
+func smallPayload() payload {
+	value := xmlrpc.NewStruct().
+		Set("Name", xmlrpc.NewString("John Doe")).
+		Set("Age", xmlrpc.NewInt(42)).
+		Set("Active", xmlrpc.NewBool(true)).
+		Set("Score", xmlrpc.NewDouble(98.5)).
+		Set("Tags", xmlrpc.NewArray(xmlrpc.NewString("admin"), xmlrpc.NewString("editor")))
+	return payload{method: "Small", value: value}
+}
+
+/*
+arrayPayload returns array of 10k ints
+*/
+func arrayPayload() payload {
+	value := xmlrpc.NewArray()
+	for i := 0; i < 10000; i++ {
+		value.Append(xmlrpc.NewInt(i))
+	}
+	return payload{method: "Array", value: value}
+}
+
+/*
+nestedPayload returns arrays nested 8 levels deep, every array has 2 items
+*/
+func nestedPayload() payload {
+	var nested func(depth int) *xmlrpc.Value
+	nested = func(depth int) *xmlrpc.Value {
+		if depth == 0 {
+			return xmlrpc.NewString("leaf")
+		}
+		return xmlrpc.NewArray(nested(depth-1), nested(depth-1))
+	}
+	return payload{method: "Nested", value: nested(8)}
+}
+
+/*
+callBody returns methodCall of payload method of service bench
+*/
+func callBody(p payload) []byte {
+	buf := &bytes.Buffer{}
+	buf.WriteString(`<?xml version="1.0"?><methodCall><methodName>bench.` + p.method + `</methodName>`)
+	buf.WriteString("<params><param><value>")
+	p.value.EncodeTo(buf)
+	buf.WriteString("</value></param></params></methodCall>")
+	return buf.Bytes()
+}
+
+/*
+generatedHandler returns handler with service generated by xmlrpcgen
+*/
+func generatedHandler() xmlrpc.Handler {
+	h := xmlrpc.NewHandler()
+	h.AddService(&BenchService{}, "bench")
+	return h
+}
+
+/*
+dynamicHandler returns handler with registry that echoes params converted to go values and back
+*/
+func dynamicHandler() xmlrpc.Handler {
+	echo := func(ctx context.Context, params ...*xmlrpc.Value) (*xmlrpc.Value, error) {
+		if len(params) != 1 {
+			return nil, xmlrpc.Errorf(xmlrpc.FaultInvalidParams, "expected 1 param, got %v", len(params))
+		}
+		return xmlrpc.FromInterface(params[0].ToInterface())
+	}
+
+	registry := xmlrpc.NewRegistry()
+	for _, method := range []string{"Small", "Array", "Nested"} {
+		registry.Register(method, echo)
+	}
+
+	h := xmlrpc.NewHandler()
+	h.AddService(registry, "bench")
+	return h
+}
+
+/*
+discardResponse is http.ResponseWriter that drops written response
+*/
+type discardResponse struct {
+	header http.Header
+}
+
+func (d *discardResponse) Header() http.Header         { return d.header }
+func (d *discardResponse) Write(p []byte) (int, error) { return len(p), nil }
+func (d *discardResponse) WriteHeader(status int)      {}
+
+/*
+serve returns response of handler to call
+*/
+func serve(h xmlrpc.Handler, body []byte) string {
+	request := httptest.NewRequest("POST", "/", bytes.NewReader(body))
+	request.Header.Set("Content-Type", "text/xml")
+	response := httptest.NewRecorder()
+	h.ServeHTTP(response, request)
+	return response.Body.String()
+}
+
+/*
+responseValue returns value of only param of response
+*/
+func responseValue(response string) (*xmlrpc.Value, error) {
+	doc := etree.NewDocument()
+	if err := doc.ReadFromString(response); err != nil {
+		return nil, err
+	}
+
+	params := doc.FindElement("methodResponse/params")
+	if params == nil {
+		return nil, fmt.Errorf("response without params")
+	}
+	values := xmlrpc.XPathParams(params)
+	if len(values) != 1 {
+		return nil, fmt.Errorf("response has %v params", len(values))
+	}
+	return xmlrpc.XPathValueGetValue(values[0], "result")
+}
+
+/*
+benchmarkCall serves call of payload by handler, response is checked once before benchmark starts
+*/
+func benchmarkCall(b *testing.B, h xmlrpc.Handler, p payload) {
+	body := callBody(p)
+	if response := serve(h, body); strings.Contains(response, "<fault>") {
+		b.Fatalf("unexpected fault: %v", response)
+	}
+
+	b.SetBytes(int64(len(body)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		request, _ := http.NewRequest("POST", "/", bytes.NewReader(body))
+		request.Header.Set("Content-Type", "text/xml")
+		h.ServeHTTP(&discardResponse{header: http.Header{}}, request)
+	}
+}
+
+func BenchmarkGeneratedSmall(b *testing.B)  { benchmarkCall(b, generatedHandler(), smallPayload()) }
+func BenchmarkGeneratedArray(b *testing.B)  { benchmarkCall(b, generatedHandler(), arrayPayload()) }
+func BenchmarkGeneratedNested(b *testing.B) { benchmarkCall(b, generatedHandler(), nestedPayload()) }
+func BenchmarkDynamicSmall(b *testing.B)    { benchmarkCall(b, dynamicHandler(), smallPayload()) }
+func BenchmarkDynamicArray(b *testing.B)    { benchmarkCall(b, dynamicHandler(), arrayPayload()) }
+func BenchmarkDynamicNested(b *testing.B)   { benchmarkCall(b, dynamicHandler(), nestedPayload()) }
+
+/*
+TestPayloads checks that generated and dynamic services return same values
+*/
+func TestPayloads(t *testing.T) {
+	for _, p := range []payload{smallPayload(), arrayPayload(), nestedPayload()} {
+		generated := serve(generatedHandler(), callBody(p))
+		dynamic := serve(dynamicHandler(), callBody(p))
+
+		for name, response := range map[string]string{"generated": generated, "dynamic": dynamic} {
+			value, err := responseValue(response)
+			if err != nil {
+				t.Errorf("%v %v: %v", name, p.method, err)
+				continue
+			}
+			if !value.Equal(p.value) {
+				t.Errorf("%v %v: expected echo of payload", name, p.method)
+			}
+		}
+	}
+}
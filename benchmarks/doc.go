@@ -0,0 +1,17 @@
+/*
+Package benchmarks compares code generated by xmlrpcgen with dynamic services (Registry methods converting params
+and results by Value.ToInterface and FromInterface) and with other xmlrpc libraries.
+
+Payloads are small struct, array of 10k ints and arrays nested 8 levels deep. Every benchmark reads whole call
+and writes whole response through handler, so numbers include parsing:
+
+	go test -bench . -benchmem ./benchmarks
+
+Other libraries are not dependencies of this package, their benchmarks are built with tags:
+
+	go get github.com/kolo/xmlrpc
+	go test -tags kolo -bench . -benchmem ./benchmarks
+
+kolo/xmlrpc has no server side, its benchmarks decode response and encode call with same payloads.
+*/
+package benchmarks
@@ -0,0 +1,57 @@
+//go:build kolo
+// +build kolo
+
+package benchmarks
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/kolo/xmlrpc"
+)
+
+/*
+koloSmall is small struct decoded by kolo/xmlrpc
+*/
+type koloSmall struct {
+	Name   string   `xmlrpc:"Name"`
+	Age    int      `xmlrpc:"Age"`
+	Active bool     `xmlrpc:"Active"`
+	Score  float64  `xmlrpc:"Score"`
+	Tags   []string `xmlrpc:"Tags"`
+}
+
+/*
+benchmarkKolo decodes response with payload to pointer returned by result and encodes call with its value
+*/
+func benchmarkKolo(b *testing.B, p payload, result func() interface{}) {
+	response := []byte(serve(generatedHandler(), callBody(p)))
+	if err := xmlrpc.Response(response).Unmarshal(result()); err != nil {
+		b.Fatalf("cannot decode response: %v", err)
+	}
+
+	b.SetBytes(int64(len(response)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		value := result()
+		if err := xmlrpc.Response(response).Unmarshal(value); err != nil {
+			b.Fatal(err)
+		}
+		if _, err := xmlrpc.EncodeMethodCall("bench."+p.method, reflect.ValueOf(value).Elem().Interface()); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkKoloSmall(b *testing.B) {
+	benchmarkKolo(b, smallPayload(), func() interface{} { return &koloSmall{} })
+}
+
+func BenchmarkKoloArray(b *testing.B) {
+	benchmarkKolo(b, arrayPayload(), func() interface{} { return &[]int{} })
+}
+
+func BenchmarkKoloNested(b *testing.B) {
+	benchmarkKolo(b, nestedPayload(), func() interface{} { return &[][][][][][][][]string{} })
+}
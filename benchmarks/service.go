@@ -0,0 +1,69 @@
+//go:generate xmlrpcgen --file $GOFILE BenchService
+
+package benchmarks
+
+import (
+	"context"
+)
+
+var (
+	// profile is result of Profile
+	profile = struct {
+		Name   string
+		Age    int
+		Active bool
+		Score  float64
+		Tags   []string
+	}{"John Doe", 42, true, 98.5, []string{"admin", "editor"}}
+)
+
+/*
+BenchService echoes benchmark payloads, its Dispatch is generated by xmlrpcgen
+*/
+type BenchService struct{}
+
+/*
+Small echoes small struct
+*/
+func (b *BenchService) Small(ctx context.Context, item struct {
+	Name   string
+	Age    int
+	Active bool
+	Score  float64
+	Tags   []string
+}) (struct {
+	Name   string
+	Age    int
+	Active bool
+	Score  float64
+	Tags   []string
+}, error) {
+	return item, nil
+}
+
+/*
+Profile returns small struct, it has no params so only writing of result is measured
+*/
+func (b *BenchService) Profile(ctx context.Context) (struct {
+	Name   string
+	Age    int
+	Active bool
+	Score  float64
+	Tags   []string
+}, error) {
+	return profile, nil
+}
+
+/*
+Array echoes big array
+*/
+func (b *BenchService) Array(ctx context.Context, items []int) ([]int, error) {
+	return items, nil
+}
+
+/*
+Nested echoes deeply nested arrays
+*/
+func (b *BenchService) Nested(ctx context.Context, items [][][][][][][][]string) ([][][][][][][][]string, error) {
+	return items, nil
+}
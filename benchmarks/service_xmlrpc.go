@@ -0,0 +1,1069 @@
+// xmlrpcgen:hash 7f435e3f6940d7d0f5c9c070070c2c26dba4f4789eb5458ee802f88009d65c31
+// This file is autogenerated by xmlrpcgen (generated code version 1)
+// do not change it directly!
+
+package benchmarks
+
+import (
+	"context"
+	xmlrpc "github.com/phonkee/go-xmlrpc"
+	"io"
+	"strconv"
+	"time"
+)
+
+/*
+init checks that runtime supports this code and registers codecs of types that implement xmlrpc.ValueMarshaler
+*/
+func init() {
+	xmlrpc.RequireGeneratedVersion(1)
+}
+
+var (
+	availableMethodsForBenchService = map[string]bool{
+		"Array": true, "Nested": true, "Profile": true, "Small": true,
+	}
+)
+
+/*
+MethodExists returns whether rpc method is available on service
+*/
+func (s *BenchService) MethodExists(method string) (ok bool) {
+	_, ok = availableMethodsForBenchService[method]
+	return
+}
+
+/*
+ListMethods returns list of all available methods for given service
+*/
+func (s *BenchService) ListMethods() []string {
+	result := make([]string, 0, len(availableMethodsForBenchService))
+	for key := range availableMethodsForBenchService {
+		result = append(result, key)
+	}
+	return result
+}
+
+/*
+MethodSignatures returns xmlrpc types of all methods, result type is first followed by types of params
+*/
+func (s *BenchService) MethodSignatures() map[string][]string {
+	return map[string][]string{
+		"Array":   {"array", "array"},
+		"Nested":  {"array", "array"},
+		"Profile": {"struct"},
+		"Small":   {"struct", "struct"},
+	}
+}
+
+/*
+MethodParamNames returns names of params of all methods
+*/
+func (s *BenchService) MethodParamNames() map[string][]string {
+	return map[string][]string{
+		"Array":   {"items"},
+		"Nested":  {"items"},
+		"Profile": {},
+		"Small":   {"item"},
+	}
+}
+
+/*
+MethodHelp returns doc comments of documented methods
+*/
+func (s *BenchService) MethodHelp() map[string]string {
+	return map[string]string{
+		"Array":   "Array echoes big array",
+		"Nested":  "Nested echoes deeply nested arrays",
+		"Profile": "Profile returns small struct, it has no params so only writing of result is measured",
+		"Small":   "Small echoes small struct",
+	}
+}
+
+/*
+MethodRoles returns roles of callers allowed to call methods with "//xmlrpc:roles" directive
+*/
+func (s *BenchService) MethodRoles() map[string][]string {
+	return map[string][]string{}
+}
+
+/*
+MethodTimeouts returns timeouts of methods with "//xmlrpc:timeout" directive, clients apply them by
+xmlrpc.NewTimeoutTransport
+*/
+func (s *BenchService) MethodTimeouts() map[string]time.Duration {
+	return map[string]time.Duration{}
+}
+
+/*
+Dispatch dispatches method on service, do not use this method directly.
+root is params *xmlrpc.Element (actually "methodCall/params"
+*/
+func (s *BenchService) Dispatch(ctx context.Context, method string, root *xmlrpc.Element) (res io.WriterTo, err error) {
+
+	// panic in service method is returned as internal error
+	defer xmlrpc.RecoverPanic("BenchService."+method, &err)
+
+	// call appropriate methods
+	switch method {
+	case "Array":
+
+		// Get parameters from xmlrpc request
+
+		params_2 := xmlrpc.XPathParams(root)
+
+		if len(params_2) < 1 || params_2[0] == nil {
+			err = xmlrpc.Errorf(xmlrpc.FaultInvalidParams, "could not find items")
+			return
+		}
+		v_3 := params_2[0]
+
+		// This is slice implementation of items
+
+		var values_4 []*xmlrpc.Element
+		if values_4, err = xmlrpc.XPathValueGetArray(v_3, "items"); err != nil {
+			return
+		}
+		// only preview of array is decoded when context limits arrays
+		values_4 = xmlrpc.LimitArray(ctx, values_4)
+
+		items := make([]int, 0, len(values_4))
+
+		// Lets iterate over given members.
+		for _, member_5 := range values_4 {
+			// stop decoding when call is cancelled
+			if err = ctx.Err(); err != nil {
+				return
+			}
+
+			var value_6 int
+			if value_6, err = xmlrpc.XPathValueGetInt(member_5, "items"); err != nil {
+				return
+			}
+
+			items = append(items, value_6)
+		}
+
+		// If following method call fails there are 2 possible reasons:
+		// 1. you have either changed method signature or you deleted method. Please re-run "go generate"
+		// 2. you have probably found a bug and you should file issue on github.
+
+		var result_1 []int
+		result_1, err = s.Array(ctx, items)
+
+		// error is written as fault by handler
+		if err != nil {
+			return
+		}
+
+		doc_7 := xmlrpc.NewDocument()
+
+		// here is place where we need to hydrate results
+		v_8 := doc_7.CreateElement("methodResponse").CreateElement("params").CreateElement("param").CreateElement("value")
+
+		array_data_9 := v_8.CreateElement("array").CreateElement("data")
+		for _, item_10 := range result_1 {
+			if err = ctx.Err(); err != nil {
+				return
+			}
+			value_11 := array_data_9.CreateElement("value")
+			value_11.CreateElement("int").SetText(strconv.Itoa(int(item_10)))
+
+		}
+
+		res = doc_7
+
+		return
+
+	case "Nested":
+
+		// Get parameters from xmlrpc request
+
+		params_2 := xmlrpc.XPathParams(root)
+
+		if len(params_2) < 1 || params_2[0] == nil {
+			err = xmlrpc.Errorf(xmlrpc.FaultInvalidParams, "could not find items")
+			return
+		}
+		v_3 := params_2[0]
+
+		// This is slice implementation of items
+
+		var values_4 []*xmlrpc.Element
+		if values_4, err = xmlrpc.XPathValueGetArray(v_3, "items"); err != nil {
+			return
+		}
+		// only preview of array is decoded when context limits arrays
+		values_4 = xmlrpc.LimitArray(ctx, values_4)
+
+		items := make([][][][][][][][]string, 0, len(values_4))
+
+		// Lets iterate over given members.
+		for _, member_5 := range values_4 {
+			// stop decoding when call is cancelled
+			if err = ctx.Err(); err != nil {
+				return
+			}
+
+			// This is slice implementation of value_6
+
+			var values_7 []*xmlrpc.Element
+			if values_7, err = xmlrpc.XPathValueGetArray(member_5, "items"); err != nil {
+				return
+			}
+			// only preview of array is decoded when context limits arrays
+			values_7 = xmlrpc.LimitArray(ctx, values_7)
+
+			value_6 := make([][][][][][][]string, 0, len(values_7))
+
+			// Lets iterate over given members.
+			for _, member_8 := range values_7 {
+				// stop decoding when call is cancelled
+				if err = ctx.Err(); err != nil {
+					return
+				}
+
+				// This is slice implementation of value_9
+
+				var values_10 []*xmlrpc.Element
+				if values_10, err = xmlrpc.XPathValueGetArray(member_8, "items"); err != nil {
+					return
+				}
+				// only preview of array is decoded when context limits arrays
+				values_10 = xmlrpc.LimitArray(ctx, values_10)
+
+				value_9 := make([][][][][][]string, 0, len(values_10))
+
+				// Lets iterate over given members.
+				for _, member_11 := range values_10 {
+					// stop decoding when call is cancelled
+					if err = ctx.Err(); err != nil {
+						return
+					}
+
+					// This is slice implementation of value_12
+
+					var values_13 []*xmlrpc.Element
+					if values_13, err = xmlrpc.XPathValueGetArray(member_11, "items"); err != nil {
+						return
+					}
+					// only preview of array is decoded when context limits arrays
+					values_13 = xmlrpc.LimitArray(ctx, values_13)
+
+					value_12 := make([][][][][]string, 0, len(values_13))
+
+					// Lets iterate over given members.
+					for _, member_14 := range values_13 {
+						// stop decoding when call is cancelled
+						if err = ctx.Err(); err != nil {
+							return
+						}
+
+						// This is slice implementation of value_15
+
+						var values_16 []*xmlrpc.Element
+						if values_16, err = xmlrpc.XPathValueGetArray(member_14, "items"); err != nil {
+							return
+						}
+						// only preview of array is decoded when context limits arrays
+						values_16 = xmlrpc.LimitArray(ctx, values_16)
+
+						value_15 := make([][][][]string, 0, len(values_16))
+
+						// Lets iterate over given members.
+						for _, member_17 := range values_16 {
+							// stop decoding when call is cancelled
+							if err = ctx.Err(); err != nil {
+								return
+							}
+
+							// This is slice implementation of value_18
+
+							var values_19 []*xmlrpc.Element
+							if values_19, err = xmlrpc.XPathValueGetArray(member_17, "items"); err != nil {
+								return
+							}
+							// only preview of array is decoded when context limits arrays
+							values_19 = xmlrpc.LimitArray(ctx, values_19)
+
+							value_18 := make([][][]string, 0, len(values_19))
+
+							// Lets iterate over given members.
+							for _, member_20 := range values_19 {
+								// stop decoding when call is cancelled
+								if err = ctx.Err(); err != nil {
+									return
+								}
+
+								// This is slice implementation of value_21
+
+								var values_22 []*xmlrpc.Element
+								if values_22, err = xmlrpc.XPathValueGetArray(member_20, "items"); err != nil {
+									return
+								}
+								// only preview of array is decoded when context limits arrays
+								values_22 = xmlrpc.LimitArray(ctx, values_22)
+
+								value_21 := make([][]string, 0, len(values_22))
+
+								// Lets iterate over given members.
+								for _, member_23 := range values_22 {
+									// stop decoding when call is cancelled
+									if err = ctx.Err(); err != nil {
+										return
+									}
+
+									// This is slice implementation of value_24
+
+									var values_25 []*xmlrpc.Element
+									if values_25, err = xmlrpc.XPathValueGetArray(member_23, "items"); err != nil {
+										return
+									}
+									// only preview of array is decoded when context limits arrays
+									values_25 = xmlrpc.LimitArray(ctx, values_25)
+
+									value_24 := make([]string, 0, len(values_25))
+
+									// Lets iterate over given members.
+									for _, member_26 := range values_25 {
+										// stop decoding when call is cancelled
+										if err = ctx.Err(); err != nil {
+											return
+										}
+
+										var value_27 string
+										if value_27, err = xmlrpc.XPathValueGetString(member_26, "items"); err != nil {
+											return
+										}
+
+										value_24 = append(value_24, value_27)
+									}
+
+									value_21 = append(value_21, value_24)
+								}
+
+								value_18 = append(value_18, value_21)
+							}
+
+							value_15 = append(value_15, value_18)
+						}
+
+						value_12 = append(value_12, value_15)
+					}
+
+					value_9 = append(value_9, value_12)
+				}
+
+				value_6 = append(value_6, value_9)
+			}
+
+			items = append(items, value_6)
+		}
+
+		// If following method call fails there are 2 possible reasons:
+		// 1. you have either changed method signature or you deleted method. Please re-run "go generate"
+		// 2. you have probably found a bug and you should file issue on github.
+
+		var result_1 [][][][][][][][]string
+		result_1, err = s.Nested(ctx, items)
+
+		// error is written as fault by handler
+		if err != nil {
+			return
+		}
+
+		doc_28 := xmlrpc.NewDocument()
+
+		// here is place where we need to hydrate results
+		v_29 := doc_28.CreateElement("methodResponse").CreateElement("params").CreateElement("param").CreateElement("value")
+
+		array_data_30 := v_29.CreateElement("array").CreateElement("data")
+		for _, item_31 := range result_1 {
+			if err = ctx.Err(); err != nil {
+				return
+			}
+			value_32 := array_data_30.CreateElement("value")
+
+			array_data_33 := value_32.CreateElement("array").CreateElement("data")
+			for _, item_34 := range item_31 {
+				if err = ctx.Err(); err != nil {
+					return
+				}
+				value_35 := array_data_33.CreateElement("value")
+
+				array_data_36 := value_35.CreateElement("array").CreateElement("data")
+				for _, item_37 := range item_34 {
+					if err = ctx.Err(); err != nil {
+						return
+					}
+					value_38 := array_data_36.CreateElement("value")
+
+					array_data_39 := value_38.CreateElement("array").CreateElement("data")
+					for _, item_40 := range item_37 {
+						if err = ctx.Err(); err != nil {
+							return
+						}
+						value_41 := array_data_39.CreateElement("value")
+
+						array_data_42 := value_41.CreateElement("array").CreateElement("data")
+						for _, item_43 := range item_40 {
+							if err = ctx.Err(); err != nil {
+								return
+							}
+							value_44 := array_data_42.CreateElement("value")
+
+							array_data_45 := value_44.CreateElement("array").CreateElement("data")
+							for _, item_46 := range item_43 {
+								if err = ctx.Err(); err != nil {
+									return
+								}
+								value_47 := array_data_45.CreateElement("value")
+
+								array_data_48 := value_47.CreateElement("array").CreateElement("data")
+								for _, item_49 := range item_46 {
+									if err = ctx.Err(); err != nil {
+										return
+									}
+									value_50 := array_data_48.CreateElement("value")
+
+									array_data_51 := value_50.CreateElement("array").CreateElement("data")
+									for _, item_52 := range item_49 {
+										if err = ctx.Err(); err != nil {
+											return
+										}
+										value_53 := array_data_51.CreateElement("value")
+										value_53.CreateElement("string").SetText(item_52)
+
+									}
+
+								}
+
+							}
+
+						}
+
+					}
+
+				}
+
+			}
+
+		}
+
+		res = doc_28
+
+		return
+
+	case "Profile":
+
+		// Get parameters from xmlrpc request
+
+		// If following method call fails there are 2 possible reasons:
+		// 1. you have either changed method signature or you deleted method. Please re-run "go generate"
+		// 2. you have probably found a bug and you should file issue on github.
+
+		var result_1 struct {
+			Name   string
+			Age    int
+			Active bool
+			Score  float64
+			Tags   []string
+		}
+		result_1, err = s.Profile(ctx)
+
+		// error is written as fault by handler
+		if err != nil {
+			return
+		}
+
+		doc_3 := xmlrpc.NewDocument()
+
+		// here is place where we need to hydrate results
+		v_4 := doc_3.CreateElement("methodResponse").CreateElement("params").CreateElement("param").CreateElement("value")
+
+		struct_5 := v_4.CreateElement("struct")
+		// iterate over struct members
+
+		member_6 := struct_5.CreateElement("member")
+
+		// first create "name" xml element with member name
+		member_6.CreateElement("name").SetText("Name")
+
+		value_7 := member_6.CreateElement("value")
+
+		// make shortcut to struct member
+		struct_var_8 := result_1.Name
+
+		// set value
+		value_7.CreateElement("string").SetText(struct_var_8)
+
+		member_9 := struct_5.CreateElement("member")
+
+		// first create "name" xml element with member name
+		member_9.CreateElement("name").SetText("Age")
+
+		value_10 := member_9.CreateElement("value")
+
+		// make shortcut to struct member
+		struct_var_11 := result_1.Age
+
+		// set value
+		value_10.CreateElement("int").SetText(strconv.Itoa(int(struct_var_11)))
+
+		member_12 := struct_5.CreateElement("member")
+
+		// first create "name" xml element with member name
+		member_12.CreateElement("name").SetText("Active")
+
+		value_13 := member_12.CreateElement("value")
+
+		// make shortcut to struct member
+		struct_var_14 := result_1.Active
+
+		// set value
+		value_13.CreateElement("boolean").SetText(xmlrpc.FormatBool(struct_var_14))
+
+		member_15 := struct_5.CreateElement("member")
+
+		// first create "name" xml element with member name
+		member_15.CreateElement("name").SetText("Score")
+
+		value_16 := member_15.CreateElement("value")
+
+		// make shortcut to struct member
+		struct_var_17 := result_1.Score
+
+		// set value
+
+		if err = xmlrpc.FiniteDouble(float64(struct_var_17), "Score"); err != nil {
+			return
+		}
+
+		value_16.CreateElement("double").SetText(xmlrpc.FormatDouble(float64(struct_var_17), 64))
+
+		member_18 := struct_5.CreateElement("member")
+
+		// first create "name" xml element with member name
+		member_18.CreateElement("name").SetText("Tags")
+
+		value_19 := member_18.CreateElement("value")
+
+		// make shortcut to struct member
+		struct_var_20 := result_1.Tags
+
+		// set value
+
+		array_data_21 := value_19.CreateElement("array").CreateElement("data")
+		for _, item_22 := range struct_var_20 {
+			if err = ctx.Err(); err != nil {
+				return
+			}
+			value_23 := array_data_21.CreateElement("value")
+			value_23.CreateElement("string").SetText(item_22)
+
+		}
+
+		res = doc_3
+
+		return
+
+	case "Small":
+
+		// Get parameters from xmlrpc request
+
+		params_2 := xmlrpc.XPathParams(root)
+
+		if len(params_2) < 1 || params_2[0] == nil {
+			err = xmlrpc.Errorf(xmlrpc.FaultInvalidParams, "could not find item")
+			return
+		}
+		v_3 := params_2[0]
+
+		// rendering struct
+		item := struct {
+			Name   string
+			Age    int
+			Active bool
+			Score  float64
+			Tags   []string
+		}{}
+
+		var members_4 []*xmlrpc.Element
+		if members_4, err = xmlrpc.XPathValueGetStruct(v_3, "item"); err != nil {
+			return
+		}
+
+		// Lets iterate over given members (single pass over struct).
+		for _, member_5 := range members_4 {
+			// stop decoding when call is cancelled
+			if err = ctx.Err(); err != nil {
+				return
+			}
+
+			var (
+				name_6  string
+				value_7 *xmlrpc.Element
+			)
+			if name_6, value_7, err = xmlrpc.XPathStructMember(member_5); err != nil {
+				return
+			}
+
+			// switch over param names (over all params)
+			switch name_6 {
+
+			case "Name":
+
+				var v_9 string
+				if v_9, err = xmlrpc.XPathValueGetString(value_7, "Name"); err != nil {
+					return
+				}
+
+				// Assign to variable (for pointer support we can provide it here
+				item.Name = v_9
+
+			case "Age":
+
+				var v_10 int
+				if v_10, err = xmlrpc.XPathValueGetInt(value_7, "Age"); err != nil {
+					return
+				}
+
+				// Assign to variable (for pointer support we can provide it here
+				item.Age = v_10
+
+			case "Active":
+
+				var v_11 bool
+				if v_11, err = xmlrpc.XPathValueGetBool(value_7, "Active"); err != nil {
+					return
+				}
+
+				// Assign to variable (for pointer support we can provide it here
+				item.Active = v_11
+
+			case "Score":
+
+				var double_13 float64
+				if double_13, err = xmlrpc.XPathValueGetDouble(value_7, "Score"); err != nil {
+
+					return
+
+				}
+				v_12 := float64(double_13)
+
+				// Assign to variable (for pointer support we can provide it here
+				item.Score = v_12
+
+			case "Tags":
+
+				// This is slice implementation of v_14
+
+				var values_15 []*xmlrpc.Element
+				if values_15, err = xmlrpc.XPathValueGetArray(value_7, "Tags"); err != nil {
+					return
+				}
+				// only preview of array is decoded when context limits arrays
+				values_15 = xmlrpc.LimitArray(ctx, values_15)
+
+				v_14 := make([]string, 0, len(values_15))
+
+				// Lets iterate over given members.
+				for _, member_16 := range values_15 {
+					// stop decoding when call is cancelled
+					if err = ctx.Err(); err != nil {
+						return
+					}
+
+					var value_17 string
+					if value_17, err = xmlrpc.XPathValueGetString(member_16, "Tags"); err != nil {
+						return
+					}
+
+					v_14 = append(v_14, value_17)
+				}
+
+				// Assign to variable (for pointer support we can provide it here
+				item.Tags = v_14
+
+			}
+		}
+
+		// If following method call fails there are 2 possible reasons:
+		// 1. you have either changed method signature or you deleted method. Please re-run "go generate"
+		// 2. you have probably found a bug and you should file issue on github.
+
+		var result_1 struct {
+			Name   string
+			Age    int
+			Active bool
+			Score  float64
+			Tags   []string
+		}
+		result_1, err = s.Small(ctx, item)
+
+		// error is written as fault by handler
+		if err != nil {
+			return
+		}
+
+		doc_18 := xmlrpc.NewDocument()
+
+		// here is place where we need to hydrate results
+		v_19 := doc_18.CreateElement("methodResponse").CreateElement("params").CreateElement("param").CreateElement("value")
+
+		struct_20 := v_19.CreateElement("struct")
+		// iterate over struct members
+
+		member_21 := struct_20.CreateElement("member")
+
+		// first create "name" xml element with member name
+		member_21.CreateElement("name").SetText("Name")
+
+		value_22 := member_21.CreateElement("value")
+
+		// make shortcut to struct member
+		struct_var_23 := result_1.Name
+
+		// set value
+		value_22.CreateElement("string").SetText(struct_var_23)
+
+		member_24 := struct_20.CreateElement("member")
+
+		// first create "name" xml element with member name
+		member_24.CreateElement("name").SetText("Age")
+
+		value_25 := member_24.CreateElement("value")
+
+		// make shortcut to struct member
+		struct_var_26 := result_1.Age
+
+		// set value
+		value_25.CreateElement("int").SetText(strconv.Itoa(int(struct_var_26)))
+
+		member_27 := struct_20.CreateElement("member")
+
+		// first create "name" xml element with member name
+		member_27.CreateElement("name").SetText("Active")
+
+		value_28 := member_27.CreateElement("value")
+
+		// make shortcut to struct member
+		struct_var_29 := result_1.Active
+
+		// set value
+		value_28.CreateElement("boolean").SetText(xmlrpc.FormatBool(struct_var_29))
+
+		member_30 := struct_20.CreateElement("member")
+
+		// first create "name" xml element with member name
+		member_30.CreateElement("name").SetText("Score")
+
+		value_31 := member_30.CreateElement("value")
+
+		// make shortcut to struct member
+		struct_var_32 := result_1.Score
+
+		// set value
+
+		if err = xmlrpc.FiniteDouble(float64(struct_var_32), "Score"); err != nil {
+			return
+		}
+
+		value_31.CreateElement("double").SetText(xmlrpc.FormatDouble(float64(struct_var_32), 64))
+
+		member_33 := struct_20.CreateElement("member")
+
+		// first create "name" xml element with member name
+		member_33.CreateElement("name").SetText("Tags")
+
+		value_34 := member_33.CreateElement("value")
+
+		// make shortcut to struct member
+		struct_var_35 := result_1.Tags
+
+		// set value
+
+		array_data_36 := value_34.CreateElement("array").CreateElement("data")
+		for _, item_37 := range struct_var_35 {
+			if err = ctx.Err(); err != nil {
+				return
+			}
+			value_38 := array_data_36.CreateElement("value")
+			value_38.CreateElement("string").SetText(item_37)
+
+		}
+
+		res = doc_18
+
+		return
+
+	default:
+		// method not found, this should not happened since we check whether method exists
+		err = xmlrpc.ErrMethodNotFound
+		return
+	}
+}
+
+/*
+cloneBenchServiceArrayResult returns deep copy of result of BenchService.Array
+*/
+func cloneBenchServiceArrayResult(src []int) (dst []int) {
+	dst = src
+	if src != nil {
+		items_1 := make([]int, len(src))
+		copy(items_1, src)
+		dst = items_1
+	}
+
+	return
+}
+
+/*
+equalBenchServiceArrayResult returns whether results of BenchService.Array are equal
+*/
+func equalBenchServiceArrayResult(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i_1 := range a {
+		if a[i_1] != b[i_1] {
+			return false
+		}
+	}
+
+	return true
+}
+
+/*
+cloneBenchServiceNestedResult returns deep copy of result of BenchService.Nested
+*/
+func cloneBenchServiceNestedResult(src [][][][][][][][]string) (dst [][][][][][][][]string) {
+	dst = src
+	if src != nil {
+		items_1 := make([][][][][][][][]string, len(src))
+		copy(items_1, src)
+		for i_2 := range items_1 {
+			if src[i_2] != nil {
+				items_3 := make([][][][][][][]string, len(src[i_2]))
+				copy(items_3, src[i_2])
+				for i_4 := range items_3 {
+					if src[i_2][i_4] != nil {
+						items_5 := make([][][][][][]string, len(src[i_2][i_4]))
+						copy(items_5, src[i_2][i_4])
+						for i_6 := range items_5 {
+							if src[i_2][i_4][i_6] != nil {
+								items_7 := make([][][][][]string, len(src[i_2][i_4][i_6]))
+								copy(items_7, src[i_2][i_4][i_6])
+								for i_8 := range items_7 {
+									if src[i_2][i_4][i_6][i_8] != nil {
+										items_9 := make([][][][]string, len(src[i_2][i_4][i_6][i_8]))
+										copy(items_9, src[i_2][i_4][i_6][i_8])
+										for i_10 := range items_9 {
+											if src[i_2][i_4][i_6][i_8][i_10] != nil {
+												items_11 := make([][][]string, len(src[i_2][i_4][i_6][i_8][i_10]))
+												copy(items_11, src[i_2][i_4][i_6][i_8][i_10])
+												for i_12 := range items_11 {
+													if src[i_2][i_4][i_6][i_8][i_10][i_12] != nil {
+														items_13 := make([][]string, len(src[i_2][i_4][i_6][i_8][i_10][i_12]))
+														copy(items_13, src[i_2][i_4][i_6][i_8][i_10][i_12])
+														for i_14 := range items_13 {
+															if src[i_2][i_4][i_6][i_8][i_10][i_12][i_14] != nil {
+																items_15 := make([]string, len(src[i_2][i_4][i_6][i_8][i_10][i_12][i_14]))
+																copy(items_15, src[i_2][i_4][i_6][i_8][i_10][i_12][i_14])
+																items_13[i_14] = items_15
+															}
+														}
+														items_11[i_12] = items_13
+													}
+												}
+												items_9[i_10] = items_11
+											}
+										}
+										items_7[i_8] = items_9
+									}
+								}
+								items_5[i_6] = items_7
+							}
+						}
+						items_3[i_4] = items_5
+					}
+				}
+				items_1[i_2] = items_3
+			}
+		}
+		dst = items_1
+	}
+
+	return
+}
+
+/*
+equalBenchServiceNestedResult returns whether results of BenchService.Nested are equal
+*/
+func equalBenchServiceNestedResult(a, b [][][][][][][][]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i_1 := range a {
+		if len(a[i_1]) != len(b[i_1]) {
+			return false
+		}
+		for i_2 := range a[i_1] {
+			if len(a[i_1][i_2]) != len(b[i_1][i_2]) {
+				return false
+			}
+			for i_3 := range a[i_1][i_2] {
+				if len(a[i_1][i_2][i_3]) != len(b[i_1][i_2][i_3]) {
+					return false
+				}
+				for i_4 := range a[i_1][i_2][i_3] {
+					if len(a[i_1][i_2][i_3][i_4]) != len(b[i_1][i_2][i_3][i_4]) {
+						return false
+					}
+					for i_5 := range a[i_1][i_2][i_3][i_4] {
+						if len(a[i_1][i_2][i_3][i_4][i_5]) != len(b[i_1][i_2][i_3][i_4][i_5]) {
+							return false
+						}
+						for i_6 := range a[i_1][i_2][i_3][i_4][i_5] {
+							if len(a[i_1][i_2][i_3][i_4][i_5][i_6]) != len(b[i_1][i_2][i_3][i_4][i_5][i_6]) {
+								return false
+							}
+							for i_7 := range a[i_1][i_2][i_3][i_4][i_5][i_6] {
+								if len(a[i_1][i_2][i_3][i_4][i_5][i_6][i_7]) != len(b[i_1][i_2][i_3][i_4][i_5][i_6][i_7]) {
+									return false
+								}
+								for i_8 := range a[i_1][i_2][i_3][i_4][i_5][i_6][i_7] {
+									if a[i_1][i_2][i_3][i_4][i_5][i_6][i_7][i_8] != b[i_1][i_2][i_3][i_4][i_5][i_6][i_7][i_8] {
+										return false
+									}
+								}
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+
+	return true
+}
+
+/*
+cloneBenchServiceProfileResult returns deep copy of result of BenchService.Profile
+*/
+func cloneBenchServiceProfileResult(src struct {
+	Name   string
+	Age    int
+	Active bool
+	Score  float64
+	Tags   []string
+}) (dst struct {
+	Name   string
+	Age    int
+	Active bool
+	Score  float64
+	Tags   []string
+}) {
+	dst = src
+	if src.Tags != nil {
+		items_1 := make([]string, len(src.Tags))
+		copy(items_1, src.Tags)
+		dst.Tags = items_1
+	}
+
+	return
+}
+
+/*
+equalBenchServiceProfileResult returns whether results of BenchService.Profile are equal
+*/
+func equalBenchServiceProfileResult(a, b struct {
+	Name   string
+	Age    int
+	Active bool
+	Score  float64
+	Tags   []string
+}) bool {
+	if a.Name != b.Name {
+		return false
+	}
+	if a.Age != b.Age {
+		return false
+	}
+	if a.Active != b.Active {
+		return false
+	}
+	if a.Score != b.Score {
+		return false
+	}
+	if len(a.Tags) != len(b.Tags) {
+		return false
+	}
+	for i_1 := range a.Tags {
+		if a.Tags[i_1] != b.Tags[i_1] {
+			return false
+		}
+	}
+
+	return true
+}
+
+/*
+cloneBenchServiceSmallResult returns deep copy of result of BenchService.Small
+*/
+func cloneBenchServiceSmallResult(src struct {
+	Name   string
+	Age    int
+	Active bool
+	Score  float64
+	Tags   []string
+}) (dst struct {
+	Name   string
+	Age    int
+	Active bool
+	Score  float64
+	Tags   []string
+}) {
+	dst = src
+	if src.Tags != nil {
+		items_1 := make([]string, len(src.Tags))
+		copy(items_1, src.Tags)
+		dst.Tags = items_1
+	}
+
+	return
+}
+
+/*
+equalBenchServiceSmallResult returns whether results of BenchService.Small are equal
+*/
+func equalBenchServiceSmallResult(a, b struct {
+	Name   string
+	Age    int
+	Active bool
+	Score  float64
+	Tags   []string
+}) bool {
+	if a.Name != b.Name {
+		return false
+	}
+	if a.Age != b.Age {
+		return false
+	}
+	if a.Active != b.Active {
+		return false
+	}
+	if a.Score != b.Score {
+		return false
+	}
+	if len(a.Tags) != len(b.Tags) {
+		return false
+	}
+	for i_1 := range a.Tags {
+		if a.Tags[i_1] != b.Tags[i_1] {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,169 @@
+package xmlrpc
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"io/ioutil"
+	"net/http"
+	"path"
+	"time"
+)
+
+/*
+NewCacheTransport returns http transport for clients that keeps successful responses of methods that match
+patterns (path.Match syntax, e.g. "posts.Get*") for ttl. Same calls (same url, method and params) get kept response
+without request to server, so only read only methods should be cached. At most size responses are kept, least
+recently used are evicted. Single call can bypass cache by ContextWithoutCache. It's safe for concurrent use,
+concurrent same calls that are not kept yet are all sent. Nil base is http.DefaultTransport.
+*/
+func NewCacheTransport(base http.RoundTripper, ttl time.Duration, size int, patterns ...string) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &cacheTransport{
+		base:      base,
+		ttl:       ttl,
+		patterns:  patterns,
+		responses: newLRUCache(size),
+	}
+}
+
+/*
+ContextWithoutCache returns context of call that bypasses cache of NewCacheTransport, response is fetched from
+server (and kept for next calls)
+*/
+func ContextWithoutCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, cacheBypassContextKey, true)
+}
+
+/*
+cacheTransport keeps responses by url and hash of call
+*/
+type cacheTransport struct {
+	base      http.RoundTripper
+	ttl       time.Duration
+	patterns  []string
+	responses *lruCache
+}
+
+/*
+cachedResponse is kept response
+*/
+type cachedResponse struct {
+	expires time.Time
+	header  http.Header
+	body    []byte
+}
+
+func (c *cacheTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	if request.Method != "POST" || request.Body == nil {
+		return c.base.RoundTrip(request)
+	}
+
+	body, err := ioutil.ReadAll(request.Body)
+	request.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	// request must not be modified, it's cloned
+	clone := request.WithContext(request.Context())
+	clone.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	if !c.cached(callMethodName(body)) {
+		return c.base.RoundTrip(clone)
+	}
+
+	sum := sha256.Sum256(body)
+	key := request.URL.String() + "\x00" + hex.EncodeToString(sum[:])
+
+	if bypass, _ := request.Context().Value(cacheBypassContextKey).(bool); !bypass {
+		if kept, ok := c.responses.get(key); ok {
+			if kept := kept.(*cachedResponse); time.Now().Before(kept.expires) {
+				return kept.response(request), nil
+			}
+			c.responses.remove(key)
+		}
+	}
+
+	response, err := c.base.RoundTrip(clone)
+	if err != nil || response.StatusCode != http.StatusOK {
+		return response, err
+	}
+
+	data, err := ioutil.ReadAll(response.Body)
+	response.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	response.Body = ioutil.NopCloser(bytes.NewReader(data))
+
+	// faults are not kept
+	if !bytes.Contains(data, []byte("<fault")) {
+		c.responses.put(key, &cachedResponse{
+			expires: time.Now().Add(c.ttl),
+			header:  response.Header,
+			body:    data,
+		})
+	}
+
+	return response, nil
+}
+
+/*
+cached returns whether responses of method are kept
+*/
+func (c *cacheTransport) cached(method string) bool {
+	for _, pattern := range c.patterns {
+		if ok, _ := path.Match(pattern, method); ok && method != "" {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+response returns kept response as response of request
+*/
+func (c *cachedResponse) response(request *http.Request) *http.Response {
+	header := http.Header{}
+	for name, values := range c.header {
+		header[name] = values
+	}
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          ioutil.NopCloser(bytes.NewReader(c.body)),
+		ContentLength: int64(len(c.body)),
+		Request:       request,
+	}
+}
+
+/*
+callMethodName returns name of method of methodCall document, empty string is returned for invalid documents
+*/
+func callMethodName(body []byte) string {
+	decoder := xml.NewDecoder(bytes.NewReader(body))
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return ""
+		}
+		if start, ok := token.(xml.StartElement); ok && start.Name.Local == "methodName" {
+			var name struct {
+				Text string `xml:",chardata"`
+			}
+			if err = decoder.DecodeElement(&name, &start); err != nil {
+				return ""
+			}
+			return name.Text
+		}
+	}
+}
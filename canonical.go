@@ -0,0 +1,193 @@
+package xmlrpc
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"sort"
+	"strings"
+)
+
+const (
+	// canonicalProlog is only prolog written in canonical output
+	canonicalProlog = `<?xml version="1.0" encoding="UTF-8"?>`
+)
+
+var (
+	canonicalTextReplacer = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", "\r", "&#xD;")
+	canonicalAttrReplacer = strings.NewReplacer("&", "&amp;", "<", "&lt;", `"`, "&quot;", "\t", "&#x9;",
+		"\n", "&#xA;", "\r", "&#xD;")
+)
+
+/*
+WithCanonicalOutput writes all responses in canonical form (see Canonicalize), so they can be signed, hashed
+and diffed deterministically. Response is buffered before it's canonicalized, so streamed responses lose
+their advantage. Indent set by WithIndent is ignored.
+*/
+func WithCanonicalOutput() HandlerOption {
+	return func(h *handler) {
+		h.canonicalOutput = true
+	}
+}
+
+/*
+WithIndent writes responses indented with given indent (e.g. "  ") for debugging, empty indent means compact
+responses (default). As with canonical output responses are buffered.
+*/
+func WithIndent(indent string) HandlerOption {
+	return func(h *handler) {
+		h.indent = indent
+	}
+}
+
+/*
+Canonicalize reads xml document from r and writes its canonical form to w:
+
+  - fixed prolog, comments, processing instructions and directives are removed
+  - whitespace between elements is removed, text of leaf elements (e.g. <string>) is kept as is
+  - attributes are sorted by name, empty elements are written with end tag
+  - text and attributes are escaped consistently (as in XML canonicalization)
+
+Same values produce same bytes regardless of backend that wrote them.
+*/
+func Canonicalize(w io.Writer, r io.Reader) error {
+	return rewriteXML(w, r, "")
+}
+
+/*
+Indent reads xml document from r and writes it to w in canonical form (see Canonicalize) indented with given
+indent. Only whitespace between elements is added, so text of values is not changed.
+*/
+func Indent(w io.Writer, r io.Reader, indent string) error {
+	return rewriteXML(w, r, indent)
+}
+
+/*
+rewriteXML writes canonical form of document, when indent is not empty elements are written on separate lines
+*/
+func rewriteXML(w io.Writer, r io.Reader, indent string) (err error) {
+	decoder := xml.NewDecoder(r)
+	buf := bytes.Buffer{}
+	buf.WriteString(canonicalProlog)
+
+	var (
+		// text is pending character data, written once it's known whether it's significant
+		text bytes.Buffer
+
+		// hasChildren tracks for every open element whether it has child elements
+		hasChildren []bool
+	)
+
+	for {
+		var token xml.Token
+		if token, err = decoder.RawToken(); err == io.EOF {
+			break
+		} else if err != nil {
+			return
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			// whitespace before child element is insignificant
+			if len(hasChildren) > 0 {
+				hasChildren[len(hasChildren)-1] = true
+			}
+			writeCanonicalText(&buf, &text, true)
+			writeIndent(&buf, indent, len(hasChildren))
+			hasChildren = append(hasChildren, false)
+
+			attrs := append([]xml.Attr{}, t.Attr...)
+			sort.Slice(attrs, func(i, j int) bool { return canonicalName(attrs[i].Name) < canonicalName(attrs[j].Name) })
+
+			buf.WriteString("<" + canonicalName(t.Name))
+			for _, attr := range attrs {
+				buf.WriteString(" " + canonicalName(attr.Name) + `="` + canonicalAttrReplacer.Replace(attr.Value) + `"`)
+			}
+			buf.WriteString(">")
+		case xml.EndElement:
+			// text of leaf element is kept, whitespace after last child is not
+			children := len(hasChildren) > 0 && hasChildren[len(hasChildren)-1]
+			writeCanonicalText(&buf, &text, children)
+			if len(hasChildren) > 0 {
+				hasChildren = hasChildren[:len(hasChildren)-1]
+			}
+			if children {
+				writeIndent(&buf, indent, len(hasChildren))
+			}
+			buf.WriteString("</" + canonicalName(t.Name) + ">")
+		case xml.CharData:
+			if len(hasChildren) > 0 {
+				text.Write(t)
+			}
+		}
+	}
+
+	_, err = buf.WriteTo(w)
+	return
+}
+
+/*
+writeCanonicalText writes pending text, whitespace only text is dropped when it's insignificant
+*/
+func writeCanonicalText(buf *bytes.Buffer, text *bytes.Buffer, insignificant bool) {
+	if insignificant && len(bytes.TrimSpace(text.Bytes())) == 0 {
+		text.Reset()
+		return
+	}
+	buf.WriteString(canonicalTextReplacer.Replace(text.String()))
+	text.Reset()
+}
+
+/*
+writeIndent writes new line and indent for given depth, nothing is written for empty indent
+*/
+func writeIndent(buf *bytes.Buffer, indent string, depth int) {
+	if indent == "" {
+		return
+	}
+	buf.WriteString("\n")
+	buf.WriteString(strings.Repeat(indent, depth))
+}
+
+/*
+canonicalName returns name with prefix as written in document
+*/
+func canonicalName(name xml.Name) string {
+	if name.Space == "" {
+		return name.Local
+	}
+	return name.Space + ":" + name.Local
+}
+
+/*
+canonicalWriterTo writes response in canonical form, indented when indent is set
+*/
+type canonicalWriterTo struct {
+	response io.WriterTo
+	indent   string
+}
+
+func (c canonicalWriterTo) WriteTo(w io.Writer) (int64, error) {
+	buf := bytes.Buffer{}
+	if _, err := c.response.WriteTo(&buf); err != nil {
+		return 0, err
+	}
+
+	counter := &countingWriter{w: w}
+	err := rewriteXML(counter, &buf, c.indent)
+	return counter.n, err
+}
+
+/*
+countingWriter counts written bytes
+*/
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (n int, err error) {
+	n, err = c.w.Write(p)
+	c.n += int64(n)
+	return
+}
@@ -0,0 +1,130 @@
+package xmlrpc
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/cgi"
+	"net/http/fcgi"
+	"strconv"
+	"strings"
+)
+
+/*
+ServeCGI serves single request of CGI program with handler, it's called from main of program executed by web
+server
+*/
+func ServeCGI(h http.Handler) error {
+	return cgi.Serve(h)
+}
+
+/*
+ServeFastCGI serves FastCGI requests accepted on listener, nil listener serves connections on stdin as FastCGI
+application spawned by web server
+*/
+func ServeFastCGI(l net.Listener, h http.Handler) error {
+	return fcgi.Serve(l, h)
+}
+
+/*
+ServeSCGI serves SCGI requests accepted on listener, every connection is served in its own goroutine
+*/
+func ServeSCGI(l net.Listener, h http.Handler) error {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return err
+		}
+		go serveSCGIConn(conn, h)
+	}
+}
+
+/*
+serveSCGIConn reads SCGI request (netstring with headers followed by body) and writes CGI response
+*/
+func serveSCGIConn(conn net.Conn, h http.Handler) {
+	defer conn.Close()
+
+	reader := bufio.NewReader(conn)
+	params, err := readSCGIHeaders(reader)
+	if err != nil {
+		fmt.Fprintf(conn, "Status: 400 Bad Request\r\nContent-Type: text/plain\r\n\r\n%v\n", err)
+		return
+	}
+
+	request, err := cgi.RequestFromMap(params)
+	if err != nil {
+		fmt.Fprintf(conn, "Status: 400 Bad Request\r\nContent-Type: text/plain\r\n\r\n%v\n", err)
+		return
+	}
+	request.Body = ioutil.NopCloser(io.LimitReader(reader, request.ContentLength))
+
+	response := &scgiResponse{writer: bufio.NewWriter(conn), header: http.Header{}}
+	h.ServeHTTP(response, request)
+	response.WriteHeader(http.StatusOK)
+	response.writer.Flush()
+}
+
+/*
+readSCGIHeaders reads netstring of null separated header names and values
+*/
+func readSCGIHeaders(reader *bufio.Reader) (map[string]string, error) {
+	prefix, err := reader.ReadString(':')
+	if err != nil {
+		return nil, err
+	}
+	length, err := strconv.Atoi(strings.TrimSuffix(prefix, ":"))
+	if err != nil || length <= 0 || length > 1<<20 {
+		return nil, fmt.Errorf("invalid scgi headers length %q", prefix)
+	}
+
+	data := make([]byte, length+1)
+	if _, err = io.ReadFull(reader, data); err != nil {
+		return nil, err
+	}
+	if data[length] != ',' {
+		return nil, fmt.Errorf("invalid scgi headers terminator")
+	}
+
+	fields := strings.Split(strings.TrimSuffix(string(data[:length]), "\x00"), "\x00")
+	if len(fields)%2 != 0 {
+		return nil, fmt.Errorf("invalid scgi headers")
+	}
+	params := make(map[string]string, len(fields)/2)
+	for i := 0; i < len(fields); i += 2 {
+		params[fields[i]] = fields[i+1]
+	}
+	return params, nil
+}
+
+/*
+scgiResponse writes response in CGI format (Status header, headers, empty line and body)
+*/
+type scgiResponse struct {
+	writer      *bufio.Writer
+	header      http.Header
+	wroteHeader bool
+}
+
+func (r *scgiResponse) Header() http.Header {
+	return r.header
+}
+
+func (r *scgiResponse) WriteHeader(status int) {
+	if r.wroteHeader {
+		return
+	}
+	r.wroteHeader = true
+
+	fmt.Fprintf(r.writer, "Status: %d %s\r\n", status, http.StatusText(status))
+	r.header.Write(r.writer)
+	r.writer.WriteString("\r\n")
+}
+
+func (r *scgiResponse) Write(data []byte) (int, error) {
+	r.WriteHeader(http.StatusOK)
+	return r.writer.Write(data)
+}
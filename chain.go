@@ -0,0 +1,268 @@
+package xmlrpc
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/beevik/etree"
+)
+
+var (
+	errorKindsMutex sync.RWMutex
+	errorKinds      = map[string]error{}
+)
+
+/*
+RegisterErrorKind registers sentinel error under kind name. When error chains are enabled (WithErrorChains),
+errors in chain that are registered are sent with their kind, and ParseFault reconstructs them, so errors.Is
+works across the wire. Both sides must register same kinds. Errors are matched by ==, so error must be comparable
+(e.g. pointer or value created by errors.New).
+*/
+func RegisterErrorKind(kind string, err error) {
+	if err == nil || !reflect.TypeOf(err).Comparable() {
+		panic("xmlrpc: error kind " + kind + " is not comparable error")
+	}
+
+	errorKindsMutex.Lock()
+	defer errorKindsMutex.Unlock()
+	errorKinds[kind] = err
+}
+
+/*
+errorKind returns kind of registered error or empty string
+*/
+func errorKind(err error) string {
+	// errors of non comparable types cannot be registered (and cannot be compared)
+	if !reflect.TypeOf(err).Comparable() {
+		return ""
+	}
+
+	errorKindsMutex.RLock()
+	defer errorKindsMutex.RUnlock()
+	for kind, registered := range errorKinds {
+		if registered == err {
+			return kind
+		}
+	}
+	return ""
+}
+
+/*
+WithErrorChains adds chain of wrapped errors to fault detail as "chain" member (array of structs with kind and
+message). Errors are unwrapped by Unwrap() or Cause() methods.
+*/
+func WithErrorChains() HandlerOption {
+	return func(h *handler) {
+		h.errorChains = true
+	}
+}
+
+/*
+unwrapError returns wrapped error or nil
+*/
+func unwrapError(err error) error {
+	switch e := err.(type) {
+	case interface {
+		Unwrap() error
+	}:
+		return e.Unwrap()
+	case interface {
+		Cause() error
+	}:
+		return e.Cause()
+	}
+	return nil
+}
+
+/*
+xmlWriteErrorChain writes chain of errors to "detail" member of fault struct
+*/
+func xmlWriteErrorChain(faultStruct *etree.Element, err error) {
+	var detail *etree.Element
+
+	// find existing detail struct
+	for _, member := range faultStruct.ChildElements() {
+		if name, value, e := XPathStructMember(member); e == nil && name == "detail" {
+			detail = childElement(value, "struct")
+		}
+	}
+
+	if detail == nil {
+		member := faultStruct.CreateElement("member")
+		member.CreateElement("name").SetText("detail")
+		detail = member.CreateElement("value").CreateElement("struct")
+	}
+
+	member := detail.CreateElement("member")
+	member.CreateElement("name").SetText("chain")
+	data := member.CreateElement("value").CreateElement("array").CreateElement("data")
+
+	for ; err != nil; err = unwrapError(err) {
+		item := data.CreateElement("value").CreateElement("struct")
+
+		kind := item.CreateElement("member")
+		kind.CreateElement("name").SetText("kind")
+		kind.CreateElement("value").CreateElement("string").SetText(errorKind(err))
+
+		message := item.CreateElement("member")
+		message.CreateElement("name").SetText("message")
+		message.CreateElement("value").CreateElement("string").SetText(err.Error())
+	}
+}
+
+/*
+Fault is fault parsed from methodResponse. It satisfies Error interface and when response carries error chain
+it can be unwrapped (errors.Is works for registered error kinds).
+*/
+type Fault struct {
+	FaultCode   int
+	FaultString string
+
+	// Detail is "detail" member of fault (see FaultDetailer), nil when fault has none
+	Detail *Value
+
+	// first error of reconstructed chain
+	chain error
+}
+
+/*
+Error satisfies error interface
+*/
+func (f *Fault) Error() string {
+	return f.FaultString
+}
+
+/*
+Code satisfies Error interface
+*/
+func (f *Fault) Code() int {
+	return f.FaultCode
+}
+
+/*
+Unwrap returns first error of chain (nil if fault has no chain)
+*/
+func (f *Fault) Unwrap() error {
+	return f.chain
+}
+
+/*
+chainError is error reconstructed from error chain
+*/
+type chainError struct {
+	kind    string
+	message string
+	next    error
+}
+
+func (c *chainError) Error() string { return c.message }
+func (c *chainError) Unwrap() error { return c.next }
+
+/*
+Is reports whether error is registered sentinel error (used by errors.Is)
+*/
+func (c *chainError) Is(target error) bool {
+	if c.kind == "" {
+		return false
+	}
+
+	errorKindsMutex.RLock()
+	defer errorKindsMutex.RUnlock()
+	return errorKinds[c.kind] == target
+}
+
+/*
+ParseFault parses <fault> element of methodResponse, malformed fault is error with FaultTransportError code
+*/
+func ParseFault(element *etree.Element) (*Fault, error) {
+	var (
+		value   *etree.Element
+		members []*etree.Element
+		err     error
+	)
+
+	if value = childElement(element, "value"); value == nil {
+		return nil, Errorf(FaultTransportError, "fault without value")
+	}
+
+	if members, err = XPathValueGetStruct(value, "fault"); err != nil {
+		return nil, Errorf(FaultTransportError, "invalid fault: %v", err)
+	}
+
+	result := &Fault{}
+
+	for _, member := range members {
+		name, memberValue, e := XPathStructMember(member)
+		if e != nil {
+			return nil, Errorf(FaultTransportError, "invalid fault: %v", e)
+		}
+
+		switch name {
+		case "faultCode":
+			// some servers send faultCode without type
+			if result.FaultCode, err = XPathValueGetInt(memberValue, name); err != nil {
+				if result.FaultCode, err = strconv.Atoi(strings.TrimSpace(memberValue.Text())); err != nil {
+					return nil, Errorf(FaultTransportError, "invalid faultCode")
+				}
+			}
+		case "faultString":
+			if result.FaultString, err = XPathValueGetString(memberValue, name); err != nil {
+				return nil, Errorf(FaultTransportError, "invalid fault: %v", err)
+			}
+		case "detail":
+			// detail is optional extension, invalid one is ignored
+			result.Detail, _ = XPathValueGetValue(memberValue, name)
+			result.chain = parseErrorChain(memberValue)
+		}
+	}
+
+	return result, nil
+}
+
+/*
+parseErrorChain reconstructs error chain from fault detail
+*/
+func parseErrorChain(detail *etree.Element) error {
+	members, err := XPathValueGetStruct(detail, "detail")
+	if err != nil {
+		return nil
+	}
+
+	for _, member := range members {
+		name, value, e := XPathStructMember(member)
+		if e != nil || name != "chain" {
+			continue
+		}
+
+		items, e := XPathValueGetArray(value, "chain")
+		if e != nil {
+			return nil
+		}
+
+		var result error
+		for i := len(items) - 1; i >= 0; i-- {
+			item := &chainError{next: result}
+
+			itemMembers, _ := XPathValueGetStruct(items[i], "chain")
+			for _, itemMember := range itemMembers {
+				itemName, itemValue, e := XPathStructMember(itemMember)
+				if e != nil {
+					continue
+				}
+				switch itemName {
+				case "kind":
+					item.kind, _ = XPathValueGetString(itemValue, itemName)
+				case "message":
+					item.message, _ = XPathValueGetString(itemValue, itemName)
+				}
+			}
+
+			result = item
+		}
+		return result
+	}
+
+	return nil
+}
@@ -0,0 +1,76 @@
+package xmlrpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/beevik/etree"
+)
+
+/*
+sliceError is error of non comparable type
+*/
+type sliceError []string
+
+func (s sliceError) Error() string { return fmt.Sprint([]string(s)) }
+
+var errChainNotFound = errors.New("not found")
+
+func TestErrorChains(t *testing.T) {
+	RegisterErrorKind("chain_test.not_found", errChainNotFound)
+
+	registry := NewRegistry()
+	registry.Register("Get", func(ctx context.Context, params ...*Value) (*Value, error) {
+		return nil, fmt.Errorf("get post: %w", errChainNotFound)
+	})
+	registry.Register("Invalid", func(ctx context.Context, params ...*Value) (*Value, error) {
+		return nil, fmt.Errorf("validate: %w", sliceError{"title", "body"})
+	})
+	h := NewHandler(WithErrorChains())
+	h.AddService(registry, "posts")
+
+	calls, callsWriter := io.Pipe()
+	responses, responsesWriter := io.Pipe()
+	defer callsWriter.Close()
+	go ServePipe(context.Background(), h, calls, responsesWriter)
+	client := NewPipeClient(responses, callsWriter)
+
+	if _, err := client.Call(context.Background(), "posts.Get"); !errors.Is(err, errChainNotFound) {
+		t.Errorf("expected registered error in chain, got %v", err)
+	}
+
+	// non comparable errors in chain are sent without kind
+	_, err := client.Call(context.Background(), "posts.Invalid")
+	if _, ok := err.(*Fault); !ok || errors.Is(err, errChainNotFound) {
+		t.Errorf("expected fault without registered error, got %v", err)
+	}
+}
+
+func TestRegisterNonComparableErrorKind(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected panic")
+		}
+	}()
+	RegisterErrorKind("chain_test.slice", sliceError{"a"})
+}
+
+func TestParseMalformedFault(t *testing.T) {
+	for _, fault := range []string{
+		"<fault></fault>",
+		"<fault><value><string>oops</string></value></fault>",
+		"<fault><value><struct><member><name>faultCode</name><value><string>x</string></value></member></struct></value></fault>",
+		"<fault><value><struct><member><name>faultString</name><value><int>1</int></value></member></struct></value></fault>",
+	} {
+		doc := etree.NewDocument()
+		if err := doc.ReadFromString(fault); err != nil {
+			t.Fatalf("invalid fixture %v: %v", fault, err)
+		}
+		if _, err := ParseFault(doc.Root()); errorCode(err) != FaultTransportError {
+			t.Errorf("%v: expected transport error, got %v", fault, err)
+		}
+	}
+}
@@ -0,0 +1,270 @@
+package xmlrpc
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+/*
+newTestHandler returns handler with math.Add method
+*/
+func newTestHandler(options ...HandlerOption) Handler {
+	registry := NewRegistry()
+	registry.Register("Add", func(ctx context.Context, params ...*Value) (*Value, error) {
+		return NewInt(params[0].Int() + params[1].Int()), nil
+	})
+	h := NewHandler(options...)
+	h.AddService(registry, "math")
+	return h
+}
+
+/*
+callConcurrently calls math.Add from many goroutines and checks results
+*/
+func callConcurrently(t *testing.T, caller Caller) {
+	wg := sync.WaitGroup{}
+	for g := 0; g < 16; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 20; i++ {
+				result, err := caller.Call(context.Background(), "math.Add", NewInt(g), NewInt(i))
+				if err != nil {
+					t.Errorf("call failed: %v", err)
+					return
+				}
+				if result.Int() != g+i {
+					t.Errorf("expected %v, got %v", g+i, result.Int())
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+}
+
+func TestPipeClientConcurrent(t *testing.T) {
+	calls, callsWriter := io.Pipe()
+	responses, responsesWriter := io.Pipe()
+	defer callsWriter.Close()
+
+	go ServePipe(context.Background(), newTestHandler(), calls, responsesWriter)
+
+	callConcurrently(t, NewPipeClient(responses, callsWriter))
+}
+
+func TestConnClientConcurrent(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("cannot listen: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go ServeConns(ctx, listener, newTestHandler(), time.Second)
+
+	client := NewConnClient(func(ctx context.Context) (net.Conn, error) {
+		return (&net.Dialer{}).DialContext(ctx, "tcp", listener.Addr().String())
+	})
+	defer client.Close()
+
+	callConcurrently(t, client)
+}
+
+func TestTransportsConcurrent(t *testing.T) {
+	server := httptest.NewServer(newTestHandler(WithETags("math.*")))
+	defer server.Close()
+
+	transport := NewETagTransport(NewCacheTransport(NewDeadlineTransport(nil), time.Minute, 4, "math.*"))
+	client := &http.Client{Transport: transport}
+
+	wg := sync.WaitGroup{}
+	for g := 0; g < 16; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 20; i++ {
+				response, err := client.Post(server.URL, "text/xml", bytes.NewReader(encodeCall("math.Add", []*Value{NewInt(g % 3), NewInt(i % 5)})))
+				if err != nil {
+					t.Errorf("call failed: %v", err)
+					return
+				}
+				body, _ := ioutil.ReadAll(response.Body)
+				response.Body.Close()
+
+				result, err := parseResponse(context.Background(), body)
+				if err != nil {
+					t.Errorf("invalid response: %v", err)
+					return
+				}
+				if result.Int() != g%3+i%5 {
+					t.Errorf("expected %v, got %v", g%3+i%5, result.Int())
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+}
+
+func TestUpload(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register("Store", func(ctx context.Context, params ...*Value) (*Value, error) {
+		return NewInt(len(params[1].Bytes())), nil
+	})
+	h := NewHandler()
+	h.AddService(registry, "files")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("token") == "" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		h.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: &http.Transport{ExpectContinueTimeout: time.Second}}
+	data := bytes.Repeat([]byte("xmlrpc"), 100000)
+
+	// rejected call never sends data
+	var sent int64
+	progress := WithUploadProgress(func(n int64) { sent = n })
+	if _, err := Upload(context.Background(), client, server.URL, "files.Store", []*Value{NewString("a")}, bytes.NewReader(data), progress); err == nil {
+		t.Fatalf("expected error")
+	}
+	if sent != 0 {
+		t.Errorf("expected no data sent, sent %v", sent)
+	}
+
+	for _, options := range [][]UploadOption{{progress}, {progress, WithUploadSize(int64(len(data)))}} {
+		sent = 0
+		result, err := Upload(context.Background(), client, server.URL+"?token=x", "files.Store", []*Value{NewString("a")}, bytes.NewReader(data), options...)
+		if err != nil {
+			t.Fatalf("upload failed: %v", err)
+		}
+		if result.Int() != len(data) || sent != int64(len(data)) {
+			t.Errorf("expected %v bytes, got %v (sent %v)", len(data), result.Int(), sent)
+		}
+	}
+}
+
+type detailError struct{}
+
+func (detailError) Error() string { return "not found" }
+func (detailError) Code() int     { return 404 }
+func (detailError) FaultDetail() map[string]interface{} {
+	return map[string]interface{}{"id": 42, "kind": "post"}
+}
+
+func TestFaultDetail(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register("Get", func(ctx context.Context, params ...*Value) (*Value, error) {
+		return nil, detailError{}
+	})
+	h := NewHandler()
+	h.AddService(registry, "posts")
+
+	calls, callsWriter := io.Pipe()
+	responses, responsesWriter := io.Pipe()
+	defer callsWriter.Close()
+	go ServePipe(context.Background(), h, calls, responsesWriter)
+
+	_, err := NewPipeClient(responses, callsWriter).Call(context.Background(), "posts.Get")
+	fault, ok := err.(*Fault)
+	if !ok {
+		t.Fatalf("expected fault, got %v", err)
+	}
+	if fault.FaultCode != 404 || fault.Detail.Member("id").Int() != 42 || fault.Detail.Member("kind").Text() != "post" {
+		t.Errorf("unexpected fault %v with detail %v", fault.FaultCode, fault.Detail)
+	}
+}
+
+func TestIdempotencyTransport(t *testing.T) {
+	calls := 0
+	registry := NewRegistry()
+	registry.Register("Create", func(ctx context.Context, params ...*Value) (*Value, error) {
+		calls++
+		return NewInt(calls), nil
+	})
+	h := NewHandler()
+	h.AddService(registry, "posts")
+
+	server := httptest.NewServer(Idempotency(time.Minute)(h))
+	defer server.Close()
+
+	client := &http.Client{Transport: NewIdempotencyTransport(nil)}
+	call := func(ctx context.Context, param int) (*Value, error) {
+		request, _ := http.NewRequest("POST", server.URL, bytes.NewReader(encodeCall("posts.Create", []*Value{NewInt(param)})))
+		response, err := client.Do(request.WithContext(ctx))
+		if err != nil {
+			return nil, err
+		}
+		defer response.Body.Close()
+		body, _ := ioutil.ReadAll(response.Body)
+		return parseResponse(ctx, body)
+	}
+
+	ctx := ContextWithIdempotencyKey(context.Background(), NewIdempotencyKey())
+	for retry := 0; retry < 3; retry++ {
+		if result, err := call(ctx, 1); err != nil || result.Int() != 1 {
+			t.Fatalf("retry %v: expected replayed result 1, got %v (%v)", retry, result.Int(), err)
+		}
+	}
+
+	if _, err := call(ctx, 2); errorCode(err) != FaultInvalidRequest {
+		t.Errorf("expected reused key fault, got %v", err)
+	}
+
+	if result, err := call(context.Background(), 1); err != nil || result.Int() != 2 {
+		t.Errorf("expected call without key to be executed, got %v (%v)", result.Int(), err)
+	}
+}
+
+/*
+roundTripperFunc is http transport implemented by function
+*/
+type roundTripperFunc func(request *http.Request) (*http.Response, error)
+
+func (r roundTripperFunc) RoundTrip(request *http.Request) (*http.Response, error) {
+	return r(request)
+}
+
+func TestETagTransportEviction(t *testing.T) {
+	server := httptest.NewServer(newTestHandler(WithETags("math.*")))
+	defer server.Close()
+
+	var statuses []int
+	counting := roundTripperFunc(func(request *http.Request) (*http.Response, error) {
+		response, err := http.DefaultTransport.RoundTrip(request)
+		if err == nil {
+			statuses = append(statuses, response.StatusCode)
+		}
+		return response, err
+	})
+	client := &http.Client{Transport: NewETagTransport(counting, WithETagCacheSize(1))}
+
+	for _, a := range []int{1, 1, 2, 1} {
+		response, err := client.Post(server.URL, "text/xml", bytes.NewReader(encodeCall("math.Add", []*Value{NewInt(a), NewInt(1)})))
+		if err != nil {
+			t.Fatalf("call failed: %v", err)
+		}
+		body, _ := ioutil.ReadAll(response.Body)
+		response.Body.Close()
+		if result, err := parseResponse(context.Background(), body); err != nil || result.Int() != a+1 {
+			t.Fatalf("expected %v, got %v (%v)", a+1, result.Int(), err)
+		}
+	}
+
+	// second call is revalidated, last one was evicted by call with other params
+	expected := []int{http.StatusOK, http.StatusNotModified, http.StatusOK, http.StatusOK}
+	if fmt.Sprint(statuses) != fmt.Sprint(expected) {
+		t.Errorf("expected statuses %v, got %v", expected, statuses)
+	}
+}
@@ -0,0 +1,178 @@
+package xmlrpc
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/beevik/etree"
+)
+
+/*
+Codec encodes values of custom go type to xmlrpc value and decodes them back. Codecs are registered by go type
+with RegisterCodec and used by generated code (types tagged ",codec" or listed by gen.WithCodecTypes), by
+FromInterface and by XMLWriteInterface (fault details).
+*/
+type Codec struct {
+	// Encode returns xmlrpc value of given go value
+	Encode func(value interface{}) (*Value, error)
+
+	// Decode returns go value decoded from xmlrpc value, it must be of registered type
+	Decode func(value *Value) (interface{}, error)
+}
+
+/*
+ValueMarshaler is implemented by types that encode themselves to xmlrpc value, generator registers codec of
+such types automatically (see MarshalerCodec)
+*/
+type ValueMarshaler interface {
+	MarshalXMLRPC() (*Value, error)
+}
+
+/*
+ValueUnmarshaler is implemented by pointers to types that decode themselves from xmlrpc value
+*/
+type ValueUnmarshaler interface {
+	UnmarshalXMLRPC(value *Value) error
+}
+
+var (
+	codecsMutex sync.RWMutex
+	codecs      = map[reflect.Type]Codec{}
+)
+
+/*
+RegisterCodec registers codec of given go type, codec registered later for the same type replaces the former.
+It's usually called from init function.
+*/
+func RegisterCodec(typ reflect.Type, codec Codec) {
+	if codec.Encode == nil || codec.Decode == nil {
+		panic(fmt.Sprintf("xmlrpc: codec of %v must have both Encode and Decode", typ))
+	}
+
+	codecsMutex.Lock()
+	defer codecsMutex.Unlock()
+	codecs[typ] = codec
+}
+
+/*
+LookupCodec returns codec registered for given go type
+*/
+func LookupCodec(typ reflect.Type) (codec Codec, ok bool) {
+	codecsMutex.RLock()
+	defer codecsMutex.RUnlock()
+	codec, ok = codecs[typ]
+	return
+}
+
+/*
+MarshalerCodec returns codec of type that implements ValueMarshaler (and its pointer ValueUnmarshaler), type can
+be also pointer to such type
+*/
+func MarshalerCodec(typ reflect.Type) Codec {
+	return Codec{
+		Encode: func(value interface{}) (*Value, error) {
+			marshaler, ok := value.(ValueMarshaler)
+			if !ok {
+				return nil, fmt.Errorf("%T does not implement xmlrpc.ValueMarshaler", value)
+			}
+			return marshaler.MarshalXMLRPC()
+		},
+		Decode: func(value *Value) (interface{}, error) {
+			// pointer types are decoded to newly allocated value
+			pointer := typ.Kind() == reflect.Ptr
+			target := reflect.New(typ)
+			if pointer {
+				target = reflect.New(typ.Elem())
+			}
+
+			unmarshaler, ok := target.Interface().(ValueUnmarshaler)
+			if !ok {
+				return nil, fmt.Errorf("%v does not implement xmlrpc.ValueUnmarshaler", target.Type())
+			}
+			if err := unmarshaler.UnmarshalXMLRPC(value); err != nil {
+				return nil, err
+			}
+
+			if pointer {
+				return target.Interface(), nil
+			}
+			return target.Elem().Interface(), nil
+		},
+	}
+}
+
+/*
+RegisterMarshaler registers MarshalerCodec of given type, generated code calls it for types that implement
+ValueMarshaler and ValueUnmarshaler
+*/
+func RegisterMarshaler(typ reflect.Type) {
+	RegisterCodec(typ, MarshalerCodec(typ))
+}
+
+/*
+EncodeCodec encodes value by codec registered for its type, generated code writes result as raw value
+*/
+func EncodeCodec(value interface{}) (result RawValue, err error) {
+	codec, ok := LookupCodec(reflect.TypeOf(value))
+	if !ok {
+		return nil, Errorf(FaultInternalError, "no codec registered for %T", value)
+	}
+
+	var encoded *Value
+	if encoded, err = codec.Encode(value); err != nil {
+		return nil, Errorf(FaultInternalError, "cannot encode %T: %v", value, err)
+	}
+	return encoded.Raw(), nil
+}
+
+/*
+XPathValueGetCodec decodes value by codec registered for type of target (pointer to decoded value)
+*/
+func XPathValueGetCodec(element *etree.Element, name string, target interface{}) (err error) {
+	pointer := reflect.ValueOf(target)
+	if pointer.Kind() != reflect.Ptr || pointer.IsNil() {
+		return Errorf(FaultInternalError, "invalid target of %v: %T", name, target)
+	}
+
+	typ := pointer.Elem().Type()
+	codec, ok := LookupCodec(typ)
+	if !ok {
+		return Errorf(FaultInternalError, "no codec registered for %v", typ)
+	}
+
+	var value *Value
+	if value, err = XPathValueGetValue(element, name); err != nil {
+		return
+	}
+
+	var decoded interface{}
+	if decoded, err = codec.Decode(value); err != nil {
+		return Errorf(FaultInvalidParams, "invalid %v: %v", name, err)
+	}
+
+	result := reflect.ValueOf(decoded)
+	if !result.IsValid() || result.Type() != typ {
+		return Errorf(FaultInternalError, "codec of %v decoded %T", typ, decoded)
+	}
+	pointer.Elem().Set(result)
+
+	return nil
+}
+
+/*
+codecValue returns value encoded by registered codec, ok is false when no codec is registered for type of value
+*/
+func codecValue(value interface{}) (result *Value, ok bool, err error) {
+	if value == nil {
+		return nil, false, nil
+	}
+
+	var codec Codec
+	if codec, ok = LookupCodec(reflect.TypeOf(value)); !ok {
+		return nil, false, nil
+	}
+
+	result, err = codec.Encode(value)
+	return result, true, err
+}
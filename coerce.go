@@ -0,0 +1,163 @@
+package xmlrpc
+
+import (
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/beevik/etree"
+)
+
+/*
+Coercion table of scalar values. Generated code uses XPathValueCoerce* helpers instead of strict XPathValueGet*
+when coercion is enabled (xmlrpcgen --coerce or ",coerce" field tag). Key is xmlrpc type of received value,
+value converts its text.
+*/
+var (
+	intCoercions = map[string]func(name, text string) (int, error){
+		"int":    coerceIntFromInt,
+		"i4":     coerceIntFromInt,
+		"i8":     coerceIntFromInt,
+		"string": coerceIntFromInt,
+		"double": coerceIntFromDouble,
+		"boolean": func(name, text string) (int, error) {
+			if text == "1" {
+				return 1, nil
+			}
+			return 0, nil
+		},
+	}
+
+	boolCoercions = map[string]func(name, text string) (bool, error){
+		"boolean": func(name, text string) (bool, error) {
+			return text == "1", nil
+		},
+		"int": coerceBoolFromInt,
+		"i4":  coerceBoolFromInt,
+		"i8":  coerceBoolFromInt,
+		"string": func(name, text string) (bool, error) {
+			return strconv.ParseBool(text)
+		},
+	}
+
+	stringCoercions = map[string]func(name, text string) (string, error){
+		"string":  coerceStringFromText,
+		"int":     coerceStringFromText,
+		"i4":      coerceStringFromText,
+		"i8":      coerceStringFromText,
+		"double":  coerceStringFromText,
+		"boolean": coerceStringFromText,
+	}
+)
+
+/*
+XPathValueCoerceInt Returns int from value, strings, booleans and doubles (truncated with warning) are converted
+*/
+func XPathValueCoerceInt(element *etree.Element, name string) (result int, err error) {
+	tag, text, ok := scalarValue(element)
+	if !ok {
+		err = Errorf(FaultInvalidParams, "not found %v", name)
+		return
+	}
+
+	coercion, ok := intCoercions[tag]
+	if !ok {
+		err = Errorf(FaultInvalidParams, "cannot convert %v %v to int", tag, name)
+		return
+	}
+
+	if result, err = coercion(name, strings.TrimSpace(text)); err != nil {
+		err = intError(name, strings.TrimSpace(text), "int", 0, err)
+	}
+
+	return
+}
+
+/*
+XPathValueCoerceBool Returns bool from value, ints (non zero is true) and strings ("true", "0"...) are converted
+*/
+func XPathValueCoerceBool(element *etree.Element, name string) (result bool, err error) {
+	tag, text, ok := scalarValue(element)
+	if !ok {
+		err = Errorf(FaultInvalidParams, "not found %v", name)
+		return
+	}
+
+	coercion, ok := boolCoercions[tag]
+	if !ok {
+		err = Errorf(FaultInvalidParams, "cannot convert %v %v to bool", tag, name)
+		return
+	}
+
+	if result, err = coercion(name, strings.TrimSpace(text)); err != nil {
+		err = Errorf(FaultInvalidParams, "invalid bool %v", name)
+	}
+
+	return
+}
+
+/*
+XPathValueCoerceString Returns string from value, text of other scalar values is used as is
+*/
+func XPathValueCoerceString(element *etree.Element, name string) (result string, err error) {
+	tag, text, ok := scalarValue(element)
+	if !ok {
+		err = Errorf(FaultInvalidParams, "not found %v", name)
+		return
+	}
+
+	coercion, ok := stringCoercions[tag]
+	if !ok {
+		err = Errorf(FaultInvalidParams, "cannot convert %v %v to string", tag, name)
+		return
+	}
+
+	if tag != "string" {
+		text = strings.TrimSpace(text)
+	}
+
+	return coercion(name, text)
+}
+
+/*
+scalarValue returns xmlrpc type and text of value, value without type element is string
+*/
+func scalarValue(element *etree.Element) (tag, text string, ok bool) {
+	children := element.ChildElements()
+
+	switch len(children) {
+	case 0:
+		return "string", element.Text(), true
+	case 1:
+		return children[0].Tag, children[0].Text(), true
+	}
+
+	return "", "", false
+}
+
+func coerceIntFromInt(name, text string) (int, error) {
+	return strconv.Atoi(text)
+}
+
+func coerceIntFromDouble(name, text string) (int, error) {
+	f, err := ParseDoubleLenient(text)
+	if err != nil {
+		return 0, err
+	}
+
+	result := int(f)
+	if float64(result) != f {
+		log.Printf("xmlrpc: warning: double %v of %v truncated to %v", text, name, result)
+	}
+
+	return result, nil
+}
+
+func coerceBoolFromInt(name, text string) (bool, error) {
+	i, err := strconv.Atoi(text)
+	return i != 0, err
+}
+
+func coerceStringFromText(name, text string) (string, error) {
+	return text, nil
+}
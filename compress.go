@@ -0,0 +1,146 @@
+package xmlrpc
+
+import (
+	"compress/gzip"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+/*
+Compress returns middleware that compresses responses with gzip for callers that accept it (Accept-Encoding).
+Responses are buffered only until they reach threshold bytes, smaller responses are written uncompressed and
+bigger are compressed as they are written (multi-MB responses are never held in memory). Flushed responses are
+compressed regardless of their size.
+*/
+func Compress(threshold int) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("Vary", "Accept-Encoding")
+			if !acceptsGzip(r.Header.Get("Accept-Encoding")) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			writer := &compressWriter{ResponseWriter: w, threshold: threshold, status: http.StatusOK}
+			defer writer.Close()
+			next.ServeHTTP(writer, r)
+		})
+	}
+}
+
+/*
+acceptsGzip returns whether Accept-Encoding header allows gzip, explicit gzip coding has precedence over "*"
+*/
+func acceptsGzip(header string) bool {
+	accepted := map[string]bool{}
+	for _, item := range strings.Split(header, ",") {
+		parts := strings.Split(item, ";")
+		coding := strings.ToLower(strings.TrimSpace(parts[0]))
+		q := 1.0
+		for _, param := range parts[1:] {
+			if param = strings.TrimSpace(param); strings.HasPrefix(param, "q=") {
+				q, _ = strconv.ParseFloat(strings.TrimPrefix(param, "q="), 64)
+			}
+		}
+		accepted[coding] = q > 0
+	}
+
+	if ok, listed := accepted["gzip"]; listed {
+		return ok
+	}
+	return accepted["*"]
+}
+
+/*
+compressWriter buffers response until it reaches threshold, then writes it compressed
+*/
+type compressWriter struct {
+	http.ResponseWriter
+	threshold int
+	status    int
+
+	// buffer of response until it's decided whether it's compressed
+	buffer  []byte
+	decided bool
+	gzip    *gzip.Writer
+}
+
+func (c *compressWriter) WriteHeader(status int) {
+	if !c.decided {
+		c.status = status
+	}
+}
+
+func (c *compressWriter) Write(data []byte) (int, error) {
+	if !c.decided {
+		if len(c.buffer)+len(data) < c.threshold {
+			c.buffer = append(c.buffer, data...)
+			return len(data), nil
+		}
+		if err := c.start(true); err != nil {
+			return 0, err
+		}
+	}
+
+	if c.gzip != nil {
+		return c.gzip.Write(data)
+	}
+	return c.ResponseWriter.Write(data)
+}
+
+/*
+Flush writes buffered response compressed and flushes it
+*/
+func (c *compressWriter) Flush() {
+	if !c.decided {
+		c.start(true)
+	}
+	if c.gzip != nil {
+		c.gzip.Flush()
+	}
+	if flusher, ok := c.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+/*
+start writes header and buffered response, compressed or not
+*/
+func (c *compressWriter) start(compress bool) error {
+	c.decided = true
+
+	header := c.Header()
+	// responses without body and already encoded responses are not compressed
+	if header.Get("Content-Encoding") != "" || c.status == http.StatusNoContent || c.status == http.StatusNotModified {
+		compress = false
+	}
+
+	if compress {
+		header.Set("Content-Encoding", "gzip")
+		header.Del("Content-Length")
+		c.ResponseWriter.WriteHeader(c.status)
+		c.gzip = gzip.NewWriter(c.ResponseWriter)
+		_, err := c.gzip.Write(c.buffer)
+		c.buffer = nil
+		return err
+	}
+
+	c.ResponseWriter.WriteHeader(c.status)
+	_, err := c.ResponseWriter.Write(c.buffer)
+	c.buffer = nil
+	return err
+}
+
+/*
+Close writes response smaller than threshold or finishes compressed response
+*/
+func (c *compressWriter) Close() error {
+	if !c.decided {
+		return c.start(false)
+	}
+	if c.gzip != nil {
+		return c.gzip.Close()
+	}
+	return nil
+}
@@ -0,0 +1,210 @@
+package xmlrpc
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+)
+
+/*
+ServeConns serves framed calls (see ServePipe) on connections accepted on listener, every connection is served in
+its own goroutine. Connection idle for longer than timeout (or stuck in the middle of frame) is closed, zero timeout
+means no deadline.
+*/
+func ServeConns(ctx context.Context, l net.Listener, h Handler, timeout time.Duration) error {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return err
+		}
+		go func() {
+			defer conn.Close()
+			ServePipe(ctx, h, deadlineConn{Conn: conn, timeout: timeout}, deadlineConn{Conn: conn, timeout: timeout})
+		}()
+	}
+}
+
+/*
+deadlineConn extends deadline of connection before every read and write
+*/
+type deadlineConn struct {
+	net.Conn
+	timeout time.Duration
+}
+
+func (c deadlineConn) Read(p []byte) (int, error) {
+	if c.timeout > 0 {
+		c.Conn.SetReadDeadline(time.Now().Add(c.timeout))
+	}
+	return c.Conn.Read(p)
+}
+
+func (c deadlineConn) Write(p []byte) (int, error) {
+	if c.timeout > 0 {
+		c.Conn.SetWriteDeadline(time.Now().Add(c.timeout))
+	}
+	return c.Conn.Write(p)
+}
+
+/*
+Dialer opens connection to server of ConnClient
+*/
+type Dialer func(ctx context.Context) (net.Conn, error)
+
+/*
+ConnClientOption configures ConnClient
+*/
+type ConnClientOption func(*ConnClient)
+
+/*
+WithConnTimeout sets deadline of every call (context deadline is used when it's earlier), default is 30 seconds,
+zero means no deadline
+*/
+func WithConnTimeout(timeout time.Duration) ConnClientOption {
+	return func(c *ConnClient) {
+		c.timeout = timeout
+	}
+}
+
+/*
+WithConnRetries sets how many times call is sent again on new connection when it cannot be sent on existing one
+(e.g. server closed idle connection), default is 1. Calls are never sent again after they were written.
+*/
+func WithConnRetries(retries int) ConnClientOption {
+	return func(c *ConnClient) {
+		c.retries = retries
+	}
+}
+
+/*
+ConnClient calls methods with framed calls (see ServeConns) over connection opened by dialer. It's safe for
+concurrent use by multiple goroutines, calls are serialized over single connection (use more clients for parallel
+calls). Connection broken by error or deadline is closed and next call dials new one, so torn connection never
+leaves client waiting for response that doesn't come.
+*/
+type ConnClient struct {
+	mutex   sync.Mutex
+	dial    Dialer
+	conn    net.Conn
+	timeout time.Duration
+	retries int
+}
+
+/*
+NewConnClient returns client that opens connections by dial
+*/
+func NewConnClient(dial Dialer, options ...ConnClientOption) *ConnClient {
+	c := &ConnClient{
+		dial:    dial,
+		timeout: 30 * time.Second,
+		retries: 1,
+	}
+	for _, option := range options {
+		option(c)
+	}
+	return c
+}
+
+/*
+Call calls method with params and returns its result, fault responses are returned as *Fault
+*/
+func (c *ConnClient) Call(ctx context.Context, method string, params ...*Value) (*Value, error) {
+	call := encodeCall(method, params)
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	for attempt := 0; ; attempt++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		reused := c.conn != nil
+		if !reused {
+			conn, err := c.dial(ctx)
+			if err != nil {
+				return nil, err
+			}
+			c.conn = conn
+		}
+		c.conn.SetDeadline(c.deadline(ctx))
+
+		if err := WriteFrame(c.conn, call); err != nil {
+			c.drop()
+			// only calls written to connection that was closed meanwhile are sent again
+			if reused && attempt < c.retries {
+				continue
+			}
+			return nil, err
+		}
+
+		response, err := c.readFrame(ctx)
+		if err != nil {
+			c.drop()
+			return nil, err
+		}
+		return parseResponse(ctx, response)
+	}
+}
+
+/*
+readFrame reads response frame, reading is interrupted when context is done
+*/
+func (c *ConnClient) readFrame(ctx context.Context) ([]byte, error) {
+	done := make(chan struct{})
+	defer close(done)
+
+	conn := c.conn
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.SetDeadline(time.Now())
+		case <-done:
+		}
+	}()
+
+	response, err := ReadFrame(conn, MaxFrameSize)
+	if err != nil && ctx.Err() != nil {
+		return nil, ctx.Err()
+	} else if err != nil {
+		return nil, fmt.Errorf("xmlrpc: cannot read response: %v", err)
+	}
+	return response, nil
+}
+
+/*
+deadline returns deadline of call, zero time means no deadline
+*/
+func (c *ConnClient) deadline(ctx context.Context) time.Time {
+	var deadline time.Time
+	if c.timeout > 0 {
+		deadline = time.Now().Add(c.timeout)
+	}
+	if d, ok := ctx.Deadline(); ok && (deadline.IsZero() || d.Before(deadline)) {
+		deadline = d
+	}
+	return deadline
+}
+
+/*
+Close closes current connection, next call opens new one
+*/
+func (c *ConnClient) Close() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.drop()
+}
+
+/*
+drop closes current connection, mutex must be held
+*/
+func (c *ConnClient) drop() error {
+	if c.conn == nil {
+		return nil
+	}
+	err := c.conn.Close()
+	c.conn = nil
+	return err
+}
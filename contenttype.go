@@ -0,0 +1,43 @@
+package xmlrpc
+
+import (
+	"mime"
+	"strings"
+)
+
+var (
+	// DefaultContentTypes are content types accepted by handler by default
+	DefaultContentTypes = []string{"text/xml", "application/xml"}
+)
+
+/*
+WithContentTypes sets content types accepted by handler (DefaultContentTypes if not set). Requests with other
+content type are rejected with fault. Requests without Content-Type header are accepted.
+*/
+func WithContentTypes(contentTypes ...string) HandlerOption {
+	return func(h *handler) {
+		h.contentTypes = contentTypes
+	}
+}
+
+/*
+checkContentType checks media type of request (parameters as charset are ignored)
+*/
+func checkContentType(contentType string, allowed []string) error {
+	if contentType == "" {
+		return nil
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return Errorf(FaultInvalidRequest, "invalid content type %v", contentType)
+	}
+
+	for _, item := range allowed {
+		if strings.EqualFold(mediaType, item) {
+			return nil
+		}
+	}
+
+	return Errorf(FaultInvalidRequest, "unsupported content type %v", mediaType)
+}
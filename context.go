@@ -0,0 +1,63 @@
+package xmlrpc
+
+import "context"
+
+/*
+contextKey is type for keys of values stored in request context
+*/
+type contextKey int
+
+const (
+	identityContextKey contextKey = iota
+	requestIDContextKey
+	faultStatusContextKey
+	dateTimePolicyContextKey
+	attachmentsContextKey
+	idempotencyKeyContextKey
+	tenantContextKey
+	etagContextKey
+	arrayLimitContextKey
+	cacheBypassContextKey
+	peerContextKey
+)
+
+/*
+Identity is authenticated caller, auth middlewares store it in request context
+*/
+type Identity struct {
+	// Name of caller (username, token owner...)
+	Name string
+
+	// Roles of caller, methods with "//xmlrpc:roles" directive are allowed only to callers with one of their roles
+	Roles []string
+}
+
+/*
+ContextWithIdentity returns new context with caller identity
+*/
+func ContextWithIdentity(ctx context.Context, identity *Identity) context.Context {
+	return context.WithValue(ctx, identityContextKey, identity)
+}
+
+/*
+IdentityFromContext returns caller identity stored by auth middleware, nil if caller is not authenticated
+*/
+func IdentityFromContext(ctx context.Context) *Identity {
+	identity, _ := ctx.Value(identityContextKey).(*Identity)
+	return identity
+}
+
+/*
+ContextWithRequestID returns new context with request id
+*/
+func ContextWithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDContextKey, requestID)
+}
+
+/*
+RequestIDFromContext returns request id of current call (either given by caller in X-Request-ID header or generated)
+*/
+func RequestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDContextKey).(string)
+	return requestID
+}
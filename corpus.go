@@ -0,0 +1,136 @@
+package xmlrpc
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+/*
+CorpusExpectation is expected result of corpus fixture. Fixture "name.xml" is checked against "name.json".
+Only fields present in expectation are checked.
+*/
+type CorpusExpectation struct {
+	// Fault is expected fault code when document cannot be decoded (e.g. FaultParseError)
+	Fault int `json:"fault,omitempty"`
+
+	// Kind of payload ("call", "response", "fault")
+	Kind string `json:"kind,omitempty"`
+
+	// Method name of call
+	Method string `json:"method,omitempty"`
+
+	// Params as json (dateTime as RFC 3339 string, base64 as base64 string)
+	Params json.RawMessage `json:"params,omitempty"`
+
+	// Violations are paths of expected spec violations
+	Violations []string `json:"violations,omitempty"`
+}
+
+/*
+CorpusFailure is fixture that doesn't match its expectation
+*/
+type CorpusFailure struct {
+	Fixture string
+	Message string
+}
+
+func (c CorpusFailure) String() string {
+	return c.Fixture + ": " + c.Message
+}
+
+/*
+CheckCorpus inspects all xml fixtures in directory and checks them against their expectations, so problem
+payloads from real servers can be collected as regression corpus. Fixtures without expectation are failures.
+*/
+func CheckCorpus(dir string) (failures []CorpusFailure, checked int, err error) {
+	var fixtures []string
+	if fixtures, err = filepath.Glob(filepath.Join(dir, "*.xml")); err != nil {
+		return
+	}
+	sort.Strings(fixtures)
+
+	for _, fixture := range fixtures {
+		checked++
+		if message := checkFixture(fixture); message != "" {
+			failures = append(failures, CorpusFailure{Fixture: filepath.Base(fixture), Message: message})
+		}
+	}
+
+	return
+}
+
+/*
+checkFixture returns description of mismatch, empty string when fixture matches its expectation
+*/
+func checkFixture(fixture string) string {
+	data, err := ioutil.ReadFile(strings.TrimSuffix(fixture, ".xml") + ".json")
+	if err != nil {
+		return fmt.Sprintf("cannot read expectation: %v", err)
+	}
+
+	var expected CorpusExpectation
+	if err = json.Unmarshal(data, &expected); err != nil {
+		return fmt.Sprintf("invalid expectation: %v", err)
+	}
+
+	f, err := os.Open(fixture)
+	if err != nil {
+		return err.Error()
+	}
+	defer f.Close()
+
+	payload, err := Inspect(f)
+	if err != nil {
+		if expected.Fault == 0 {
+			return fmt.Sprintf("unexpected error: %v", err)
+		}
+		if code := errorCode(err); code != expected.Fault {
+			return fmt.Sprintf("expected fault %d, got %d (%v)", expected.Fault, code, err)
+		}
+		return ""
+	}
+
+	if expected.Fault != 0 {
+		return fmt.Sprintf("expected fault %d, document was decoded", expected.Fault)
+	}
+	if expected.Kind != "" && expected.Kind != payload.Kind {
+		return fmt.Sprintf("expected kind %v, got %v", expected.Kind, payload.Kind)
+	}
+	if expected.Method != "" && expected.Method != payload.Method {
+		return fmt.Sprintf("expected method %v, got %v", expected.Method, payload.Method)
+	}
+
+	if len(expected.Params) > 0 {
+		// params are compared as json, so expectation doesn't depend on go types
+		actual, err := json.Marshal(payload.Params)
+		if err != nil {
+			return err.Error()
+		}
+
+		var want, got interface{}
+		if err = json.Unmarshal(expected.Params, &want); err != nil {
+			return fmt.Sprintf("invalid expected params: %v", err)
+		}
+		json.Unmarshal(actual, &got)
+
+		if !reflect.DeepEqual(want, got) {
+			return fmt.Sprintf("expected params %s, got %s", expected.Params, actual)
+		}
+	}
+
+	paths := make([]string, 0, len(payload.Violations))
+	for _, violation := range payload.Violations {
+		paths = append(paths, violation.Path)
+	}
+	if strings.Join(paths, ",") != strings.Join(expected.Violations, ",") {
+		return fmt.Sprintf("expected violations %v, got %v", expected.Violations, payload.Violations)
+	}
+
+	return ""
+}
@@ -0,0 +1,16 @@
+package xmlrpc
+
+import "testing"
+
+func TestCorpus(t *testing.T) {
+	failures, checked, err := CheckCorpus("corpus")
+	if err != nil {
+		t.Fatalf("cannot check corpus: %v", err)
+	}
+	if checked == 0 {
+		t.Fatalf("corpus has no fixtures")
+	}
+	for _, failure := range failures {
+		t.Error(failure)
+	}
+}
@@ -0,0 +1,132 @@
+package xmlrpc
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/beevik/etree"
+)
+
+/*
+DateTimePolicy configures how dateTime.iso8601 values are read and written. Many servers send local time
+without offset, so Location is assumed for such values.
+*/
+type DateTimePolicy struct {
+	// Location is assumed for values without offset, written values are converted to it (UTC when nil)
+	Location *time.Location
+
+	// Offset writes offset of Location ("Z" or "+02:00"), classic format without offset is written otherwise
+	Offset bool
+
+	// Fraction writes fractional seconds (without trailing zeros)
+	Fraction bool
+}
+
+var (
+	// DefaultDateTimePolicy is used when handler has no other policy set
+	DefaultDateTimePolicy = DateTimePolicy{Location: time.UTC}
+
+	// dateTimeLayouts are accepted layouts, layouts without zone are parsed in policy location
+	dateTimeLayouts = []string{
+		"20060102T15:04:05",
+		"20060102T15:04:05Z07:00",
+		"20060102T15:04:05Z0700",
+		"2006-01-02T15:04:05",
+		"2006-01-02T15:04:05Z07:00",
+		"2006-01-02T15:04:05Z0700",
+	}
+)
+
+/*
+WithDateTimePolicy sets policy used by generated code for dateTime.iso8601 values
+*/
+func WithDateTimePolicy(policy DateTimePolicy) HandlerOption {
+	return func(h *handler) {
+		h.dateTimePolicy = policy
+	}
+}
+
+/*
+ContextWithDateTimePolicy returns new context with dateTime policy, handler stores its policy for every call
+*/
+func ContextWithDateTimePolicy(ctx context.Context, policy DateTimePolicy) context.Context {
+	return context.WithValue(ctx, dateTimePolicyContextKey, policy)
+}
+
+/*
+DateTimePolicyFromContext returns dateTime policy stored in context, DefaultDateTimePolicy if none is stored
+*/
+func DateTimePolicyFromContext(ctx context.Context) DateTimePolicy {
+	if policy, ok := ctx.Value(dateTimePolicyContextKey).(DateTimePolicy); ok {
+		return policy
+	}
+	return DefaultDateTimePolicy
+}
+
+/*
+Format returns dateTime.iso8601 representation of t
+*/
+func (p DateTimePolicy) Format(t time.Time) string {
+	layout := "20060102T15:04:05"
+	if p.Fraction {
+		layout += ".999999999"
+	}
+	if p.Offset {
+		layout += "Z07:00"
+	}
+	return t.In(p.location()).Format(layout)
+}
+
+/*
+Parse parses dateTime.iso8601 value, both basic (20060102T15:04:05) and extended (2006-01-02T15:04:05) forms
+are accepted with optional fractional seconds and offset
+*/
+func (p DateTimePolicy) Parse(text string) (result time.Time, err error) {
+	text = strings.TrimSpace(text)
+
+	for _, layout := range dateTimeLayouts {
+		// fractional seconds are accepted by parser even when they are not in layout
+		if result, err = time.ParseInLocation(layout, text, p.location()); err == nil {
+			return
+		}
+	}
+
+	return
+}
+
+/*
+location returns policy location, UTC when not set
+*/
+func (p DateTimePolicy) location() *time.Location {
+	if p.Location == nil {
+		return time.UTC
+	}
+	return p.Location
+}
+
+/*
+FormatTime returns dateTime.iso8601 representation of t by policy stored in context
+*/
+func FormatTime(ctx context.Context, t time.Time) string {
+	return DateTimePolicyFromContext(ctx).Format(t)
+}
+
+/*
+XPathValueGetTime Returns time from dateTime.iso8601 value (or ex:dateTime of Apache library), it's parsed by
+policy stored in context
+*/
+func XPathValueGetTime(ctx context.Context, element *etree.Element, name string) (result time.Time, err error) {
+	var tmp *etree.Element
+
+	if tmp = childElement(element, "dateTime.iso8601", "dateTime"); tmp == nil {
+		err = Errorf(FaultInvalidParams, "not found %v", name)
+		return
+	}
+
+	if result, err = DateTimePolicyFromContext(ctx).Parse(tmp.Text()); err != nil {
+		err = Errorf(FaultInvalidParams, "invalid dateTime %v", name)
+	}
+
+	return
+}
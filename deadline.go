@@ -0,0 +1,75 @@
+package xmlrpc
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	// TimeoutHeader is http header that carries remaining time of caller's deadline in milliseconds (relative, so
+	// clocks of hosts don't need to be synchronized)
+	TimeoutHeader = "X-Timeout-Ms"
+)
+
+/*
+SetTimeoutHeader sets TimeoutHeader of request to remaining time of context deadline, request is not modified
+when context has no deadline
+*/
+func SetTimeoutHeader(ctx context.Context, r *http.Request) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return
+	}
+	remaining := time.Until(deadline) / time.Millisecond
+	if remaining < 1 {
+		remaining = 1
+	}
+	r.Header.Set(TimeoutHeader, strconv.FormatInt(int64(remaining), 10))
+}
+
+/*
+contextWithTimeoutHeader returns context with deadline of caller given by TimeoutHeader, handler adopts it so
+timeouts compose across hops. Context is returned unchanged when header is missing or invalid.
+*/
+func contextWithTimeoutHeader(ctx context.Context, r *http.Request) (context.Context, context.CancelFunc) {
+	ms, err := strconv.ParseInt(r.Header.Get(TimeoutHeader), 10, 64)
+	if err != nil || ms <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, time.Duration(ms)*time.Millisecond)
+}
+
+/*
+NewDeadlineTransport returns http transport for clients, it sends remaining time of request context deadline in
+TimeoutHeader. Nil base is http.DefaultTransport.
+*/
+func NewDeadlineTransport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return deadlineTransport{base: base}
+}
+
+/*
+deadlineTransport sets TimeoutHeader of requests
+*/
+type deadlineTransport struct {
+	base http.RoundTripper
+}
+
+func (d deadlineTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	if _, ok := request.Context().Deadline(); !ok {
+		return d.base.RoundTrip(request)
+	}
+
+	// request must not be modified, it's cloned
+	clone := request.WithContext(request.Context())
+	clone.Header = http.Header{}
+	for name, values := range request.Header {
+		clone.Header[name] = values
+	}
+	SetTimeoutHeader(request.Context(), clone)
+	return d.base.RoundTrip(clone)
+}
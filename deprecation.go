@@ -0,0 +1,90 @@
+package xmlrpc
+
+import (
+	"bytes"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+/*
+DeprecationFunc is called by transport of NewDeprecationTransport with deprecated method and its notice
+*/
+type DeprecationFunc func(method string, notice string)
+
+/*
+NewDeprecationTransport returns http transport for clients, it reads Deprecation header of responses and calls
+notify with method and deprecation notice (from Warning header) once per method, so callers learn about deprecated
+methods they still use. Nil notify logs notice by log package, nil base is http.DefaultTransport.
+*/
+func NewDeprecationTransport(base http.RoundTripper, notify DeprecationFunc) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if notify == nil {
+		notify = func(method string, notice string) {
+			log.Printf("xmlrpc: method %v is deprecated: %v", method, notice)
+		}
+	}
+	return &deprecationTransport{
+		base:     base,
+		notify:   notify,
+		notified: map[string]bool{},
+	}
+}
+
+/*
+deprecationTransport remembers deprecated methods that were already notified
+*/
+type deprecationTransport struct {
+	base     http.RoundTripper
+	notify   DeprecationFunc
+	mutex    sync.Mutex
+	notified map[string]bool
+}
+
+func (d *deprecationTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	if request.Body == nil {
+		return d.base.RoundTrip(request)
+	}
+
+	body, err := ioutil.ReadAll(request.Body)
+	request.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	// request must not be modified, it's cloned
+	clone := request.WithContext(request.Context())
+	clone.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	response, err := d.base.RoundTrip(clone)
+	if err != nil || response.Header.Get("Deprecation") == "" {
+		return response, err
+	}
+
+	method := callMethodName(body)
+	d.mutex.Lock()
+	notified := d.notified[method]
+	d.notified[method] = true
+	d.mutex.Unlock()
+
+	if !notified {
+		d.notify(method, deprecationNotice(response.Header.Get("Warning")))
+	}
+	return response, nil
+}
+
+/*
+deprecationNotice returns deprecation notice of Warning header (see deprecationWarning)
+*/
+func deprecationNotice(warning string) string {
+	text := strings.TrimPrefix(warning, "299 - ")
+	if notice, err := strconv.Unquote(text); err == nil {
+		return notice
+	}
+	return text
+}
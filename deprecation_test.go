@@ -0,0 +1,63 @@
+package xmlrpc
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+/*
+deprecatedRegistry is registry with help of methods
+*/
+type deprecatedRegistry struct {
+	*Registry
+}
+
+func (d deprecatedRegistry) MethodHelp() map[string]string {
+	return map[string]string{"Slow": "Slow is slow.\n\nDeprecated: use Fast."}
+}
+
+func TestDeprecationWithHeartbeat(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register("Slow", func(ctx context.Context, params ...*Value) (*Value, error) {
+		time.Sleep(50 * time.Millisecond)
+		return NewInt(42), nil
+	})
+	h := NewHandler(WithHeartbeat(10 * time.Millisecond))
+	if err := h.AddService(&deprecatedRegistry{registry}, "posts"); err != nil {
+		t.Fatalf("cannot add service: %v", err)
+	}
+
+	server := httptest.NewServer(h)
+	defer server.Close()
+
+	var notices []string
+	client := &http.Client{Transport: NewDeprecationTransport(nil, func(method string, notice string) {
+		notices = append(notices, method+": "+notice)
+	})}
+
+	for i := 0; i < 2; i++ {
+		response, err := client.Post(server.URL, "text/xml", bytes.NewReader(encodeCall("posts.Slow", nil)))
+		if err != nil {
+			t.Fatalf("call failed: %v", err)
+		}
+		body, _ := ioutil.ReadAll(response.Body)
+		response.Body.Close()
+
+		if response.Header.Get("Deprecation") != "true" {
+			t.Errorf("expected deprecation header, got %v", response.Header)
+		}
+		if result, err := parseResponse(context.Background(), body); err != nil || result.Int() != 42 {
+			t.Errorf("expected full response, got %s (%v)", body, err)
+		}
+	}
+
+	// method is notified once
+	if len(notices) != 1 || notices[0] != "posts.Slow: Deprecated: use Fast." {
+		t.Errorf("expected single notice, got %q", notices)
+	}
+}
@@ -0,0 +1,81 @@
+package xmlrpc
+
+import (
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/beevik/etree"
+)
+
+/*
+FaultDetailer can be implemented by errors returned from service methods. Detail is added to fault struct as
+"detail" member. Supported values are string, bool, ints, floats, time.Time, []byte, []string,
+[]interface{}, map[string]interface{} (recursively) and types with registered codec, other values (and nil) are
+written as strings.
+*/
+type FaultDetailer interface {
+	FaultDetail() map[string]interface{}
+}
+
+/*
+XMLWriteInterface writes dynamic value to value element
+*/
+func XMLWriteInterface(element *etree.Element, value interface{}) {
+	switch v := value.(type) {
+	case string:
+		element.CreateElement("string").SetText(v)
+	case bool:
+		element.CreateElement("boolean").SetText(FormatBool(v))
+	case int:
+		element.CreateElement("int").SetText(strconv.Itoa(v))
+	case int8:
+		element.CreateElement("int").SetText(strconv.Itoa(int(v)))
+	case int16:
+		element.CreateElement("int").SetText(strconv.Itoa(int(v)))
+	case int32:
+		element.CreateElement("int").SetText(strconv.Itoa(int(v)))
+	case int64:
+		element.CreateElement("int").SetText(strconv.FormatInt(v, 10))
+	case float32:
+		element.CreateElement("double").SetText(FormatDouble(float64(v), 32))
+	case float64:
+		element.CreateElement("double").SetText(FormatDouble(v, 64))
+	case time.Time:
+		element.CreateElement("dateTime.iso8601").SetText(DefaultDateTimePolicy.Format(v))
+	case []byte:
+		element.CreateElement("base64").SetText(base64.StdEncoding.EncodeToString(v))
+	case []string:
+		XMLWriteStringSlice(element, v)
+	case []interface{}:
+		data := element.CreateElement("array").CreateElement("data")
+		for _, item := range v {
+			XMLWriteInterface(data.CreateElement("value"), item)
+		}
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		strukt := element.CreateElement("struct")
+		for _, key := range keys {
+			member := strukt.CreateElement("member")
+			member.CreateElement("name").SetText(key)
+			XMLWriteInterface(member.CreateElement("value"), v[key])
+		}
+	case error:
+		element.CreateElement("string").SetText(v.Error())
+	default:
+		// custom types are encoded by registered codecs
+		if encoded, ok, err := codecValue(v); ok && err == nil {
+			if err = XMLWriteRaw(element, encoded.Raw()); err == nil {
+				return
+			}
+		}
+		element.CreateElement("string").SetText(fmt.Sprint(v))
+	}
+}
@@ -0,0 +1,175 @@
+package xmlrpc
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/beevik/etree"
+)
+
+var (
+	// plainDouble matches doubles allowed by specification (optional sign, dot as decimal separator)
+	plainDouble = regexp.MustCompile(`^[+-]?(\d+(\.\d*)?|\.\d+)$`)
+
+	// lenientDouble matches doubles accepted by lenient parser after decimal separator is normalized
+	lenientDouble = regexp.MustCompile(`^[+-]?(\d+(\.\d*)?|\.\d+)([eE][+-]?\d+)?$`)
+)
+
+/*
+ParseDouble parses double as allowed by specification, exponents, infinities and NaN are rejected
+*/
+func ParseDouble(text string) (float64, error) {
+	text = strings.TrimSpace(text)
+	if !plainDouble.MatchString(text) {
+		return 0, strconv.ErrSyntax
+	}
+	return strconv.ParseFloat(text, 64)
+}
+
+/*
+ParseDoubleLenient parses double with comma as decimal separator or with exponent. When both comma and dot
+are present the last one is decimal separator and the other one separates digit groups.
+*/
+func ParseDoubleLenient(text string) (float64, error) {
+	text = strings.TrimSpace(text)
+
+	comma, dot := strings.LastIndex(text, ","), strings.LastIndex(text, ".")
+	switch {
+	case comma > dot:
+		text = strings.Replace(strings.Replace(text, ".", "", -1), ",", ".", -1)
+	case comma >= 0:
+		text = strings.Replace(text, ",", "", -1)
+	}
+
+	if strings.Count(text, ".") > 1 || !lenientDouble.MatchString(text) {
+		return 0, strconv.ErrSyntax
+	}
+	return strconv.ParseFloat(text, 64)
+}
+
+/*
+ParseNonFiniteDouble parses "NaN", "Infinity" and "-Infinity" (case insensitive, "inf" and "+inf" are also
+accepted)
+*/
+func ParseNonFiniteDouble(text string) (float64, error) {
+	switch strings.ToLower(strings.TrimSpace(text)) {
+	case "nan":
+		return math.NaN(), nil
+	case "infinity", "+infinity", "inf", "+inf":
+		return math.Inf(1), nil
+	case "-infinity", "-inf":
+		return math.Inf(-1), nil
+	}
+	return 0, strconv.ErrSyntax
+}
+
+/*
+FormatDouble returns plain decimal representation of double (no exponent) as required by specification. bitSize
+is 32 for float32 values so they are written with shortest representation of float32. NaN and infinities are
+written as "NaN", "Infinity" and "-Infinity", generated code checks them by FiniteDouble unless
+gen.WithNonFiniteDoubles is used.
+*/
+func FormatDouble(value float64, bitSize int) string {
+	return FormatDoubleWith(value, 'f', -1, bitSize)
+}
+
+/*
+FormatDoubleWith returns double formatted by strconv format ('f', 'g' or 'e') with given precision (-1 is shortest
+representation that reads back exactly), e.g. for servers that need fixed count of decimals. Exponents written by
+'g' and 'e' are extension of specification. NaN and infinities are written as by FormatDouble.
+*/
+func FormatDoubleWith(value float64, format byte, precision, bitSize int) string {
+	switch {
+	case math.IsNaN(value):
+		return "NaN"
+	case math.IsInf(value, 1):
+		return "Infinity"
+	case math.IsInf(value, -1):
+		return "-Infinity"
+	}
+	return strconv.FormatFloat(value, format, precision, bitSize)
+}
+
+/*
+FiniteDouble returns error when value cannot be written by specification (NaN or infinity)
+*/
+func FiniteDouble(value float64, name string) error {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return Errorf(FaultInternalError, "cannot encode %v of %v", FormatDouble(value, 64), name)
+	}
+	return nil
+}
+
+/*
+XPathValueGetDouble Returns double from value
+*/
+func XPathValueGetDouble(element *etree.Element, name string) (result float64, err error) {
+	return xpathValueDouble(element, name, ParseDouble)
+}
+
+/*
+XPathValueGetDoubleLenient Returns double from value, doubles are parsed by ParseDoubleLenient
+*/
+func XPathValueGetDoubleLenient(element *etree.Element, name string) (result float64, err error) {
+	return xpathValueDouble(element, name, ParseDoubleLenient)
+}
+
+/*
+XPathValueCoerceDouble Returns double from value, ints and strings are converted (text is parsed by ParseDouble)
+*/
+func XPathValueCoerceDouble(element *etree.Element, name string) (result float64, err error) {
+	return xpathValueCoerceDouble(element, name, ParseDouble)
+}
+
+/*
+XPathValueCoerceDoubleLenient Returns double from value, ints and strings are converted (text is parsed by
+ParseDoubleLenient)
+*/
+func XPathValueCoerceDoubleLenient(element *etree.Element, name string) (result float64, err error) {
+	return xpathValueCoerceDouble(element, name, ParseDoubleLenient)
+}
+
+func xpathValueCoerceDouble(element *etree.Element, name string, parse func(string) (float64, error)) (result float64, err error) {
+	tag, text, ok := scalarValue(element)
+	if !ok {
+		err = Errorf(FaultInvalidParams, "not found %v", name)
+		return
+	}
+
+	switch tag {
+	case "double", "int", "i4", "string":
+	default:
+		err = Errorf(FaultInvalidParams, "cannot convert %v %v to double", tag, name)
+		return
+	}
+
+	if result, err = parse(text); err != nil {
+		err = Errorf(FaultInvalidParams, "invalid double %v", name)
+	}
+
+	return
+}
+
+/*
+XPathValueGetNonFiniteDouble Returns NaN or infinity from value (see gen.WithNonFiniteDoubles)
+*/
+func XPathValueGetNonFiniteDouble(element *etree.Element, name string) (result float64, err error) {
+	return xpathValueDouble(element, name, ParseNonFiniteDouble)
+}
+
+func xpathValueDouble(element *etree.Element, name string, parse func(string) (float64, error)) (result float64, err error) {
+	var tmp *etree.Element
+
+	if tmp = childElement(element, "double"); tmp == nil {
+		err = Errorf(FaultInvalidParams, "not found %v", name)
+		return
+	}
+
+	if result, err = parse(tmp.Text()); err != nil {
+		err = Errorf(FaultInvalidParams, "invalid double %v", name)
+	}
+
+	return
+}
@@ -0,0 +1,175 @@
+package xmlrpc
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/beevik/etree"
+)
+
+func TestParseDouble(t *testing.T) {
+	for _, item := range []struct {
+		text     string
+		expected float64
+		ok       bool
+	}{
+		{"1.5", 1.5, true},
+		{"-1.5", -1.5, true},
+		{"+1.5", 1.5, true},
+		{" 42 ", 42, true},
+		{"1.", 1, true},
+		{".5", 0.5, true},
+		{"0", 0, true},
+		{"1,5", 0, false},
+		{"1.5e3", 0, false},
+		{"1E3", 0, false},
+		{"NaN", 0, false},
+		{"Infinity", 0, false},
+		{"0x10", 0, false},
+		{"1_000", 0, false},
+		{"", 0, false},
+		{".", 0, false},
+	} {
+		result, err := ParseDouble(item.text)
+		if (err == nil) != item.ok {
+			t.Errorf("%q: expected ok %v, got error %v", item.text, item.ok, err)
+			continue
+		}
+		if item.ok && result != item.expected {
+			t.Errorf("%q: expected %v, got %v", item.text, item.expected, result)
+		}
+	}
+}
+
+func TestParseDoubleLenient(t *testing.T) {
+	for _, item := range []struct {
+		text     string
+		expected float64
+		ok       bool
+	}{
+		{"1.5", 1.5, true},
+		{"1,5", 1.5, true},
+		{"-1,5", -1.5, true},
+		{"1.234,5", 1234.5, true},
+		{"1,234.5", 1234.5, true},
+		{"1.234.567,89", 1234567.89, true},
+		{"1,234,567.89", 1234567.89, true},
+		{"1.5e3", 1500, true},
+		{"1,5e3", 1500, true},
+		{"1.5E-3", 0.0015, true},
+		{"-2e+2", -200, true},
+		{" 7 ", 7, true},
+		{"1.2.3", 0, false},
+		{"1e", 0, false},
+		{"e3", 0, false},
+		{"1,5,", 0, false},
+		{"NaN", 0, false},
+		{"Infinity", 0, false},
+		{"abc", 0, false},
+		{"", 0, false},
+	} {
+		result, err := ParseDoubleLenient(item.text)
+		if (err == nil) != item.ok {
+			t.Errorf("%q: expected ok %v, got error %v", item.text, item.ok, err)
+			continue
+		}
+		if item.ok && result != item.expected {
+			t.Errorf("%q: expected %v, got %v", item.text, item.expected, result)
+		}
+	}
+}
+
+func TestParseNonFiniteDouble(t *testing.T) {
+	for _, item := range []struct {
+		text     string
+		expected float64
+	}{
+		{"NaN", math.NaN()},
+		{"nan", math.NaN()},
+		{"Infinity", math.Inf(1)},
+		{"+inf", math.Inf(1)},
+		{"-Infinity", math.Inf(-1)},
+		{"-INF", math.Inf(-1)},
+	} {
+		result, err := ParseNonFiniteDouble(item.text)
+		if err != nil {
+			t.Errorf("%q: unexpected error %v", item.text, err)
+			continue
+		}
+		if math.IsNaN(item.expected) != math.IsNaN(result) || !math.IsNaN(result) && result != item.expected {
+			t.Errorf("%q: expected %v, got %v", item.text, item.expected, result)
+		}
+	}
+
+	if _, err := ParseNonFiniteDouble("1.5"); err == nil {
+		t.Errorf("expected error for finite double")
+	}
+}
+
+func TestFormatDouble(t *testing.T) {
+	for _, item := range []struct {
+		value    float64
+		bitSize  int
+		expected string
+	}{
+		{1.5, 64, "1.5"},
+		{-1.5, 64, "-1.5"},
+		{0, 64, "0"},
+		{1e21, 64, "1000000000000000000000"},
+		{1e-7, 64, "0.0000001"},
+		{123456789.125, 64, "123456789.125"},
+		{math.MaxFloat64, 64, "17976931348623157" + strings.Repeat("0", 292)},
+		{math.SmallestNonzeroFloat64, 64, "0." + strings.Repeat("0", 323) + "5"},
+		{float64(float32(0.1)), 32, "0.1"},
+		{float64(float32(0.1)), 64, "0.10000000149011612"},
+		{math.NaN(), 64, "NaN"},
+		{math.Inf(1), 64, "Infinity"},
+		{math.Inf(-1), 64, "-Infinity"},
+	} {
+		result := FormatDouble(item.value, item.bitSize)
+		if result != item.expected {
+			t.Errorf("%v: expected %v, got %v", item.value, item.expected, result)
+		}
+		if math.IsNaN(item.value) || math.IsInf(item.value, 0) {
+			continue
+		}
+
+		// plain decimals read back exactly by strict parser
+		parsed, err := ParseDouble(result)
+		if err != nil {
+			t.Errorf("%v: %v is not plain decimal", item.value, result)
+		} else if item.bitSize == 64 && parsed != item.value {
+			t.Errorf("%v: read back as %v", item.value, parsed)
+		}
+	}
+}
+
+func TestXPathValueCoerceDouble(t *testing.T) {
+	for _, item := range []struct {
+		value   string
+		strict  bool
+		lenient bool
+	}{
+		{"<value><double>1.5</double></value>", true, true},
+		{"<value><int>2</int></value>", true, true},
+		{"<value><string>2.5</string></value>", true, true},
+		{"<value>2.5</value>", true, true},
+		{"<value><double>1,5</double></value>", false, true},
+		{"<value><string>1.5e3</string></value>", false, true},
+		{"<value><boolean>1</boolean></value>", false, false},
+	} {
+		doc := etree.NewDocument()
+		if err := doc.ReadFromString(item.value); err != nil {
+			t.Fatalf("invalid fixture %v: %v", item.value, err)
+		}
+		element := doc.Root()
+
+		if _, err := XPathValueCoerceDouble(element, "value"); (err == nil) != item.strict {
+			t.Errorf("%v: expected ok %v, got error %v", item.value, item.strict, err)
+		}
+		if _, err := XPathValueCoerceDoubleLenient(element, "value"); (err == nil) != item.lenient {
+			t.Errorf("%v: expected lenient ok %v, got error %v", item.value, item.lenient, err)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package xmlrpc
+
+import "github.com/beevik/etree"
+
+const (
+	// DuplicateMembersLast decodes last of struct members with same name (default)
+	DuplicateMembersLast = "last"
+
+	// DuplicateMembersFirst decodes first of struct members with same name
+	DuplicateMembersFirst = "first"
+
+	// DuplicateMembersError rejects structs with duplicate members
+	DuplicateMembersError = "error"
+)
+
+/*
+XPathUniqueMembers removes duplicate struct members by policy, first member with given name is kept for
+DuplicateMembersFirst and error is returned for DuplicateMembersError. Generated code doesn't call it for
+DuplicateMembersLast, later members overwrite earlier ones while decoding. Member names are compared by
+FoldMemberName when fold is true.
+*/
+func XPathUniqueMembers(members []*etree.Element, name string, policy string, fold bool) (result []*etree.Element, err error) {
+	seen := make(map[string]bool, len(members))
+	result = make([]*etree.Element, 0, len(members))
+
+	for _, member := range members {
+		var memberName string
+		if memberName, _, err = XPathStructMember(member); err != nil {
+			return nil, err
+		}
+		if fold {
+			memberName = FoldMemberName(memberName)
+		}
+
+		if seen[memberName] {
+			if policy == DuplicateMembersError {
+				return nil, Errorf(FaultInvalidParams, "duplicate member %v of %v", memberName, name)
+			}
+			continue
+		}
+
+		seen[memberName] = true
+		result = append(result, member)
+	}
+	return
+}
@@ -0,0 +1,17 @@
+package xmlrpc
+
+import "github.com/beevik/etree"
+
+/*
+Element is element of decoded request passed to services. Generated code refers to elements only through this
+package (helpers XPathValueGet* and friends), so it doesn't import DOM library and runtime can swap it without
+regenerating services. Responses are written through Node (see SetDocumentFactory), not Element.
+*/
+type Element = etree.Element
+
+/*
+NewElement returns detached element with given tag
+*/
+func NewElement(tag string) *Element {
+	return etree.NewElement(tag)
+}
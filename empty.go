@@ -0,0 +1,45 @@
+package xmlrpc
+
+import "github.com/beevik/etree"
+
+/*
+PHP servers can't tell empty struct from empty array, they send empty array instead of empty struct and vice
+versa. Generated code uses following helpers when lenient empty values are enabled (xmlrpcgen --lenient-empty),
+such values are decoded as zero values instead of failing.
+*/
+
+/*
+XPathValueGetStructOrEmpty returns struct members, empty array is decoded as struct without members
+*/
+func XPathValueGetStructOrEmpty(element *etree.Element, name string) (members []*etree.Element, err error) {
+	if isEmptyValue(element, "array") {
+		return nil, nil
+	}
+	return XPathValueGetStruct(element, name)
+}
+
+/*
+XPathValueGetArrayOrEmpty returns array values, empty struct is decoded as array without values
+*/
+func XPathValueGetArrayOrEmpty(element *etree.Element, name string) (values []*etree.Element, err error) {
+	if isEmptyValue(element, "struct") {
+		return nil, nil
+	}
+	return XPathValueGetArray(element, name)
+}
+
+/*
+isEmptyValue returns whether value is empty struct or array (given by tag), empty array can have empty data
+*/
+func isEmptyValue(element *etree.Element, tag string) bool {
+	value := childElement(element, tag)
+	if value == nil {
+		return false
+	}
+
+	children := value.ChildElements()
+	if tag == "array" && len(children) == 1 && children[0].Tag == "data" {
+		children = children[0].ChildElements()
+	}
+	return len(children) == 0
+}
@@ -0,0 +1,93 @@
+package xmlrpc
+
+import (
+	"encoding/xml"
+	"io"
+	"unicode/utf8"
+)
+
+/*
+XMLEncodeStart encodes start elements with given names
+*/
+func XMLEncodeStart(enc *xml.Encoder, names ...string) {
+	for _, name := range names {
+		enc.EncodeToken(xml.StartElement{Name: xml.Name{Local: name}})
+	}
+}
+
+/*
+XMLEncodeEnd encodes end elements with given names (in given order)
+*/
+func XMLEncodeEnd(enc *xml.Encoder, names ...string) {
+	for _, name := range names {
+		enc.EncodeToken(xml.EndElement{Name: xml.Name{Local: name}})
+	}
+}
+
+/*
+XMLEncodeValue encodes element with given name and text
+*/
+func XMLEncodeValue(enc *xml.Encoder, name string, text string) {
+	XMLEncodeStart(enc, name)
+	enc.EncodeToken(xml.CharData(text))
+	XMLEncodeEnd(enc, name)
+}
+
+/*
+FormatBool returns xmlrpc representation of bool
+*/
+func FormatBool(value bool) string {
+	if value {
+		return "1"
+	}
+	return "0"
+}
+
+/*
+XMLWriteEscaped writes text escaped for use in xml to buffer (*bytes.Buffer, *bufio.Writer...). Characters that
+are not allowed in xml are replaced with unicode replacement character.
+*/
+func XMLWriteEscaped(buf io.StringWriter, text string) {
+	last := 0
+	for i := 0; i < len(text); {
+		r, width := utf8.DecodeRuneInString(text[i:])
+
+		var escaped string
+		switch r {
+		case '&':
+			escaped = "&amp;"
+		case '<':
+			escaped = "&lt;"
+		case '>':
+			escaped = "&gt;"
+		case '"':
+			escaped = "&#34;"
+		case '\'':
+			escaped = "&#39;"
+		case '\r':
+			escaped = "&#xD;"
+		default:
+			if isXMLChar(r) && !(r == utf8.RuneError && width == 1) {
+				i += width
+				continue
+			}
+			escaped = "\uFFFD"
+		}
+
+		buf.WriteString(text[last:i])
+		buf.WriteString(escaped)
+		i += width
+		last = i
+	}
+	buf.WriteString(text[last:])
+}
+
+/*
+isXMLChar returns whether rune is allowed in xml document
+*/
+func isXMLChar(r rune) bool {
+	return r == 0x09 || r == 0x0A || r == 0x0D ||
+		r >= 0x20 && r <= 0xD7FF ||
+		r >= 0xE000 && r <= 0xFFFD ||
+		r >= 0x10000 && r <= 0x10FFFF
+}
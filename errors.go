@@ -1,12 +1,30 @@
 package xmlrpc
 
 import (
-	"errors"
 	"fmt"
+	"strings"
+)
+
+/*
+Fault codes defined by specification for fault code interoperability (faults_interop).
+Handler and generated code use them for their own failures, errors returned by service methods keep their codes
+(500 if they don't have any).
+*/
+const (
+	FaultParseError          = -32700
+	FaultUnsupportedEncoding = -32701
+	FaultInvalidCharacter    = -32702
+	FaultInvalidRequest      = -32600
+	FaultMethodNotFound      = -32601
+	FaultInvalidParams       = -32602
+	FaultInternalError       = -32603
+	FaultApplicationError    = -32500
+	FaultSystemError         = -32400
+	FaultTransportError      = -32300
 )
 
 var (
-	ErrMethodNotFound = errors.New("Method not found")
+	ErrMethodNotFound = Errorf(FaultMethodNotFound, "method not found")
 )
 
 type Error interface {
@@ -45,3 +63,61 @@ Code is additional param when returning from service methods. Otherwise xmlrpc r
 func (e err) Code() int {
 	return e.code
 }
+
+/*
+errorCode returns code of given error, if error is not xmlrpc.Error 500 is returned
+*/
+func errorCode(e error) int {
+	if xe, ok := e.(Error); ok {
+		return xe.Code()
+	}
+	return 500
+}
+
+/*
+parseError classifies error returned by xml parser
+*/
+func parseError(err error) Error {
+	message := err.Error()
+
+	switch {
+	case strings.Contains(message, "encoding"), strings.Contains(message, "charset"):
+		return Errorf(FaultUnsupportedEncoding, "parse error: unsupported encoding")
+	case strings.Contains(message, "illegal character"), strings.Contains(message, "invalid UTF-8"):
+		return Errorf(FaultInvalidCharacter, "parse error: invalid character for encoding")
+	}
+
+	return Errorf(FaultParseError, "parse error: not well formed")
+}
+
+/*
+FaultWithCode returns error with given fault code, original error is kept in chain (errors.Is and errors.As work)
+*/
+func FaultWithCode(e error, code int) Error {
+	return &codedError{
+		error: e,
+		code:  code,
+	}
+}
+
+/*
+codedError is error with fault code set by FaultWithCode
+*/
+type codedError struct {
+	error
+	code int
+}
+
+/*
+Code returns fault code
+*/
+func (c *codedError) Code() int {
+	return c.code
+}
+
+/*
+Unwrap returns original error
+*/
+func (c *codedError) Unwrap() error {
+	return c.error
+}
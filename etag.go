@@ -0,0 +1,193 @@
+package xmlrpc
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"path"
+	"strings"
+)
+
+/*
+WithETags sets ETag header of successful responses of methods that match patterns (path.Match syntax, e.g.
+"posts.Get*"), tag is hash of response or tag set by method by SetETag. Calls with If-None-Match header that
+matches tag get empty response with 304 status, polling clients (see NewETagTransport) then reuse response they
+already have. Methods must be read only, their response is computed anyway. Calls that already sent heartbeats
+(see WithHeartbeat) get full response without ETag.
+*/
+func WithETags(patterns ...string) HandlerOption {
+	return func(h *handler) {
+		h.etagPatterns = append(h.etagPatterns, patterns...)
+	}
+}
+
+/*
+SetETag sets tag of response of current call (e.g. version of returned data), it's used instead of hash of
+response. It has no effect for methods without ETags (see WithETags).
+*/
+func SetETag(ctx context.Context, tag string) {
+	if holder, ok := ctx.Value(etagContextKey).(*string); ok {
+		*holder = tag
+	}
+}
+
+/*
+etagged returns whether method has ETags
+*/
+func (h *handler) etagged(method string) bool {
+	for _, pattern := range h.etagPatterns {
+		if ok, _ := path.Match(pattern, method); ok {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+etag writes response to buffer, sets ETag header and returns whether If-None-Match header matches it
+*/
+func (h *handler) etag(ctx context.Context, w http.ResponseWriter, r *http.Request, res io.WriterTo) (io.WriterTo, bool) {
+	buf := &bytes.Buffer{}
+	if _, err := res.WriteTo(buf); err != nil {
+		return buf, false
+	}
+
+	tag, _ := ctx.Value(etagContextKey).(*string)
+	if tag == nil || *tag == "" {
+		sum := sha256.Sum256(buf.Bytes())
+		hash := hex.EncodeToString(sum[:16])
+		tag = &hash
+	}
+	etag := `"` + strings.Trim(*tag, `"`) + `"`
+	w.Header().Set("ETag", etag)
+
+	for _, candidate := range strings.Split(r.Header.Get("If-None-Match"), ",") {
+		if candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/"); candidate == etag || candidate == "*" {
+			return nil, true
+		}
+	}
+	return buf, false
+}
+
+const (
+	// DefaultETagCacheSize is count of responses kept by NewETagTransport by default
+	DefaultETagCacheSize = 1000
+)
+
+/*
+ETagTransportOption configures transport of NewETagTransport
+*/
+type ETagTransportOption func(e *etagTransport)
+
+/*
+WithETagCacheSize sets count of kept responses (DefaultETagCacheSize by default), least recently used are evicted
+*/
+func WithETagCacheSize(size int) ETagTransportOption {
+	return func(e *etagTransport) {
+		e.size = size
+	}
+}
+
+/*
+NewETagTransport returns http transport for clients, it keeps responses with ETag and sends their tag with same
+calls (same url and body). Response with 304 status is replaced by kept response, so clients see full response.
+At most DefaultETagCacheSize responses are kept (see WithETagCacheSize). It's safe for concurrent use. Nil base
+is http.DefaultTransport.
+*/
+func NewETagTransport(base http.RoundTripper, options ...ETagTransportOption) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	result := &etagTransport{base: base, size: DefaultETagCacheSize}
+	for _, option := range options {
+		option(result)
+	}
+	result.responses = newLRUCache(result.size)
+	return result
+}
+
+/*
+etagTransport keeps responses with ETag by url and hash of call
+*/
+type etagTransport struct {
+	base      http.RoundTripper
+	size      int
+	responses *lruCache
+}
+
+/*
+etagResponse is kept response
+*/
+type etagResponse struct {
+	etag   string
+	header http.Header
+	body   []byte
+}
+
+func (e *etagTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	if request.Method != "POST" || request.Body == nil {
+		return e.base.RoundTrip(request)
+	}
+
+	body, err := ioutil.ReadAll(request.Body)
+	request.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(body)
+	key := request.URL.String() + "\x00" + hex.EncodeToString(sum[:])
+
+	// request must not be modified, it's cloned
+	clone := request.WithContext(request.Context())
+	clone.Header = http.Header{}
+	for name, values := range request.Header {
+		clone.Header[name] = values
+	}
+	clone.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	var kept *etagResponse
+	value, ok := e.responses.get(key)
+	if ok {
+		kept = value.(*etagResponse)
+		clone.Header.Set("If-None-Match", kept.etag)
+	}
+
+	response, err := e.base.RoundTrip(clone)
+	if err != nil {
+		return nil, err
+	}
+
+	if response.StatusCode == http.StatusNotModified && ok {
+		response.Body.Close()
+		response.StatusCode = http.StatusOK
+		response.Status = "200 OK"
+		for name, values := range kept.header {
+			if response.Header.Get(name) == "" {
+				response.Header[name] = values
+			}
+		}
+		response.Body = ioutil.NopCloser(bytes.NewReader(kept.body))
+		response.ContentLength = int64(len(kept.body))
+		return response, nil
+	}
+
+	etag := response.Header.Get("ETag")
+	if response.StatusCode != http.StatusOK || etag == "" {
+		return response, nil
+	}
+
+	data, err := ioutil.ReadAll(response.Body)
+	response.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	response.Body = ioutil.NopCloser(bytes.NewReader(data))
+
+	e.responses.put(key, &etagResponse{etag: etag, header: response.Header, body: data})
+
+	return response, nil
+}
@@ -1,5 +1,4 @@
 package example
 
 type Config interface {
-
 }
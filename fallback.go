@@ -0,0 +1,37 @@
+package xmlrpc
+
+import (
+	"context"
+	"io"
+)
+
+/*
+MethodNotFoundHandler is called for methods that no registered service has (proxying, aliasing, shims of removed
+methods). Params element is checked by limits and call validators before, result is whole methodResponse
+document as returned by Dispatch. Returning ErrMethodNotFound keeps standard fault.
+*/
+type MethodNotFoundHandler func(ctx context.Context, method string, params *Element) (io.WriterTo, error)
+
+/*
+WithMethodNotFoundHandler sets handler of calls of unknown methods, -32601 fault is returned without it
+*/
+func WithMethodNotFoundHandler(fallback MethodNotFoundHandler) HandlerOption {
+	return func(h *handler) {
+		h.methodNotFound = fallback
+	}
+}
+
+/*
+Dispatch calls method of registered service with given params element, fallback handler is not called (so it can
+use Dispatch for aliases).
+*/
+func (h *handler) Dispatch(ctx context.Context, method string, params *Element) (io.WriterTo, error) {
+	service, serviceMethod := splitMethod(method)
+
+	s, ok := h.service(ctx, service)
+	if !ok || !s.MethodExists(serviceMethod) {
+		return nil, ErrMethodNotFound
+	}
+
+	return s.Dispatch(ctx, serviceMethod, params)
+}
@@ -0,0 +1,54 @@
+package xmlrpc
+
+import (
+	"context"
+	"net/http"
+)
+
+/*
+FaultStatusMapper returns http status for fault code, 0 means http.StatusOK
+*/
+type FaultStatusMapper func(code int) int
+
+/*
+FaultStatus returns middleware that changes http status of fault responses. By specification faults are sent
+with 200 OK (which is also default), but proxies and load balancers often key off status codes.
+FaultStatus should be outermost middleware so faults written by other middlewares are mapped too.
+*/
+func FaultStatus(mapper FaultStatusMapper) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), faultStatusContextKey, mapper)))
+		})
+	}
+}
+
+/*
+DefaultFaultStatus maps fault classes to http statuses:
+401, 403 and 429 are kept, malformed requests and invalid params are 400, unknown methods 404 and rest 500.
+*/
+func DefaultFaultStatus(code int) int {
+	switch code {
+	case http.StatusUnauthorized, http.StatusForbidden, http.StatusTooManyRequests:
+		return code
+	case FaultParseError, FaultUnsupportedEncoding, FaultInvalidCharacter, FaultInvalidRequest, FaultInvalidParams:
+		return http.StatusBadRequest
+	case FaultMethodNotFound:
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
+
+/*
+faultStatus returns http status for fault response by mapper stored in context
+*/
+func faultStatus(ctx context.Context, err error) int {
+	mapper, _ := ctx.Value(faultStatusContextKey).(FaultStatusMapper)
+	if mapper == nil {
+		return http.StatusOK
+	}
+	if status := mapper(errorCode(err)); status != 0 {
+		return status
+	}
+	return http.StatusOK
+}
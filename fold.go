@@ -0,0 +1,10 @@
+package xmlrpc
+
+import "strings"
+
+/*
+FoldMemberName returns name of struct member as matched by gen.WithFoldMembers
+*/
+func FoldMemberName(name string) string {
+	return strings.ToLower(strings.NewReplacer("_", "", "-", "").Replace(name))
+}
@@ -0,0 +1,178 @@
+package xmlrpc
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"path"
+
+	"github.com/beevik/etree"
+)
+
+/*
+ForwarderOption configures forwarder
+*/
+type ForwarderOption func(*forwarder)
+
+/*
+WithForwardClient sets http client used for upstream calls (http.DefaultClient by default)
+*/
+func WithForwardClient(client *http.Client) ForwarderOption {
+	return func(f *forwarder) {
+		f.client = client
+	}
+}
+
+/*
+WithForwardRename rewrites names of forwarded methods (e.g. adds upstream namespace)
+*/
+func WithForwardRename(rename func(method string) string) ForwarderOption {
+	return func(f *forwarder) {
+		f.rename = rename
+	}
+}
+
+/*
+WithForwardRequest modifies upstream requests (e.g. injects authentication), error rejects the call
+*/
+func WithForwardRequest(modify func(ctx context.Context, method string, r *http.Request) error) ForwarderOption {
+	return func(f *forwarder) {
+		f.modifiers = append(f.modifiers, modify)
+	}
+}
+
+/*
+WithForwardBasicAuth injects basic authentication to upstream requests
+*/
+func WithForwardBasicAuth(username, password string) ForwarderOption {
+	return WithForwardRequest(func(ctx context.Context, method string, r *http.Request) error {
+		r.SetBasicAuth(username, password)
+		return nil
+	})
+}
+
+/*
+WithForwardMethods forwards only methods that match one of given patterns (path.Match syntax, e.g. "posts.*"),
+other methods are not found. All methods are forwarded by default.
+*/
+func WithForwardMethods(patterns ...string) ForwarderOption {
+	return func(f *forwarder) {
+		f.patterns = append(f.patterns, patterns...)
+	}
+}
+
+/*
+NewForwarder returns MethodNotFoundHandler that forwards calls to upstream xmlrpc endpoint. Use it with
+WithMethodNotFoundHandler to forward unknown methods, handler without services forwards all methods.
+Upstream faults are returned to client as they are, transport failures are transport error faults.
+*/
+func NewForwarder(upstream string, options ...ForwarderOption) MethodNotFoundHandler {
+	f := &forwarder{
+		upstream: upstream,
+		client:   http.DefaultClient,
+	}
+
+	for _, option := range options {
+		option(f)
+	}
+
+	return f.forward
+}
+
+/*
+forwarder forwards calls to upstream
+*/
+type forwarder struct {
+	upstream  string
+	client    *http.Client
+	rename    func(method string) string
+	modifiers []func(ctx context.Context, method string, r *http.Request) error
+	patterns  []string
+
+	// transformers of params and results, see transform.go
+	paramsTransformers []paramsTransformer
+	resultTransformers []resultTransformer
+}
+
+/*
+allowed returns whether method is forwarded
+*/
+func (f *forwarder) allowed(method string) bool {
+	if len(f.patterns) == 0 {
+		return true
+	}
+	for _, pattern := range f.patterns {
+		if ok, _ := path.Match(pattern, method); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *forwarder) forward(ctx context.Context, method string, params *etree.Element) (res io.WriterTo, err error) {
+	if !f.allowed(method) {
+		return nil, ErrMethodNotFound
+	}
+
+	if params, err = f.transformParams(ctx, method, params); err != nil {
+		return
+	}
+
+	upstreamMethod := method
+	if f.rename != nil {
+		upstreamMethod = f.rename(method)
+	}
+
+	// methodCall with same params
+	call := newResponseDocument()
+	root := call.CreateElement("methodCall")
+	root.CreateElement("methodName").SetText(upstreamMethod)
+	root.AddChild(params.Copy())
+
+	var body []byte
+	if body, err = call.WriteToBytes(); err != nil {
+		return
+	}
+
+	var request *http.Request
+	if request, err = http.NewRequest("POST", f.upstream, bytes.NewReader(body)); err != nil {
+		return
+	}
+	request = request.WithContext(ctx)
+	request.Header.Set("Content-Type", "text/xml")
+	// upstream adopts remaining time of call
+	SetTimeoutHeader(ctx, request)
+
+	for _, modify := range f.modifiers {
+		if err = modify(ctx, method, request); err != nil {
+			return
+		}
+	}
+
+	var response *http.Response
+	// error is not passed to client, it would reveal upstream address
+	if response, err = f.client.Do(request); err != nil {
+		return nil, Errorf(FaultTransportError, "upstream call failed")
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, Errorf(FaultTransportError, "upstream returned %v", response.Status)
+	}
+
+	// response is parsed, so only valid methodResponse is returned
+	doc := etree.NewDocument()
+	if _, err = doc.ReadFrom(response.Body); err != nil {
+		return nil, Errorf(FaultTransportError, "invalid upstream response")
+	}
+	if doc.Root() == nil || doc.Root().Tag != "methodResponse" {
+		return nil, Errorf(FaultTransportError, "invalid upstream response: methodResponse not found")
+	}
+
+	if err = f.transformResult(ctx, method, doc); err != nil {
+		return
+	}
+
+	return doc, nil
+}
@@ -0,0 +1,12 @@
+package gen
+
+/*
+WithApacheExtensions writes 64 bit integers as <ex:i8>, time.Time as <ex:dateTime> and nil dynamic values as
+<ex:nil/> as Apache ws-xmlrpc library does (Java peers need enabledForExtensions). Every extension element declares
+its namespace. Extensions are always accepted when decoding, regardless of this option.
+*/
+func WithApacheExtensions() GeneratorOption {
+	return func(g *generator) {
+		g.paramOptions.apacheExtensions = true
+	}
+}
@@ -0,0 +1,277 @@
+package gen
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+/*
+backend is set of templates that write response. Request is always decoded with etree, backends differ only in
+how response is written. Every template receives:
+
+	Element - variable where value is written (xmlrpc.Node for etree backend, *xml.Encoder for xml backend,
+		*bytes.Buffer for writer backend)
+	ResultVar - variable with value
+	ErrorVar - error variable
+
+Generated code can use "ctx" variable (context of call), loops should stop when it's cancelled.
+
+"scalar" template receives also Tag (xmlrpc type) and Text (go expression that returns string),
+"struct" and "tuple" templates receive Params (Name is name of member, Field is name of go struct field),
+"slice" template receives Object (Param of slice item), "raw" template writes xmlrpc.RawValue in ResultVar verbatim
+and "response" template receives Result (Param, can be nil).
+Response template must assign io.WriterTo to "res" variable.
+*/
+type backend struct {
+	// imports needed by generated code
+	imports []string
+
+	// templates by kind
+	templates map[string]string
+}
+
+/*
+render renders template of given kind
+*/
+func (b *backend) render(names *VariableNames, kind string, data map[string]interface{}) string {
+	buf := bytes.Buffer{}
+	names.renderInto(&buf, b.templates[kind], data)
+	return buf.String()
+}
+
+/*
+scalar renders scalar value with given xmlrpc type and text expression
+*/
+func (b *backend) scalar(names *VariableNames, element, tag, text string) string {
+	return b.render(names, "scalar", map[string]interface{}{
+		"Element": element,
+		"Tag":     tag,
+		"Text":    text,
+	})
+}
+
+/*
+getBackend returns backend by name
+*/
+func getBackend(name string) (*backend, error) {
+	if b, ok := backends[name]; ok {
+		return b, nil
+	}
+
+	names := make([]string, 0, len(backends))
+	for key := range backends {
+		names = append(names, key)
+	}
+	sort.Strings(names)
+
+	return nil, fmt.Errorf("unknown backend %v, available backends: %v", name, strings.Join(names, ", "))
+}
+
+const (
+	// DefaultBackend is backend used when no other is set
+	DefaultBackend = "etree"
+)
+
+var (
+	backends = map[string]*backend{
+		// etree builds xmlrpc.Document (etree document unless runtime has other DOM, see xmlrpc.SetDocumentFactory)
+		"etree": {
+			templates: map[string]string{
+				"response": `
+					{{$doc := GenerateVariableName "doc"}}
+					{{$doc}} := xmlrpc.NewDocument()
+					{{if .Result }}
+						// here is place where we need to hydrate results {{$tempParam := GenerateVariableName}}
+						{{$tempParam}} := {{$doc}}.CreateElement("methodResponse").CreateElement("params").CreateElement("param").CreateElement("value")
+						{{.Result.ToEtree names $tempParam .ResultVar .ErrorVar }}
+					{{else}}
+						{{$doc}}.CreateElement("methodResponse")
+					{{end}}
+					res = {{$doc}}
+				`,
+				"scalar": `{{.Element}}.CreateElement("{{.Tag}}").SetText({{.Text}})`,
+				"raw": `
+					if {{.ErrorVar}} = xmlrpc.XMLWriteNode({{.Element}}, {{.ResultVar}}); {{.ErrorVar}} != nil {
+						return
+					}
+				`,
+				"struct": `
+					{{$struct := GenerateVariableName "struct"}}
+					{{$struct}} := {{.Element}}.CreateElement("struct")
+					// iterate over struct members
+					{{range .Params}}
+						{{$MemberVar:= GenerateVariableName "member"}}
+						{{$MemberVar}} := {{$struct}}.CreateElement("member")
+
+						// first create "name" xml element with member name
+						{{$MemberVar}}.CreateElement("name").SetText("{{.Name}}")
+
+						{{$TempValueVar := GenerateVariableName "value"}}
+						{{$TempValueVar}} := {{$MemberVar}}.CreateElement("value")
+
+						// make shortcut to struct member {{$StructItemVar := GenerateVariableName "struct_var"}}
+						{{$StructItemVar}} := {{$.ResultVar}}.{{.Field}}
+
+						// set value
+						{{.ToEtree names $TempValueVar $StructItemVar $.ErrorVar }}
+					{{end}}
+				`,
+				"tuple": `
+					{{$data := GenerateVariableName "array_data"}}
+					{{$data}} := {{.Element}}.CreateElement("array").CreateElement("data")
+					{{range .Params}}
+						{{$value := GenerateVariableName "value"}}
+						{{$value}} := {{$data}}.CreateElement("value")
+						{{$item := GenerateVariableName "tuple_item"}}
+						{{$item}} := {{$.ResultVar}}.{{.Field}}
+						{{.ToEtree names $value $item $.ErrorVar }}
+					{{end}}
+				`,
+				"slice": `
+					{{$data := GenerateVariableName "array_data"}}
+					{{$item := GenerateVariableName "item"}}
+					{{$value := GenerateVariableName "value"}}
+					{{$data}} := {{.Element}}.CreateElement("array").CreateElement("data")
+					for _, {{$item}} := range {{.ResultVar}} {
+						if {{.ErrorVar}} = ctx.Err(); {{.ErrorVar}} != nil {
+							return
+						}
+						{{$value}} := {{$data}}.CreateElement("value")
+						{{.Object.ToEtree names $value $item .ErrorVar }}
+					}
+				`,
+			},
+		},
+
+		// xml writes response with encoding/xml encoder, no document is built
+		"xml": {
+			imports: []string{"bytes", "encoding/xml"},
+			templates: map[string]string{
+				"response": `
+					{{$buf := GenerateVariableName "buf"}}
+					{{$enc := GenerateVariableName "encoder"}}
+					{{$buf}} := &bytes.Buffer{}
+					{{$buf}}.WriteString(xml.Header)
+					{{$enc}} := xml.NewEncoder({{$buf}})
+					{{if .Result }}
+						xmlrpc.XMLEncodeStart({{$enc}}, "methodResponse", "params", "param", "value")
+						{{.Result.ToEtree names $enc .ResultVar .ErrorVar }}
+						xmlrpc.XMLEncodeEnd({{$enc}}, "value", "param", "params", "methodResponse")
+					{{else}}
+						xmlrpc.XMLEncodeStart({{$enc}}, "methodResponse")
+						xmlrpc.XMLEncodeEnd({{$enc}}, "methodResponse")
+					{{end}}
+					if {{.ErrorVar}} = {{$enc}}.Flush(); {{.ErrorVar}} != nil {
+						return
+					}
+					res = {{$buf}}
+				`,
+				"scalar": `xmlrpc.XMLEncodeValue({{.Element}}, "{{.Tag}}", {{.Text}})`,
+				"raw": `
+					if {{.ErrorVar}} = xmlrpc.XMLEncodeRaw({{.Element}}, {{.ResultVar}}); {{.ErrorVar}} != nil {
+						return
+					}
+				`,
+				"struct": `
+					xmlrpc.XMLEncodeStart({{.Element}}, "struct")
+					{{range .Params}}
+						xmlrpc.XMLEncodeStart({{$.Element}}, "member")
+						xmlrpc.XMLEncodeValue({{$.Element}}, "name", "{{.Name}}")
+						xmlrpc.XMLEncodeStart({{$.Element}}, "value")
+						{{$StructItemVar := GenerateVariableName "struct_var"}}
+						{{$StructItemVar}} := {{$.ResultVar}}.{{.Field}}
+						{{.ToEtree names $.Element $StructItemVar $.ErrorVar }}
+						xmlrpc.XMLEncodeEnd({{$.Element}}, "value", "member")
+					{{end}}
+					xmlrpc.XMLEncodeEnd({{.Element}}, "struct")
+				`,
+				"tuple": `
+					xmlrpc.XMLEncodeStart({{.Element}}, "array", "data")
+					{{range .Params}}
+						xmlrpc.XMLEncodeStart({{$.Element}}, "value")
+						{{$item := GenerateVariableName "tuple_item"}}
+						{{$item}} := {{$.ResultVar}}.{{.Field}}
+						{{.ToEtree names $.Element $item $.ErrorVar }}
+						xmlrpc.XMLEncodeEnd({{$.Element}}, "value")
+					{{end}}
+					xmlrpc.XMLEncodeEnd({{.Element}}, "data", "array")
+				`,
+				"slice": `
+					{{$item := GenerateVariableName "item"}}
+					xmlrpc.XMLEncodeStart({{.Element}}, "array", "data")
+					for _, {{$item}} := range {{.ResultVar}} {
+						if {{.ErrorVar}} = ctx.Err(); {{.ErrorVar}} != nil {
+							return
+						}
+						xmlrpc.XMLEncodeStart({{.Element}}, "value")
+						{{.Object.ToEtree names .Element $item .ErrorVar }}
+						xmlrpc.XMLEncodeEnd({{.Element}}, "value")
+					}
+					xmlrpc.XMLEncodeEnd({{.Element}}, "data", "array")
+				`,
+			},
+		},
+
+		// writer writes escaped xml directly to *bytes.Buffer, neither document nor tokens are built
+		"writer": {
+			imports: []string{"bytes"},
+			templates: map[string]string{
+				"response": `
+					{{$buf := GenerateVariableName "buf"}}
+					{{$buf}} := &bytes.Buffer{}
+					{{if .Result }}
+						{{$buf}}.WriteString("<?xml version=\"1.0\" encoding=\"UTF-8\"?><methodResponse><params><param><value>")
+						{{.Result.ToEtree names $buf .ResultVar .ErrorVar }}
+						{{$buf}}.WriteString("</value></param></params></methodResponse>")
+					{{else}}
+						{{$buf}}.WriteString("<?xml version=\"1.0\" encoding=\"UTF-8\"?><methodResponse></methodResponse>")
+					{{end}}
+					res = {{$buf}}
+				`,
+				"raw": `{{.Element}}.Write({{.ResultVar}})`,
+				"scalar": `
+					{{.Element}}.WriteString("<{{.Tag}}>")
+					xmlrpc.XMLWriteEscaped({{.Element}}, {{.Text}})
+					{{.Element}}.WriteString("</{{.Tag}}>")
+				`,
+				"struct": `
+					{{.Element}}.WriteString("<struct>")
+					{{range .Params}}
+						{{$.Element}}.WriteString("<member><name>{{.Name}}</name><value>")
+						{{$StructItemVar := GenerateVariableName "struct_var"}}
+						{{$StructItemVar}} := {{$.ResultVar}}.{{.Field}}
+						{{.ToEtree names $.Element $StructItemVar $.ErrorVar }}
+						{{$.Element}}.WriteString("</value></member>")
+					{{end}}
+					{{.Element}}.WriteString("</struct>")
+				`,
+				"tuple": `
+					{{.Element}}.WriteString("<array><data>")
+					{{range .Params}}
+						{{$.Element}}.WriteString("<value>")
+						{{$item := GenerateVariableName "tuple_item"}}
+						{{$item}} := {{$.ResultVar}}.{{.Field}}
+						{{.ToEtree names $.Element $item $.ErrorVar }}
+						{{$.Element}}.WriteString("</value>")
+					{{end}}
+					{{.Element}}.WriteString("</data></array>")
+				`,
+				"slice": `
+					{{$item := GenerateVariableName "item"}}
+					{{.Element}}.WriteString("<array><data>")
+					for _, {{$item}} := range {{.ResultVar}} {
+						if {{.ErrorVar}} = ctx.Err(); {{.ErrorVar}} != nil {
+							return
+						}
+						{{.Element}}.WriteString("<value>")
+						{{.Object.ToEtree names .Element $item .ErrorVar }}
+						{{.Element}}.WriteString("</value>")
+					}
+					{{.Element}}.WriteString("</data></array>")
+				`,
+			},
+		},
+	}
+)
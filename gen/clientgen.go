@@ -0,0 +1,467 @@
+package gen
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var (
+	// tsReserved are words that cannot be used as names of params in TypeScript
+	tsReserved = map[string]bool{
+		"break": true, "case": true, "catch": true, "class": true, "const": true, "continue": true,
+		"debugger": true, "default": true, "delete": true, "do": true, "else": true, "enum": true,
+		"export": true, "extends": true, "false": true, "finally": true, "for": true, "function": true,
+		"if": true, "import": true, "in": true, "instanceof": true, "let": true, "new": true, "null": true,
+		"return": true, "super": true, "switch": true, "this": true, "throw": true, "true": true, "try": true,
+		"typeof": true, "var": true, "void": true, "while": true, "with": true, "yield": true,
+	}
+
+	// pyReserved are words that cannot be used as names of params in Python
+	pyReserved = map[string]bool{
+		"False": true, "None": true, "True": true, "and": true, "as": true, "assert": true, "async": true,
+		"await": true, "break": true, "class": true, "continue": true, "def": true, "del": true, "elif": true,
+		"else": true, "except": true, "finally": true, "for": true, "from": true, "global": true, "if": true,
+		"import": true, "in": true, "is": true, "lambda": true, "nonlocal": true, "not": true, "or": true,
+		"pass": true, "raise": true, "return": true, "self": true, "try": true, "while": true, "with": true,
+		"yield": true,
+	}
+)
+
+/*
+clientParam is param of method in generated client
+*/
+type clientParam struct {
+	// Name is name of param safe in target language
+	Name string
+
+	Param Param
+}
+
+/*
+clientParams returns params of method with names that are safe in language with given reserved words
+*/
+func clientParams(method *rpcMethod, reserved map[string]bool) []clientParam {
+	result := make([]clientParam, 0, len(method.Params))
+	for i, param := range method.Params {
+		name := param.Name()
+		if name == "" || name == "_" {
+			name = fmt.Sprintf("p%d", i)
+		}
+		if reserved[name] {
+			name += "_"
+		}
+		result = append(result, clientParam{Name: name, Param: param})
+	}
+	return result
+}
+
+/*
+tsParams returns params of method in generated TypeScript client
+*/
+func tsParams(method *rpcMethod) []clientParam { return clientParams(method, tsReserved) }
+
+/*
+pyParams returns params of method in generated Python client
+*/
+func pyParams(method *rpcMethod) []clientParam { return clientParams(method, pyReserved) }
+
+/*
+tsType returns TypeScript type of param
+*/
+func tsType(p Param) string {
+	switch param := p.(type) {
+	case *boolParam:
+		return "boolean"
+	case *intParam, *floatParam:
+		return "number"
+	case *timeParam:
+		return "Date"
+	case *bytesParam, *readerParam:
+		return "Uint8Array"
+	case *structParam:
+		members := make([]string, 0, len(param.params))
+		for _, field := range param.params {
+			members = append(members, strconv.Quote(field.Name())+": "+tsType(field.Param))
+		}
+		return "{ " + strings.Join(members, "; ") + " }"
+	case *sliceParam:
+		return "Array<" + tsType(param.object) + ">"
+	case *streamParam:
+		return "Array<" + tsType(param.object) + ">"
+	case *tupleParam:
+		items := make([]string, 0, len(param.params))
+		for _, field := range param.params {
+			items = append(items, tsType(field.Param))
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	case *unionParam:
+		variants := make([]string, 0, len(param.variants))
+		for _, variant := range param.variants {
+			variants = append(variants, tsType(variant.Param))
+		}
+		return strings.Join(variants, " | ")
+	case *rawParam, *valueParam, *codecParam, *lazyStructParam:
+		return "unknown"
+	default:
+		return "string"
+	}
+}
+
+/*
+tsSchema returns TypeScript literal of schema that encodes value of param (numbers are ints or doubles by
+schema, unions and dynamic values are encoded by their runtime type)
+*/
+func tsSchema(p Param) string {
+	switch param := p.(type) {
+	case *structParam:
+		members := make([]string, 0, len(param.params))
+		for _, field := range param.params {
+			members = append(members, strconv.Quote(field.Name())+": "+tsSchema(field.Param))
+		}
+		return "{ struct: { " + strings.Join(members, ", ") + " } }"
+	case *sliceParam:
+		return "{ array: " + tsSchema(param.object) + " }"
+	case *tupleParam:
+		items := make([]string, 0, len(param.params))
+		for _, field := range param.params {
+			items = append(items, tsSchema(field.Param))
+		}
+		return "{ tuple: [" + strings.Join(items, ", ") + "] }"
+	case *unionParam:
+		return strconv.Quote("undef")
+	}
+	return strconv.Quote(wireType(p))
+}
+
+/*
+pyType returns Python type hint of param
+*/
+func pyType(p Param) string {
+	switch param := p.(type) {
+	case *boolParam:
+		return "bool"
+	case *intParam:
+		return "int"
+	case *floatParam:
+		return "float"
+	case *timeParam:
+		return "datetime.datetime"
+	case *bytesParam, *readerParam:
+		return "bytes"
+	case *structParam:
+		return "Dict[str, Any]"
+	case *sliceParam:
+		return "List[" + pyType(param.object) + "]"
+	case *streamParam:
+		return "List[" + pyType(param.object) + "]"
+	case *tupleParam:
+		items := make([]string, 0, len(param.params))
+		for _, field := range param.params {
+			items = append(items, pyType(field.Param))
+		}
+		return "Tuple[" + strings.Join(items, ", ") + "]"
+	case *unionParam:
+		variants := make([]string, 0, len(param.variants))
+		for _, variant := range param.variants {
+			variants = append(variants, pyType(variant.Param))
+		}
+		return "Union[" + strings.Join(variants, ", ") + "]"
+	case *rawParam, *valueParam, *codecParam, *lazyStructParam:
+		return "Any"
+	default:
+		return "str"
+	}
+}
+
+/*
+tsDoc returns doc comment as JSDoc comment of class member
+*/
+func tsDoc(doc string) string {
+	buf := bytes.Buffer{}
+	buf.WriteString("\t/**\n")
+	for _, line := range strings.Split(strings.Replace(doc, "*/", "*\\/", -1), "\n") {
+		buf.WriteString(strings.TrimRight("\t * "+line, " ") + "\n")
+	}
+	buf.WriteString("\t */\n")
+	return buf.String()
+}
+
+/*
+clientDoc returns doc comment of client method, it's doc comment of service method followed by sample request
+*/
+func clientDoc(namespace string, method *rpcMethod) string {
+	doc := strings.TrimSpace(method.Doc)
+	if doc != "" {
+		doc += "\n\n"
+	}
+	return doc + "Sample request:\n\n" + sampleRequest(namespace, method)
+}
+
+/*
+pyDoc returns doc comment as docstring of method
+*/
+func pyDoc(doc string) string {
+	doc = strings.Replace(strings.Replace(doc, `\`, `\\`, -1), `"""`, `\"""`, -1)
+
+	buf := bytes.Buffer{}
+	buf.WriteString(`        """`)
+	for i, line := range strings.Split(doc, "\n") {
+		if i > 0 {
+			buf.WriteString(strings.TrimRight("\n        "+line, " "))
+		} else {
+			buf.WriteString(line)
+		}
+	}
+	buf.WriteString("\n        \"\"\"\n")
+	return buf.String()
+}
+
+/*
+FormatTypeScript returns TypeScript source of clients of services (typed methods that call server with fetch),
+values are decoded with DOMParser (browsers, polyfill in node)
+*/
+func (g *generator) FormatTypeScript() []byte {
+	g.buf.Reset()
+
+	g.writeHeader()
+
+	g.WriteTemplate(`
+export type Schema = string | { struct: { [name: string]: Schema } } | { array: Schema } | { tuple: Schema[] }
+
+/**
+ * Fault is error returned by server
+ */
+export class Fault extends Error {
+	constructor(readonly code: number, message: string) {
+		super(message)
+	}
+}
+
+function escape(text: string): string {
+	return text.replace(/&/g, "&amp;").replace(/</g, "&lt;").replace(/>/g, "&gt;")
+}
+
+function encodeBase64(data: Uint8Array): string {
+	let binary = ""
+	data.forEach(byte => binary += String.fromCharCode(byte))
+	return btoa(binary)
+}
+
+function decodeBase64(text: string): Uint8Array {
+	const binary = atob(text.replace(/\s/g, ""))
+	const result = new Uint8Array(binary.length)
+	for (let i = 0; i < binary.length; i++) {
+		result[i] = binary.charCodeAt(i)
+	}
+	return result
+}
+
+export function encodeValue(value: unknown, schema: Schema): string {
+	if (typeof schema === "object") {
+		if ("struct" in schema) {
+			const members = Object.keys(schema.struct).filter(name => (value as any)[name] !== undefined)
+			return "<struct>" + members.map(name => "<member><name>" + escape(name) + "</name><value>" +
+				encodeValue((value as any)[name], schema.struct[name]) + "</value></member>").join("") + "</struct>"
+		}
+		if ("array" in schema) {
+			return "<array><data>" + (value as unknown[]).map(item => "<value>" + encodeValue(item, schema.array) +
+				"</value>").join("") + "</data></array>"
+		}
+		return "<array><data>" + schema.tuple.map((item, i) => "<value>" + encodeValue((value as unknown[])[i], item) +
+			"</value>").join("") + "</data></array>"
+	}
+
+	switch (schema) {
+	case "int":
+		return "<int>" + Math.trunc(value as number) + "</int>"
+	case "double":
+		return "<double>" + value + "</double>"
+	case "boolean":
+		return "<boolean>" + (value ? 1 : 0) + "</boolean>"
+	case "string":
+		return "<string>" + escape(String(value)) + "</string>"
+	case "dateTime.iso8601":
+		return "<dateTime.iso8601>" + (value as Date).toISOString().replace(/\.\d+Z$/, "Z") + "</dateTime.iso8601>"
+	case "base64":
+		return "<base64>" + encodeBase64(value as Uint8Array) + "</base64>"
+	}
+
+	// values without schema are encoded by their runtime type
+	if (value === null || value === undefined) {
+		return "<nil/>"
+	}
+	if (typeof value === "number") {
+		return encodeValue(value, Number.isInteger(value) ? "int" : "double")
+	}
+	if (typeof value === "boolean") {
+		return encodeValue(value, "boolean")
+	}
+	if (value instanceof Date) {
+		return encodeValue(value, "dateTime.iso8601")
+	}
+	if (value instanceof Uint8Array) {
+		return encodeValue(value, "base64")
+	}
+	if (Array.isArray(value)) {
+		return encodeValue(value, { array: "undef" })
+	}
+	if (typeof value === "object") {
+		const struct: { [name: string]: Schema } = {}
+		Object.keys(value as object).forEach(name => struct[name] = "undef")
+		return encodeValue(value, { struct })
+	}
+	return encodeValue(value, "string")
+}
+
+function children(element: Element, name?: string): Element[] {
+	return Array.from(element.childNodes).filter(
+		node => node.nodeType === 1 && (!name || (node as Element).localName === name)) as Element[]
+}
+
+function decodeDateTime(text: string): Date | string {
+	const match = /^(\d{4})-?(\d{2})-?(\d{2})T(\d{2}):(\d{2}):(\d{2})(\.\d+)?(Z|[+-]\d{2}:?\d{2})?$/.exec(text.trim())
+	if (!match) {
+		return text
+	}
+	// values without zone are UTC
+	let zone = match[8] || "Z"
+	if (zone.length === 5) {
+		zone = zone.slice(0, 3) + ":" + zone.slice(3)
+	}
+	return new Date(match[1] + "-" + match[2] + "-" + match[3] + "T" + match[4] + ":" + match[5] + ":" + match[6] +
+		(match[7] || "") + zone)
+}
+
+export function decodeValue(element: Element): unknown {
+	const typed = children(element)[0]
+	if (!typed) {
+		return element.textContent || ""
+	}
+
+	const text = typed.textContent || ""
+	switch (typed.localName) {
+	case "int":
+	case "i4":
+	case "i8":
+	case "i2":
+	case "i1":
+		return parseInt(text, 10)
+	case "double":
+		return parseFloat(text)
+	case "boolean":
+		return text.trim() === "1"
+	case "dateTime.iso8601":
+	case "dateTime":
+		return decodeDateTime(text)
+	case "base64":
+		return decodeBase64(text)
+	case "nil":
+		return null
+	case "struct": {
+		const result: { [name: string]: unknown } = {}
+		children(typed, "member").forEach(member => {
+			const name = children(member, "name")[0]
+			const value = children(member, "value")[0]
+			if (name && value) {
+				result[name.textContent || ""] = decodeValue(value)
+			}
+		})
+		return result
+	}
+	case "array": {
+		const data = children(typed, "data")[0]
+		return data ? children(data, "value").map(decodeValue) : []
+	}
+	}
+	return text
+}
+
+/**
+ * call calls method with encoded params, returns decoded result or throws Fault
+ */
+export async function call(endpoint: string, method: string, params: string[], init: RequestInit = {}): Promise<unknown> {
+	const headers = new Headers(init.headers)
+	headers.set("Content-Type", "text/xml")
+
+	const response = await fetch(endpoint, {
+		...init,
+		method: "POST",
+		headers,
+		body: "<?xml version=\"1.0\" encoding=\"UTF-8\"?><methodCall><methodName>" + escape(method) + "</methodName><params>" +
+			params.map(param => "<param><value>" + param + "</value></param>").join("") + "</params></methodCall>",
+	})
+
+	const document = new DOMParser().parseFromString(await response.text(), "text/xml")
+	const fault = document.getElementsByTagName("fault")[0]
+	if (fault) {
+		const value = decodeValue(children(fault, "value")[0]) as { faultCode?: number, faultString?: string }
+		throw new Fault(Number(value.faultCode), String(value.faultString))
+	}
+	if (!response.ok) {
+		throw new Fault(response.status, response.statusText)
+	}
+
+	const param = document.getElementsByTagName("param")[0]
+	return param ? decodeValue(children(param, "value")[0]) : undefined
+}
+{{range $service, $methods := .Services}}
+/**
+ * {{$service}}Client calls methods of {{$service}} registered as "{{namespace $service}}"
+ */
+export class {{$service}}Client {
+	constructor(readonly endpoint: string, readonly namespace: string = "{{namespace $service}}", readonly init: RequestInit = {}) {
+	}
+
+	private method(name: string): string {
+		return this.namespace ? this.namespace + "." + name : name
+	}
+{{range $methods}}{{$params := tsParams .}}
+{{tsDoc (clientDoc (namespace $service) .)}}	async {{.Method}}({{range $i, $p := $params}}{{if $i}}, {{end}}{{$p.Name}}: {{tsType $p.Param}}{{end}}): Promise<{{if .Result}}{{tsType .Result}}{{else}}void{{end}}> {
+		{{if .Result}}return (await {{else}}await {{end}}call(this.endpoint, this.method("{{.Method}}"), [{{range $i, $p := $params}}{{if $i}}, {{end}}encodeValue({{$p.Name}}, {{tsSchema $p.Param}}){{end}}], this.init){{if .Result}}) as {{tsType .Result}}{{end}}
+	}
+{{end}}}
+{{end}}`, map[string]interface{}{
+		"Services": g.services,
+	})
+
+	return g.buf.Bytes()
+}
+
+/*
+FormatPython returns Python source of clients of services (typed wrappers of xmlrpc.client.ServerProxy)
+*/
+func (g *generator) FormatPython() []byte {
+	g.buf.Reset()
+
+	g.WriteTemplate(`# This file is autogenerated by xmlrpcgen
+# do not change it directly!
+
+import datetime
+import xmlrpc.client
+from typing import Any, Dict, List, Tuple, Union
+{{range $service, $methods := .Services}}
+
+class {{$service}}Client:
+    """{{$service}}Client calls methods of {{$service}} registered as "{{namespace $service}}"."""
+
+    def __init__(self, url: str, namespace: str = "{{namespace $service}}", **kwargs: Any) -> None:
+        kwargs.setdefault("use_builtin_types", True)
+        kwargs.setdefault("allow_none", True)
+        self._proxy = xmlrpc.client.ServerProxy(url, **kwargs)
+        self._namespace = namespace
+
+    def _call(self, method: str, *params: Any) -> Any:
+        if self._namespace:
+            method = self._namespace + "." + method
+        return getattr(self._proxy, method)(*params)
+{{range $methods}}{{$params := pyParams .}}
+    def {{.Method}}(self{{range $params}}, {{.Name}}: {{pyType .Param}}{{end}}) -> {{if .Result}}{{pyType .Result}}{{else}}None{{end}}:
+{{pyDoc (clientDoc (namespace $service) .)}}        {{if .Result}}return {{end}}self._call("{{.Method}}"{{range $params}}, {{.Name}}{{end}})
+{{end}}{{end}}`, map[string]interface{}{
+		"Services": g.services,
+	})
+
+	return g.buf.Bytes()
+}
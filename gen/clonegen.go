@@ -0,0 +1,293 @@
+package gen
+
+import (
+	"bytes"
+	"go/types"
+	"strings"
+)
+
+/*
+cloneFuncs returns code of clone<Service><Method>Result and equal<Service><Method>Result functions, so tests and
+caching layers can copy and compare results without reflect.DeepEqual surprises. Clone copies slices, maps and
+pointers, Equal compares time.Time values as instants (by Equal methods), big numbers by Cmp and nil slices and maps
+as empty ones (they're the same on the wire). Empty string is returned for methods without result and for results
+that cannot be copied (streams, readers, funcs, interfaces, recursive types).
+*/
+func (g *generator) cloneFuncs(service string, method *rpcMethod) string {
+	if method.Signature.Results().Len() != 2 {
+		return ""
+	}
+	typ := method.Signature.Results().At(0).Type()
+
+	clone := &cloneWriter{pkg: g.pkg, names: newVariableNames(), visiting: map[types.Type]bool{}}
+	cloneCode, ok := clone.clone(typ, "src", "dst")
+	if !ok {
+		return ""
+	}
+	equal := &cloneWriter{pkg: g.pkg, names: newVariableNames(), visiting: map[types.Type]bool{}}
+	equalCode, ok := equal.equal(typ, "a", "b")
+	if !ok {
+		return ""
+	}
+
+	buf := bytes.Buffer{}
+	RenderTemplateInto(&buf, `
+	/*
+	clone{{.Name}}Result returns deep copy of result of {{.Service}}.{{.Method}}
+	*/
+	func clone{{.Name}}Result(src {{.Type}}) (dst {{.Type}}) {
+		dst = src
+		{{.Clone}}
+		return
+	}
+
+	/*
+	equal{{.Name}}Result returns whether results of {{.Service}}.{{.Method}} are equal
+	*/
+	func equal{{.Name}}Result(a, b {{.Type}}) bool {
+		{{.Equal}}
+		return true
+	}
+	`, map[string]interface{}{
+		"Name":    service + method.Method,
+		"Service": service,
+		"Method":  method.Method,
+		"Type":    typeString(typ, g.pkg),
+		"Clone":   cloneCode,
+		"Equal":   equalCode,
+	})
+	return buf.String()
+}
+
+const (
+	// runtimePath is import path of runtime package used by generated code
+	runtimePath = "github.com/phonkee/go-xmlrpc"
+)
+
+/*
+cloneWriter writes code of clone and equal functions of single type
+*/
+type cloneWriter struct {
+	pkg      *types.Package
+	names    *VariableNames
+	visiting map[types.Type]bool
+}
+
+/*
+clone returns statements that make dst (shallow copy of src) deep copy of src, ok is false for types that cannot be
+copied
+*/
+func (c *cloneWriter) clone(typ types.Type, src, dst string) (code string, ok bool) {
+	if c.hasMethod(typ, "Clone", typ, nil) {
+		return dst + " = " + src + ".Clone()\n", true
+	}
+	if bigType(typ) != "" {
+		return "if " + src + " != nil {\n" + dst + " = new(" + bigType(typ) + ").Set(" + src + ")\n}\n", true
+	}
+
+	if named, isNamed := typ.(*types.Named); isNamed {
+		if c.visiting[named] {
+			return "", false
+		}
+		c.visiting[named] = true
+		defer delete(c.visiting, named)
+
+		// structs of other packages can have unexported fields, they're copied as values
+		if _, isStruct := named.Underlying().(*types.Struct); isStruct && !c.local(named) {
+			return "", true
+		}
+	}
+
+	switch t := typ.Underlying().(type) {
+	case *types.Basic:
+		return "", true
+	case *types.Pointer:
+		elem := c.names.Next("elem")
+		inner, ok := c.clone(t.Elem(), "(*"+src+")", "(*"+elem+")")
+		if !ok {
+			return "", false
+		}
+		return "if " + src + " != nil {\n" +
+			elem + " := new(" + typeString(t.Elem(), c.pkg) + ")\n" +
+			"*" + elem + " = *" + src + "\n" +
+			inner +
+			dst + " = " + elem + "\n}\n", true
+	case *types.Slice:
+		items := c.names.Next("items")
+		i := c.names.Next("i")
+		inner, ok := c.clone(t.Elem(), src+"["+i+"]", items+"["+i+"]")
+		if !ok {
+			return "", false
+		}
+		code = "if " + src + " != nil {\n" +
+			items + " := make(" + typeString(typ, c.pkg) + ", len(" + src + "))\n" +
+			"copy(" + items + ", " + src + ")\n"
+		if inner != "" {
+			code += "for " + i + " := range " + items + " {\n" + inner + "}\n"
+		}
+		return code + dst + " = " + items + "\n}\n", true
+	case *types.Array:
+		i := c.names.Next("i")
+		inner, ok := c.clone(t.Elem(), src+"["+i+"]", dst+"["+i+"]")
+		if !ok || inner == "" {
+			return "", ok
+		}
+		return "for " + i + " := range " + dst + " {\n" + inner + "}\n", true
+	case *types.Map:
+		items := c.names.Next("items")
+		key, value, item := c.names.Next("key"), c.names.Next("value"), c.names.Next("item")
+		inner, ok := c.clone(t.Elem(), value, item)
+		if !ok {
+			return "", false
+		}
+		return "if " + src + " != nil {\n" +
+			items + " := make(" + typeString(typ, c.pkg) + ", len(" + src + "))\n" +
+			"for " + key + ", " + value + " := range " + src + " {\n" +
+			item + " := " + value + "\n" +
+			inner +
+			items + "[" + key + "] = " + item + "\n}\n" +
+			dst + " = " + items + "\n}\n", true
+	case *types.Struct:
+		fields := []string{}
+		for i := 0; i < t.NumFields(); i++ {
+			field := t.Field(i)
+			if field.Name() == "_" {
+				continue
+			}
+			inner, ok := c.clone(field.Type(), src+"."+field.Name(), dst+"."+field.Name())
+			if !ok {
+				return "", false
+			}
+			fields = append(fields, inner)
+		}
+		return strings.Join(fields, ""), true
+	}
+
+	// funcs (streams), channels and interfaces (readers) cannot be copied
+	return "", false
+}
+
+/*
+equal returns statements that return false when a and b differ, ok is false for types that cannot be compared
+*/
+func (c *cloneWriter) equal(typ types.Type, a, b string) (code string, ok bool) {
+	if c.hasMethod(typ, "Equal", types.Typ[types.Bool], typ) {
+		return "if !" + a + ".Equal(" + b + ") {\nreturn false\n}\n", true
+	}
+	if bigType(typ) != "" {
+		return "if (" + a + " == nil) != (" + b + " == nil) || " + a + " != nil && " + a + ".Cmp(" + b + ") != 0 {\n" +
+			"return false\n}\n", true
+	}
+
+	if named, isNamed := typ.(*types.Named); isNamed {
+		if c.visiting[named] {
+			return "", false
+		}
+		c.visiting[named] = true
+		defer delete(c.visiting, named)
+
+		// structs of other packages can have unexported fields, they're compared by ==
+		if _, isStruct := named.Underlying().(*types.Struct); isStruct && !c.local(named) {
+			if !types.Comparable(named) {
+				return "", false
+			}
+			return "if " + a + " != " + b + " {\nreturn false\n}\n", true
+		}
+	}
+
+	switch t := typ.Underlying().(type) {
+	case *types.Basic:
+		return "if " + a + " != " + b + " {\nreturn false\n}\n", true
+	case *types.Pointer:
+		inner, ok := c.equal(t.Elem(), "(*"+a+")", "(*"+b+")")
+		if !ok {
+			return "", false
+		}
+		return "if (" + a + " == nil) != (" + b + " == nil) {\nreturn false\n}\n" +
+			"if " + a + " != nil {\n" + inner + "}\n", true
+	case *types.Slice, *types.Array:
+		elem := t.(interface{ Elem() types.Type }).Elem()
+		i := c.names.Next("i")
+		inner, ok := c.equal(elem, a+"["+i+"]", b+"["+i+"]")
+		if !ok {
+			return "", false
+		}
+		return "if len(" + a + ") != len(" + b + ") {\nreturn false\n}\n" +
+			"for " + i + " := range " + a + " {\n" + inner + "}\n", true
+	case *types.Map:
+		key, valueA, valueB, found := c.names.Next("key"), c.names.Next("value"), c.names.Next("value"),
+			c.names.Next("found")
+		inner, ok := c.equal(t.Elem(), valueA, valueB)
+		if !ok {
+			return "", false
+		}
+		return "if len(" + a + ") != len(" + b + ") {\nreturn false\n}\n" +
+			"for " + key + ", " + valueA + " := range " + a + " {\n" +
+			valueB + ", " + found + " := " + b + "[" + key + "]\n" +
+			"if !" + found + " {\nreturn false\n}\n" +
+			inner + "}\n", true
+	case *types.Struct:
+		fields := []string{}
+		for i := 0; i < t.NumFields(); i++ {
+			field := t.Field(i)
+			if field.Name() == "_" {
+				continue
+			}
+			inner, ok := c.equal(field.Type(), a+"."+field.Name(), b+"."+field.Name())
+			if !ok {
+				return "", false
+			}
+			fields = append(fields, inner)
+		}
+		return strings.Join(fields, ""), true
+	}
+
+	return "", false
+}
+
+/*
+local returns whether named type is declared in generated package (its unexported fields are accessible)
+*/
+func (c *cloneWriter) local(named *types.Named) bool {
+	return named.Obj().Pkg() == nil || c.pkg != nil && named.Obj().Pkg().Path() == c.pkg.Path()
+}
+
+/*
+hasMethod returns whether type has method with given name that returns result and accepts param (no params when
+param is nil), e.g. Clone() T of *xmlrpc.Value or Equal(T) bool of time.Time. Only methods of types of generated
+package, of this runtime and of time.Time are used, methods of other packages differ between their versions.
+*/
+func (c *cloneWriter) hasMethod(typ types.Type, name string, result types.Type, param types.Type) bool {
+	named, ok := typ.(*types.Named)
+	if pointer, isPointer := typ.(*types.Pointer); isPointer {
+		named, ok = pointer.Elem().(*types.Named)
+	}
+	if !ok || !c.local(named) && named.Obj().Pkg().Path() != runtimePath && named.String() != "time.Time" {
+		return false
+	}
+
+	obj, _, _ := types.LookupFieldOrMethod(typ, false, c.pkg, name)
+	fn, ok := obj.(*types.Func)
+	if !ok {
+		return false
+	}
+	signature := fn.Type().(*types.Signature)
+	if signature.Results().Len() != 1 || !types.Identical(signature.Results().At(0).Type(), result) {
+		return false
+	}
+	if param == nil {
+		return signature.Params().Len() == 0
+	}
+	return signature.Params().Len() == 1 && types.Identical(signature.Params().At(0).Type(), param)
+}
+
+/*
+bigType returns name of math/big type of pointer (e.g. "big.Int" for *big.Int), empty string for other types
+*/
+func bigType(typ types.Type) string {
+	switch typ.String() {
+	case "*math/big.Int", "*math/big.Float", "*math/big.Rat":
+		return strings.TrimPrefix(typ.String(), "*math/")
+	}
+	return ""
+}
@@ -0,0 +1,153 @@
+package gen
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// directivePrefix starts comment directives of service methods (e.g. "//xmlrpc:timeout=30s")
+	directivePrefix = "//xmlrpc:"
+)
+
+/*
+parseDirectives returns "//xmlrpc:key=value" directives from doc comment of method (directives are not part of
+method help)
+*/
+func parseDirectives(doc *ast.CommentGroup) map[string]string {
+	result := map[string]string{}
+	if doc == nil {
+		return result
+	}
+	for _, comment := range doc.List {
+		if !strings.HasPrefix(comment.Text, directivePrefix) {
+			continue
+		}
+		directive := strings.TrimSpace(strings.TrimPrefix(comment.Text, directivePrefix))
+		if index := strings.Index(directive, "="); index != -1 {
+			result[directive[:index]] = directive[index+1:]
+		} else {
+			result[directive] = ""
+		}
+	}
+	return result
+}
+
+/*
+applyDirectives sets method options from directives
+*/
+func applyDirectives(method *rpcMethod, directives map[string]string) {
+	for key, value := range directives {
+		switch key {
+		case "timeout":
+			timeout, err := time.ParseDuration(value)
+			if err != nil || timeout <= 0 {
+				Exit("Service method %v.%v has invalid timeout %v", method.Service, method.Method, value)
+			}
+			method.Timeout = timeout
+		case "roles":
+			for _, role := range strings.Split(value, ",") {
+				if role = strings.TrimSpace(role); role != "" {
+					method.Roles = append(method.Roles, role)
+				}
+			}
+			if len(method.Roles) == 0 {
+				Exit("Service method %v.%v has empty roles directive", method.Service, method.Method)
+			}
+		default:
+			Exit("Service method %v.%v has unknown directive %v", method.Service, method.Method, key)
+		}
+	}
+}
+
+/*
+durationExpr returns go expression of duration (e.g. "30 * time.Second")
+*/
+func durationExpr(d time.Duration) string {
+	units := []struct {
+		unit time.Duration
+		name string
+	}{
+		{time.Hour, "time.Hour"},
+		{time.Minute, "time.Minute"},
+		{time.Second, "time.Second"},
+		{time.Millisecond, "time.Millisecond"},
+		{time.Microsecond, "time.Microsecond"},
+	}
+	for _, unit := range units {
+		if d%unit.unit == 0 {
+			return fmt.Sprintf("%d * %v", d/unit.unit, unit.name)
+		}
+	}
+	return fmt.Sprintf("%d * time.Nanosecond", d)
+}
+
+/*
+faultMapping maps error (variable or type) to fault code, it's set by "//xmlrpc:fault ErrNotFound=404" directive
+in doc comment of service type
+*/
+type faultMapping struct {
+	// Match is go expression that matches error in "err" variable
+	Match string
+
+	// Code is fault code
+	Code int
+}
+
+/*
+faultMappings returns fault mappings from "//xmlrpc:fault Name=code" directives of service, Name is error variable
+(matched by errors.Is) or error type (matched by errors.As). Variables of other packages can be given as
+"pkg.ErrName".
+*/
+func (g *generator) faultMappings(service string, doc *ast.CommentGroup) []faultMapping {
+	result := []faultMapping{}
+	if doc == nil {
+		return result
+	}
+
+	errorType := types.Universe.Lookup("error").Type().Underlying().(*types.Interface)
+
+	for _, comment := range doc.List {
+		if !strings.HasPrefix(comment.Text, directivePrefix+"fault ") {
+			continue
+		}
+		directive := strings.TrimSpace(strings.TrimPrefix(comment.Text, directivePrefix+"fault "))
+
+		index := strings.Index(directive, "=")
+		if index == -1 {
+			Exit("Service %v has invalid fault directive %v", service, directive)
+		}
+		name := strings.TrimSpace(directive[:index])
+		code, err := strconv.Atoi(strings.TrimSpace(directive[index+1:]))
+		if err != nil {
+			Exit("Service %v has invalid fault code in directive %v", service, directive)
+		}
+
+		mapping := faultMapping{Code: code}
+
+		switch obj := g.pkg.Scope().Lookup(name).(type) {
+		case *types.Var:
+			mapping.Match = fmt.Sprintf("errors.Is(err, %v)", name)
+		case *types.TypeName:
+			if types.Implements(obj.Type(), errorType) {
+				mapping.Match = fmt.Sprintf("errors.As(err, new(%v))", name)
+			} else if types.Implements(types.NewPointer(obj.Type()), errorType) {
+				mapping.Match = fmt.Sprintf("errors.As(err, new(*%v))", name)
+			} else {
+				Exit("Service %v has fault directive for %v that is not error", service, name)
+			}
+		default:
+			if !strings.Contains(name, ".") {
+				Exit("Service %v has fault directive for unknown error %v", service, name)
+			}
+			mapping.Match = fmt.Sprintf("errors.Is(err, %v)", name)
+		}
+
+		result = append(result, mapping)
+	}
+	return result
+}
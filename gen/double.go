@@ -0,0 +1,54 @@
+package gen
+
+import (
+	"fmt"
+	"strconv"
+)
+
+/*
+WithLenientDoubles decodes doubles sent by broken servers, comma is accepted as decimal separator (digit
+grouping separators are removed) and exponent is accepted ("1,5", "1.234,5", "1,234.5", "1.5e3")
+*/
+func WithLenientDoubles() GeneratorOption {
+	return func(g *generator) {
+		g.paramOptions.lenientDoubles = true
+	}
+}
+
+/*
+WithNonFiniteDoubles writes NaN and infinities as "NaN", "Infinity" and "-Infinity" (extension used by
+some implementations) and decodes them. Without this option encoding of NaN or infinity fails with internal
+error fault and such values are rejected as invalid params.
+*/
+func WithNonFiniteDoubles() GeneratorOption {
+	return func(g *generator) {
+		g.paramOptions.nonFiniteDoubles = true
+	}
+}
+
+/*
+WithDoubleFormat sets strconv format ('f', 'g' or 'e') and precision (-1 is shortest representation that reads
+back exactly) of written doubles. Default is 'f' with precision -1 (plain decimals without exponent, as required by
+specification). Single struct field can be tagged ",fmt=f,prec=2" instead.
+*/
+func WithDoubleFormat(format byte, precision int) GeneratorOption {
+	return func(g *generator) {
+		g.paramOptions.doubleFormat = string(format)
+		g.paramOptions.doublePrecision = strconv.Itoa(precision)
+	}
+}
+
+/*
+checkDoubleFormat returns error when format or precision of doubles is invalid
+*/
+func checkDoubleFormat(format, precision string) error {
+	switch format {
+	case "f", "g", "e":
+	default:
+		return fmt.Errorf("unknown double format %q, available formats: f, g, e", format)
+	}
+	if prec, err := strconv.Atoi(precision); err != nil || prec < -1 {
+		return fmt.Errorf("invalid double precision %q", precision)
+	}
+	return nil
+}
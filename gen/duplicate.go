@@ -0,0 +1,11 @@
+package gen
+
+/*
+WithDuplicateMembers sets how structs with same member name sent twice are decoded (xmlrpc.DuplicateMembersLast,
+xmlrpc.DuplicateMembersFirst or xmlrpc.DuplicateMembersError)
+*/
+func WithDuplicateMembers(policy string) GeneratorOption {
+	return func(g *generator) {
+		g.paramOptions.duplicateMembers = policy
+	}
+}
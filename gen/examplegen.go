@@ -0,0 +1,195 @@
+package gen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"strings"
+	"time"
+
+	"github.com/phonkee/go-xmlrpc"
+)
+
+var (
+	// textSamples are sample texts of types that marshal to text, other text types have no known sample
+	textSamples = map[string]string{
+		"big.Int":    "0",
+		"big.Float":  "0",
+		"big.Rat":    "0",
+		"net.IP":     "127.0.0.1",
+		"netip.Addr": "127.0.0.1",
+	}
+
+	// knownSamples are sample texts of types in knownTypes by their parse func
+	knownSamples = map[string]string{
+		"XPathValueGetURL":         "http://example.com/",
+		"XPathValueGetMailAddress": "user@example.com",
+	}
+)
+
+/*
+sampleRequest returns indented methodCall with zero values of all method params, namespace is service namespace
+*/
+func sampleRequest(namespace string, method *rpcMethod) string {
+	result, _ := writeSampleRequest(namespace, method)
+	return result
+}
+
+/*
+sampleAccepted returns whether sample request of method is valid, it's not for params without known sample
+(codecs, text types, strings with pattern)
+*/
+func sampleAccepted(method *rpcMethod) bool {
+	_, valid := writeSampleRequest("", method)
+	return valid
+}
+
+/*
+writeSampleRequest returns sample request of method and whether all its params have valid samples
+*/
+func writeSampleRequest(namespace string, method *rpcMethod) (string, bool) {
+	buf := &bytes.Buffer{}
+	buf.WriteString("<?xml version=\"1.0\"?>\n<methodCall>\n")
+	fmt.Fprintf(buf, "  <methodName>%v.%v</methodName>\n  <params>\n", namespace, method.Method)
+	valid := true
+	for _, param := range method.Params {
+		buf.WriteString("    <param>\n      <value>")
+		valid = writeSampleValue(buf, param, "      ") && valid
+		buf.WriteString("</value>\n    </param>\n")
+	}
+	buf.WriteString("  </params>\n</methodCall>")
+	return buf.String(), valid
+}
+
+/*
+writeSampleValue writes sample (zero) value of param, it returns false when value may be rejected
+*/
+func writeSampleValue(buf *bytes.Buffer, p Param, indent string) bool {
+	valid := true
+	switch param := p.(type) {
+	case *structParam:
+		buf.WriteString("<struct>\n")
+		for _, field := range param.params {
+			fmt.Fprintf(buf, "%v  <member>\n%v    <name>%v</name>\n%v    <value>", indent, indent, field.Name(), indent)
+			valid = writeSampleValue(buf, field.Param, indent+"    ") && valid
+			fmt.Fprintf(buf, "</value>\n%v  </member>\n", indent)
+		}
+		fmt.Fprintf(buf, "%v</struct>", indent)
+	case *sliceParam:
+		fmt.Fprintf(buf, "<array>\n%v  <data>\n%v    <value>", indent, indent)
+		valid = writeSampleValue(buf, param.object, indent+"    ")
+		fmt.Fprintf(buf, "</value>\n%v  </data>\n%v</array>", indent, indent)
+	case *tupleParam:
+		fmt.Fprintf(buf, "<array>\n%v  <data>\n", indent)
+		for _, field := range param.params {
+			fmt.Fprintf(buf, "%v    <value>", indent)
+			valid = writeSampleValue(buf, field.Param, indent+"    ") && valid
+			buf.WriteString("</value>\n")
+		}
+		fmt.Fprintf(buf, "%v  </data>\n%v</array>", indent, indent)
+	case *unionParam:
+		valid = writeSampleValue(buf, param.variants[0].Param, indent)
+	case *boolParam:
+		buf.WriteString("<boolean>0</boolean>")
+	case *intParam:
+		buf.WriteString("<int>0</int>")
+	case *floatParam:
+		buf.WriteString("<double>0.5</double>")
+	case *bytesParam, *readerParam:
+		buf.WriteString("<base64></base64>")
+	case *timeParam:
+		fmt.Fprintf(buf, "<dateTime.iso8601>%v</dateTime.iso8601>",
+			xmlrpc.DefaultDateTimePolicy.Format(time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC)))
+	case *lazyStructParam:
+		buf.WriteString("<struct></struct>")
+	case *uuidParam:
+		buf.WriteString("<string>00000000-0000-0000-0000-000000000000</string>")
+	case *knownParam:
+		var sample string
+		sample, valid = knownSamples[param.known.Parse]
+		fmt.Fprintf(buf, "<string>%v</string>", sample)
+	case *textParam:
+		var sample string
+		sample, valid = textSamples[strings.TrimPrefix(param.typ, "*")]
+		fmt.Fprintf(buf, "<string>%v</string>", sample)
+	case *stringParam:
+		buf.WriteString("<string></string>")
+		valid = param.pattern == ""
+	case *codecParam:
+		buf.WriteString("<string></string>")
+		valid = false
+	default:
+		buf.WriteString("<string></string>")
+	}
+	return valid
+}
+
+/*
+FormatExamples returns formatted source code of examples (example_test.go) that call every service method
+through handler with sample request, so generated packages are documented with payloads they accept. Examples
+run with tests, their output is whether sample request was accepted (result depends on service). Examples of
+methods with params without known valid sample (codecs, strings with pattern...) are only compiled.
+*/
+func (g *generator) FormatExamples() []byte {
+	g.buf.Reset()
+
+	g.writeHeader()
+
+	g.WriteTemplate(`
+	package {{.Package}}
+
+	import (
+		"fmt"
+		"io/ioutil"
+		"net/http/httptest"
+		"strings"
+
+		xmlrpc "github.com/phonkee/go-xmlrpc"
+	)
+
+	{{range $service, $methods := .Services}}{{$namespace := namespace $service}}
+		{{range $methods}}
+			/*
+			Example{{$service}}_{{.Method}} calls {{.Method}} of {{$service}} registered as "{{$namespace}}"
+			*/
+			func Example{{$service}}_{{.Method}}() {
+				handler := xmlrpc.NewHandler()
+				if err := handler.AddService(&{{$service}}{}, "{{$namespace}}"); err != nil {
+					panic(err)
+				}
+
+				request := httptest.NewRequest("POST", "/", strings.NewReader(`+"`"+`{{sampleRequest $namespace .}}`+"`"+`))
+				request.Header.Set("Content-Type", "text/xml")
+				response := httptest.NewRecorder()
+				handler.ServeHTTP(response, request)
+
+				// result depends on service, example checks that sample request is accepted (faults of method are fine)
+				body, _ := ioutil.ReadAll(response.Body)
+				if _, err := xmlrpc.ParseResponse(body); err != nil {
+					if fault, ok := err.(*xmlrpc.Fault); !ok || fault.Code() == xmlrpc.FaultInvalidParams {
+						fmt.Println(err)
+						return
+					}
+				}
+				fmt.Println("accepted")
+				{{- if sampleAccepted .}}
+				// Output: accepted
+				{{- end}}
+			}
+		{{end}}
+	{{end}}
+	`, map[string]interface{}{
+		"Services": g.services,
+		"Package":  g.pkg.Name(),
+	})
+
+	src, err := g.removeUnusedImports(g.buf.Bytes())
+	if err == nil {
+		src, err = format.Source(src)
+	}
+	if err != nil {
+		fmt.Printf("warning: internal error: invalid Go generated: %s\n", err)
+		return g.buf.Bytes()
+	}
+	return src
+}
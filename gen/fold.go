@@ -0,0 +1,27 @@
+package gen
+
+import "github.com/phonkee/go-xmlrpc"
+
+/*
+WithFoldMembers matches struct members case-insensitively and ignores underscores and dashes in their names,
+so "user_id", "UserId" and "userID" are decoded to the same field.
+*/
+func WithFoldMembers() GeneratorOption {
+	return func(g *generator) {
+		g.paramOptions.foldMembers = true
+	}
+}
+
+/*
+checkFoldedMembers exits when members of struct cannot be told apart after folding
+*/
+func checkFoldedMembers(name string, fields []*structField) {
+	seen := make(map[string]string, len(fields))
+	for _, field := range fields {
+		folded := xmlrpc.FoldMemberName(field.Name())
+		if other, ok := seen[folded]; ok {
+			Exit("Struct %v has members %v and %v that are same with folded member names", name, other, field.Name())
+		}
+		seen[folded] = field.Name()
+	}
+}
@@ -0,0 +1,700 @@
+/*
+Generator generates two structures, one for parameters one for result values.
+
+Result values must be two, first is actual return value and second is error.
+If error is returned fault response will be generated.
+*/
+package gen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/types"
+	"text/template"
+
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/phonkee/go-xmlrpc"
+	"golang.org/x/tools/go/ast/astutil"
+	"golang.org/x/tools/go/loader"
+)
+
+/*
+Generator interface
+*/
+type Generator interface {
+	// add service by its name
+	AddService(name string) error
+
+	// AddServices adds services by their names, methods are processed concurrently
+	AddServices(names ...string) error
+
+	// format returns formatted source code
+	Format() []byte
+
+	// FormatMocks returns formatted source code of service interfaces and their mocks
+	FormatMocks() []byte
+
+	// FormatExamples returns formatted source code of examples that call service methods
+	FormatExamples() []byte
+
+	// FormatTypeScript returns TypeScript source of service clients
+	FormatTypeScript() []byte
+
+	// FormatPython returns Python source of service clients
+	FormatPython() []byte
+
+	// Lint returns issues of service methods (unsupported types and poor wire formats)
+	Lint(name string) ([]LintIssue, error)
+
+	// Hash returns hash of added services and options, generated code changes only when hash changes
+	Hash() string
+}
+
+/*
+GeneratorOption configures generator
+*/
+type GeneratorOption func(*generator)
+
+/*
+WithBackend sets backend that writes responses ("etree", "xml" or "writer")
+*/
+func WithBackend(name string) GeneratorOption {
+	return func(g *generator) {
+		g.backendName = name
+	}
+}
+
+/*
+WithCoercion enables coercion of scalar values sent with other type (numbers sent as strings...) in generated
+decode code. It can be overridden for single struct field by ",coerce" or ",strict" tag.
+*/
+func WithCoercion() GeneratorOption {
+	return func(g *generator) {
+		g.paramOptions.coerce = true
+	}
+}
+
+/*
+WithLenientEmpty decodes empty array sent instead of struct (and empty struct sent instead of array) as zero
+value, PHP servers can't tell them apart.
+*/
+func WithLenientEmpty() GeneratorOption {
+	return func(g *generator) {
+		g.paramOptions.lenientEmpty = true
+	}
+}
+
+const (
+	// Version is version of generated code, init of generated code checks that runtime supports it
+	Version = xmlrpc.GeneratedVersion
+)
+
+const (
+	// MemberOrderField writes struct members in order of go struct fields
+	MemberOrderField = "field"
+
+	// MemberOrderAlpha writes struct members in alphabetical order
+	MemberOrderAlpha = "alpha"
+)
+
+/*
+WithMemberOrder sets order of written struct members (MemberOrderField or MemberOrderAlpha). Members can be
+also ordered explicitly by ",order=N" tag, such members are written first.
+*/
+func WithMemberOrder(order string) GeneratorOption {
+	return func(g *generator) {
+		g.paramOptions.memberOrder = order
+	}
+}
+
+/*
+WithCodecTypes sets types (as written in service source, e.g. "Money" or "decimal.Decimal") that are encoded and
+decoded by codecs registered with xmlrpc.RegisterCodec. Single struct field can be tagged ",codec" instead.
+*/
+func WithCodecTypes(types ...string) GeneratorOption {
+	return func(g *generator) {
+		for _, typ := range types {
+			g.paramOptions.codecTypes[typ] = true
+		}
+	}
+}
+
+/*
+WithWorkers sets maximum count of service methods whose params are built concurrently (GOMAXPROCS by default)
+*/
+func WithWorkers(workers int) GeneratorOption {
+	return func(g *generator) {
+		g.workers = workers
+	}
+}
+
+/*
+NewGenerator returns Generator implementation
+*/
+func NewGenerator(filename string, options ...GeneratorOption) (Generator, error) {
+	result := &generator{
+		services:    map[string][]*rpcMethod{},
+		docs:        map[token.Pos]*ast.CommentGroup{},
+		faults:      map[string][]faultMapping{},
+		backendName: DefaultBackend,
+		workers:     runtime.GOMAXPROCS(0),
+	}
+	result.paramOptions.memberOrder = MemberOrderField
+	result.paramOptions.duplicateMembers = xmlrpc.DuplicateMembersLast
+	result.paramOptions.doubleFormat = "f"
+	result.paramOptions.doublePrecision = "-1"
+	result.paramOptions.codecTypes = map[string]bool{}
+	result.paramOptions.codecs = &codecSet{types: map[string]bool{}}
+
+	for _, option := range options {
+		option(result)
+	}
+
+	var err error
+
+	if order := result.paramOptions.memberOrder; order != MemberOrderField && order != MemberOrderAlpha {
+		return nil, fmt.Errorf("unknown member order %v, available orders: %v, %v", order, MemberOrderField, MemberOrderAlpha)
+	}
+
+	if err = checkDoubleFormat(result.paramOptions.doubleFormat, result.paramOptions.doublePrecision); err != nil {
+		return nil, err
+	}
+
+	if result.workers < 1 {
+		return nil, fmt.Errorf("invalid count of workers %v", result.workers)
+	}
+
+	switch result.paramOptions.duplicateMembers {
+	case xmlrpc.DuplicateMembersLast, xmlrpc.DuplicateMembersFirst, xmlrpc.DuplicateMembersError:
+	default:
+		return nil, fmt.Errorf("unknown duplicate members policy %v, available policies: %v, %v, %v",
+			result.paramOptions.duplicateMembers, xmlrpc.DuplicateMembersLast, xmlrpc.DuplicateMembersFirst, xmlrpc.DuplicateMembersError)
+	}
+
+	if result.backend, err = getBackend(result.backendName); err != nil {
+		return nil, err
+	}
+
+	result.addImports("context", "errors", "io", "reflect", "strconv", "time")
+	result.addImports(result.backend.imports...)
+
+	// parse file
+	if err = result.parseFile(filename); err != nil {
+		return nil, err
+	}
+
+	// types of method params can come from any package imported by parsed package (unused imports are removed)
+	for _, imp := range result.pkg.Imports() {
+		if imp.Path() != "github.com/phonkee/go-xmlrpc" {
+			result.addImports(imp.Path())
+		}
+	}
+	return result, nil
+}
+
+/*
+generator
+*/
+type generator struct {
+	// buffer for accumulated output
+	buf bytes.Buffer
+
+	// parsed package
+	pkg *types.Package
+
+	imports []string
+
+	// store methods
+	services map[string][]*rpcMethod
+
+	// doc comments of methods and types by position of their name
+	docs map[token.Pos]*ast.CommentGroup
+
+	// fault mappings of services set by directives
+	faults map[string][]faultMapping
+
+	// backend writes responses
+	backendName string
+	backend     *backend
+
+	// paramOptions are default options of generated param code
+	paramOptions paramOptions
+
+	// workers is maximum count of methods built concurrently
+	workers int
+}
+
+func (g *generator) addImports(imports ...string) {
+	for _, imp := range imports {
+		found := false
+		for _, existing := range g.imports {
+			if existing == imp {
+				found = true
+				break
+			}
+		}
+		if !found {
+			g.imports = append(g.imports, imp)
+		}
+	}
+}
+
+/*
+parseFile parses file
+*/
+func (g *generator) parseFile(filename string) (err error) {
+
+	fset := token.NewFileSet()
+
+	kpath := "."
+
+	// output of previous run is not parsed, it doesn't compile when methods of services changed. Tests are not
+	// parsed either, their imports (e.g. build tagged benchmarks of other libraries) would leak to generated code
+	pkgs, e := parser.ParseDir(fset, kpath, func(info os.FileInfo) bool {
+		name := info.Name()
+		return !info.IsDir() && !strings.HasPrefix(name, ".") && strings.HasSuffix(name, ".go") &&
+			!strings.HasSuffix(name, "_xmlrpc.go") && !strings.HasSuffix(name, "_xmlrpc_mock.go") &&
+			!strings.HasSuffix(name, "_test.go")
+	}, parser.ParseComments)
+	if e != nil {
+		Exit(e)
+		return
+	}
+
+	astf := make([]*ast.File, 0)
+	for _, pkg := range pkgs {
+		for _, f := range pkg.Files {
+			astf = append(astf, f)
+
+			// store doc comments of methods (served as method help) and types (directives of services)
+			for _, decl := range f.Decls {
+				switch decl := decl.(type) {
+				case *ast.FuncDecl:
+					if decl.Recv != nil && decl.Doc != nil {
+						g.docs[decl.Name.Pos()] = decl.Doc
+					}
+				case *ast.GenDecl:
+					for _, spec := range decl.Specs {
+						if spec, ok := spec.(*ast.TypeSpec); ok {
+							if spec.Doc != nil {
+								g.docs[spec.Name.Pos()] = spec.Doc
+							} else if decl.Doc != nil {
+								g.docs[spec.Name.Pos()] = decl.Doc
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+
+	var conf loader.Config
+	conf.CreateFromFiles(".", astf...)
+	prog, err := conf.Load()
+
+	if err != nil {
+		Exit(err)
+	}
+
+	p := prog.Package(".")
+	if p == nil {
+		Exit("errors")
+	}
+
+	g.pkg = p.Pkg
+
+	return nil
+
+}
+
+func (g *generator) Printf(format string, args ...interface{}) {
+	fmt.Fprintf(&g.buf, format, args...)
+}
+
+func (g *generator) WriteTemplate(tpl string, data map[string]interface{}) {
+	g.Printf("%v", RenderTemplate(tpl, data, g.funcs()))
+}
+
+/*
+funcs returns template functions of generated code
+*/
+func (g *generator) funcs() template.FuncMap {
+	return template.FuncMap{
+		"getAvailableMethodsVariable": getAvailableMethodsVariable,
+		"getAvailableMethods":         getAvailableMethods,
+		"renderResponse":              g.renderResponse,
+		"mock":                        g.mockMethod,
+		"cloneFuncs":                  g.cloneFuncs,
+		"sampleRequest":               sampleRequest,
+		"sampleAccepted":              sampleAccepted,
+		"namespace":                   strings.ToLower,
+		"durationExpr":                durationExpr,
+		"tsParams":                    tsParams,
+		"tsType":                      tsType,
+		"tsSchema":                    tsSchema,
+		"tsDoc":                       tsDoc,
+		"clientDoc":                   clientDoc,
+		"pyDoc":                       pyDoc,
+		"pyParams":                    pyParams,
+		"pyType":                      pyType,
+	}
+}
+
+// format generates and returns the gofmt-ed contents of the Generator's buffer.
+func (g *generator) Format() []byte {
+	g.buf = bytes.Buffer{}
+
+	// writeHeader writes header (package name, imports)
+	g.writeHeader()
+
+	g.WriteTemplate(`
+	package {{.Package}}
+	import (
+		{{range .Imports}}"{{.}}"
+		{{end -}}
+		xmlrpc "github.com/phonkee/go-xmlrpc"
+	)
+
+	/*
+	init checks that runtime supports this code and registers codecs of types that implement xmlrpc.ValueMarshaler
+	*/
+	func init() {
+		xmlrpc.RequireGeneratedVersion({{.Version}}){{range .Codecs}}
+		xmlrpc.RegisterMarshaler(reflect.TypeOf((*{{.}})(nil)).Elem()){{end}}
+	}
+
+	{{range $service, $methods := .Services}}
+		{{ $availMethodsVarname := getAvailableMethodsVariable $service}}
+
+		var (
+			{{$availMethodsVarname}} = map[string]bool{
+			{{range $methods }}"{{.Method}}": true,{{end}}
+			}
+		)
+
+		/*
+		MethodExists returns whether rpc method is available on service
+		*/
+		func (s *{{$service}}) MethodExists(method string) (ok bool) {
+			_, ok = {{$availMethodsVarname}}[method]
+			return
+		}
+
+		/*
+		ListMethods returns list of all available methods for given service
+		*/
+		func (s *{{$service}}) ListMethods() []string {
+			result := make([]string, 0, len({{$availMethodsVarname}}))
+			for key := range {{$availMethodsVarname}} {
+				result = append(result, key)
+			}
+			return result
+		}
+
+		/*
+		MethodSignatures returns xmlrpc types of all methods, result type is first followed by types of params
+		*/
+		func (s *{{$service}}) MethodSignatures() map[string][]string {
+			return map[string][]string{ {{range $methods}}
+				"{{.Method}}": { {{range .WireTypes}}"{{.}}", {{end}} },{{end}}
+			}
+		}
+
+		/*
+		MethodParamNames returns names of params of all methods
+		*/
+		func (s *{{$service}}) MethodParamNames() map[string][]string {
+			return map[string][]string{ {{range $methods}}
+				"{{.Method}}": { {{range .Params}}"{{.Name}}", {{end}} },{{end}}
+			}
+		}
+
+		/*
+		MethodHelp returns doc comments of documented methods
+		*/
+		func (s *{{$service}}) MethodHelp() map[string]string {
+			return map[string]string{ {{range $methods}}{{if .Doc}}
+				"{{.Method}}": {{printf "%q" .Doc}},{{end}}{{end}}
+			}
+		}
+
+		/*
+		MethodRoles returns roles of callers allowed to call methods with "//xmlrpc:roles" directive
+		*/
+		func (s *{{$service}}) MethodRoles() map[string][]string {
+			return map[string][]string{ {{range $methods}}{{if .Roles}}
+				"{{.Method}}": { {{range .Roles}}{{printf "%q" .}}, {{end}} },{{end}}{{end}}
+			}
+		}
+
+		/*
+		MethodTimeouts returns timeouts of methods with "//xmlrpc:timeout" directive, clients apply them by
+		xmlrpc.NewTimeoutTransport
+		*/
+		func (s *{{$service}}) MethodTimeouts() map[string]time.Duration {
+			return map[string]time.Duration{ {{range $methods}}{{if .Timeout}}
+				"{{.Method}}": {{durationExpr .Timeout}},{{end}}{{end}}
+			}
+		}
+
+		{{with index $.Faults $service}}
+			/*
+			faultCode{{$service}} sets fault codes of errors listed in "//xmlrpc:fault" directives of {{$service}}
+			*/
+			func faultCode{{$service}}(err error) error {
+				switch { {{range .}}
+				case {{.Match}}:
+					return xmlrpc.FaultWithCode(err, {{.Code}}){{end}}
+				}
+				return err
+			}
+		{{end}}
+
+		/*
+		Dispatch dispatches method on service, do not use this method directly.
+		root is params *xmlrpc.Element (actually "methodCall/params"
+		*/
+		func (s *{{$service}}) Dispatch(ctx context.Context, method string, root *xmlrpc.Element) (res io.WriterTo, err error) {
+
+			// panic in service method is returned as internal error
+			defer xmlrpc.RecoverPanic("{{$service}}." + method, &err)
+			{{if index $.Faults $service}}
+				// set fault codes of errors listed in directives
+				defer func() {
+					err = faultCode{{$service}}(err)
+				}()
+			{{end}}
+
+			// call appropriate methods
+			switch method { {{range $methods}}
+			case "{{.Method}}":
+				{{index $.Bodies .}}
+			{{end}}
+			default:
+				// method not found, this should not happened since we check whether method exists
+				err = xmlrpc.ErrMethodNotFound
+				return
+			}
+		}
+
+		{{range $methods}}{{cloneFuncs $service .}}{{end}}
+	{{end}}
+	`, map[string]interface{}{
+		"Services": g.services,
+		"Bodies":   g.renderMethods(),
+		"Codecs":   g.paramOptions.codecs.sorted(),
+		"Version":  Version,
+		"Faults":   g.faults,
+		"Package":  g.pkg.Name(),
+		"Imports":  g.imports,
+	})
+
+	src, err := g.removeUnusedImports(g.buf.Bytes())
+	if err == nil {
+		src, err = format.Source(src)
+	}
+	if err != nil {
+		// Should never happen, but can arise when developing this code.
+		// The user can compile the output to see the error.
+		fmt.Printf("warning: internal error: invalid Go generated: %s\n", err)
+		fmt.Print("warning: compile the package to analyze the error\n")
+		return g.buf.Bytes()
+	}
+	return src
+}
+
+/*
+methodTemplate writes code of method call in Dispatch
+*/
+const methodTemplate = `{{with .Method}}
+			{{if .Timeout}}
+				// timeout set by directive, streamed results are written after Dispatch returns so context is
+				// cancelled when they are written
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, {{durationExpr .Timeout}})
+				defer func() {
+					res = xmlrpc.CancelAfterWrite(res, cancel)
+				}()
+			{{end}}
+
+			// Get parameters from xmlrpc request
+
+			{{$resultVar := GenerateVariableName "result"}}
+
+			{{ if .Result }}
+				{{.FromEtree names "root" $resultVar "err"}}
+			{{ else }}
+				{{.FromEtree names "root" "" "err"}}
+			{{ end }}
+
+			// error is written as fault by handler
+			if {{.ResultError.Name}} != nil {
+				return
+			}
+
+			{{renderResponse names . $resultVar "err"}}
+			return
+{{end}}`
+
+/*
+renderMethods renders code of method calls concurrently by workers (see WithWorkers), every method has its own
+variable names, so code doesn't depend on scheduling
+*/
+func (g *generator) renderMethods() map[*rpcMethod]string {
+	methods := []*rpcMethod{}
+	for _, serviceMethods := range g.services {
+		methods = append(methods, serviceMethods...)
+	}
+
+	bodies := make([]string, len(methods))
+	var wg sync.WaitGroup
+	workers := make(chan struct{}, g.workers)
+	for i := range methods {
+		wg.Add(1)
+		workers <- struct{}{}
+		go func(i int) {
+			defer func() {
+				<-workers
+				wg.Done()
+			}()
+
+			names := newVariableNames()
+			bodies[i] = RenderTemplate(methodTemplate, map[string]interface{}{"Method": methods[i]}, g.funcs(),
+				names.Funcs())
+		}(i)
+	}
+	wg.Wait()
+
+	result := make(map[*rpcMethod]string, len(methods))
+	for i, method := range methods {
+		result[method] = bodies[i]
+	}
+	return result
+}
+
+/*
+renderResponse renders code that writes method result to response
+*/
+func (g *generator) renderResponse(names *VariableNames, method *rpcMethod, resultvar string, errvar string) string {
+	// streamed results don't depend on backend
+	if responder, ok := method.Result.(responseParam); ok {
+		return responder.Response(names, resultvar, errvar)
+	}
+
+	return g.backend.render(names, "response", map[string]interface{}{
+		"Result":    method.Result,
+		"ResultVar": resultvar,
+		"ErrorVar":  errvar,
+	})
+}
+
+/*
+removeUnusedImports removes imports that are not used by generated code (e.g. strconv when no int is written)
+*/
+func (g *generator) removeUnusedImports(src []byte) ([]byte, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "", src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, imp := range g.imports {
+		if !astutil.UsesImport(f, imp) {
+			astutil.DeleteImport(fset, f, imp)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err = format.Node(&buf, fset, f); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+/*
+AddService adds service
+*/
+func (g *generator) AddService(name string) error {
+	return g.AddServices(name)
+}
+
+/*
+AddServices adds services, param trees of their methods are built concurrently by workers (see WithWorkers)
+*/
+func (g *generator) AddServices(names ...string) error {
+	type job struct {
+		service string
+		method  *types.Func
+		result  *rpcMethod
+	}
+
+	jobs := []*job{}
+	for _, name := range names {
+		obj := g.pkg.Scope().Lookup(name)
+		if obj == nil {
+			return fmt.Errorf("Service %v unavailable.", name)
+		}
+
+		// if service not there create one
+		if _, ok := g.services[name]; !ok {
+			g.services[name] = []*rpcMethod{}
+		}
+
+		// fault codes of errors are set by directives in doc comment of service
+		if mappings := g.faultMappings(name, g.docs[obj.Pos()]); len(mappings) > 0 {
+			g.faults[name] = mappings
+		}
+
+		// prepare methodset
+		mset := types.NewMethodSet(types.NewPointer(obj.Type()))
+		for i := 0; i < mset.Len(); i++ {
+			jobs = append(jobs, &job{service: name, method: mset.At(i).Obj().(*types.Func)})
+		}
+	}
+
+	// build methods with bounded count of workers
+	var wg sync.WaitGroup
+	workers := make(chan struct{}, g.workers)
+	for _, j := range jobs {
+		wg.Add(1)
+		workers <- struct{}{}
+		go func(j *job) {
+			defer func() {
+				<-workers
+				wg.Done()
+			}()
+
+			// add service method
+			j.result = newRPCMethod(j.service, j.method.Name(), j.method.Type().(*types.Signature), g.backend, g.paramOptions)
+			if doc, ok := g.docs[j.method.Pos()]; ok {
+				j.result.Doc = strings.TrimSpace(doc.Text())
+				applyDirectives(j.result, parseDirectives(doc))
+			}
+		}(j)
+	}
+	wg.Wait()
+
+	// methods are added in order of method sets, so generated code doesn't depend on scheduling
+	for _, j := range jobs {
+		g.services[j.service] = append(g.services[j.service], j.result)
+	}
+
+	return nil
+}
+
+/*
+Write header
+*/
+func (g *generator) writeHeader() {
+	g.Printf("// This file is autogenerated by xmlrpcgen (generated code version %v)\n", Version)
+	g.Printf("// do not change it directly!\n")
+}
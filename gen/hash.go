@@ -0,0 +1,101 @@
+package gen
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"go/types"
+	"io"
+	"sort"
+)
+
+/*
+Hash returns hash of added services and generator options. It covers signatures of methods (with struct tags),
+their doc comments (help and directives) and definitions and method sets of named types they use, so generated
+code needs to be regenerated only when hash changes.
+*/
+func (g *generator) Hash() string {
+	h := sha256.New()
+
+	fmt.Fprintf(h, "backend %v\noptions %#v\n", g.backendName, g.paramOptions.hashable())
+
+	services := make([]string, 0, len(g.services))
+	for service := range g.services {
+		services = append(services, service)
+	}
+	sort.Strings(services)
+
+	named := map[*types.Named]bool{}
+	for _, service := range services {
+		fmt.Fprintf(h, "service %v\n", service)
+		if doc, ok := g.docs[g.pkg.Scope().Lookup(service).Pos()]; ok {
+			fmt.Fprintf(h, "doc %q\n", doc.Text())
+		}
+
+		methods := append([]*rpcMethod{}, g.services[service]...)
+		sort.Slice(methods, func(i, j int) bool { return methods[i].Method < methods[j].Method })
+		for _, method := range methods {
+			fmt.Fprintf(h, "method %v %v\ndoc %q\n", method.Method, method.Signature, method.Doc)
+			collectNamed(method.Signature, named)
+		}
+	}
+
+	// named types are hashed by their definition and methods (e.g. text marshalers, codecs)
+	definitions := make([]string, 0, len(named))
+	for typ := range named {
+		mset := types.NewMethodSet(types.NewPointer(typ))
+		definition := fmt.Sprintf("type %v %v\n", typ, typ.Underlying())
+		for i := 0; i < mset.Len(); i++ {
+			definition += fmt.Sprintf("func %v\n", mset.At(i).Obj())
+		}
+		definitions = append(definitions, definition)
+	}
+	sort.Strings(definitions)
+	for _, definition := range definitions {
+		io.WriteString(h, definition)
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+/*
+collectNamed adds named types used by type (recursively) to given set
+*/
+func collectNamed(typ types.Type, named map[*types.Named]bool) {
+	switch x := typ.(type) {
+	case *types.Named:
+		if named[x] {
+			return
+		}
+		named[x] = true
+		collectNamed(x.Underlying(), named)
+	case *types.Pointer:
+		collectNamed(x.Elem(), named)
+	case *types.Slice:
+		collectNamed(x.Elem(), named)
+	case *types.Array:
+		collectNamed(x.Elem(), named)
+	case *types.Map:
+		collectNamed(x.Key(), named)
+		collectNamed(x.Elem(), named)
+	case *types.Struct:
+		for i := 0; i < x.NumFields(); i++ {
+			collectNamed(x.Field(i).Type(), named)
+		}
+	case *types.Signature:
+		collectNamed(x.Params(), named)
+		collectNamed(x.Results(), named)
+	case *types.Tuple:
+		for i := 0; i < x.Len(); i++ {
+			collectNamed(x.At(i).Type(), named)
+		}
+	}
+}
+
+/*
+hashable returns options without codecs collected during generation (they depend on services only)
+*/
+func (o paramOptions) hashable() paramOptions {
+	o.codecs = nil
+	return o
+}
@@ -0,0 +1,225 @@
+package gen
+
+import (
+	"fmt"
+	"go/types"
+	"reflect"
+	"strings"
+)
+
+/*
+LintIssue is construct of service method that cannot be generated (Error) or that generates poor wire format
+(e.g. 64 bit integers that most clients reject)
+*/
+type LintIssue struct {
+	// Path is method and param of construct (e.g. "HelloService.Search: page")
+	Path string
+
+	// Error is true when generator rejects construct, other issues are warnings
+	Error bool
+
+	Message string
+}
+
+/*
+String returns issue as single line
+*/
+func (i LintIssue) String() string {
+	severity := "warning"
+	if i.Error {
+		severity = "error"
+	}
+	return fmt.Sprintf("%v: %v: %v", i.Path, severity, i.Message)
+}
+
+/*
+Lint analyzes methods of service and returns issues of their params and results, nothing is generated
+*/
+func (g *generator) Lint(name string) ([]LintIssue, error) {
+	obj := g.pkg.Scope().Lookup(name)
+	if obj == nil {
+		return nil, fmt.Errorf("Service %v unavailable.", name)
+	}
+
+	l := &linter{
+		pkg:  g.pkg,
+		opts: g.paramOptions,
+	}
+
+	mset := types.NewMethodSet(types.NewPointer(obj.Type()))
+	for i := 0; i < mset.Len(); i++ {
+		method := mset.At(i).Obj().(*types.Func)
+		l.lintMethod(name+"."+method.Name(), method.Type().(*types.Signature))
+	}
+
+	return l.issues, nil
+}
+
+/*
+linter collects issues of types used by service methods
+*/
+type linter struct {
+	pkg    *types.Package
+	opts   paramOptions
+	issues []LintIssue
+}
+
+func (l *linter) warn(path string, format string, args ...interface{}) {
+	l.issues = append(l.issues, LintIssue{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+func (l *linter) fail(path string, format string, args ...interface{}) {
+	l.issues = append(l.issues, LintIssue{Path: path, Error: true, Message: fmt.Sprintf(format, args...)})
+}
+
+/*
+lintMethod checks params and results of method
+*/
+func (l *linter) lintMethod(path string, signature *types.Signature) {
+	params := signature.Params()
+	for i := 0; i < params.Len(); i++ {
+		param := params.At(i)
+
+		// first param can be context.Context
+		if i == 0 && param.Type().String() == "context.Context" {
+			continue
+		}
+
+		name := param.Name()
+		if name == "" || name == "_" {
+			name = fmt.Sprintf("param %d", i)
+		}
+		if signature.Variadic() && i == params.Len()-1 {
+			l.fail(path+": "+name, "variadic params are not supported")
+			continue
+		}
+		l.lintType(path+": "+name, param.Type(), fieldTag{})
+	}
+
+	results := signature.Results()
+	if results.Len() == 0 || results.Len() > 2 || results.At(results.Len()-1).Type().String() != "error" {
+		l.fail(path, "method must return either (value, error) or just error")
+		return
+	}
+	if results.Len() == 2 {
+		l.lintType(path+": result", results.At(0).Type(), fieldTag{})
+	}
+}
+
+/*
+lintType checks type of param (result, struct field...) tagged with given tag
+*/
+func (l *linter) lintType(path string, typ types.Type, tag fieldTag) {
+	name := typeString(typ, l.pkg)
+
+	// types with codecs are encoded by user
+	if tag.Has("codec") || l.opts.codecTypes[name] || isValueMarshaler(typ) {
+		return
+	}
+
+	if (tag.Has("max") || tag.Has("pattern")) && !isStringType(typ) {
+		l.fail(path, "max and pattern tags are supported only by strings")
+	}
+
+	// types of registered param factories
+	if hasFactoryParam(typ, l.pkg) {
+		return
+	}
+
+	switch x := typ.(type) {
+	case *types.Basic:
+		switch x.Kind() {
+		case types.Int64, types.Uint, types.Uint32:
+			if !l.opts.apacheExtensions {
+				l.warn(path, "%v values outside of int32 range are written as <int>, most clients reject them "+
+					"(use smaller type or --apache-extensions)", name)
+			}
+		case types.Uint64:
+			if l.opts.apacheExtensions {
+				l.warn(path, "uint64 values above int64 range overflow <ex:i8> of clients")
+			} else {
+				l.warn(path, "uint64 values outside of int32 range are written as <int>, most clients reject them "+
+					"(use smaller type or string)")
+			}
+		case types.Int, types.Int8, types.Int16, types.Int32, types.Uint8, types.Uint16, types.Float32, types.Float64,
+			types.String, types.Bool:
+		default:
+			l.fail(path, "%v is not supported", name)
+		}
+	case *types.Struct:
+		// tuples and unions are checked by generator
+		if tag.Has("tuple") || tag.Has("oneof") {
+			return
+		}
+
+		members := map[string]string{}
+		for i := 0; i < x.NumFields(); i++ {
+			field := x.Field(i)
+			fieldTag := parseTag(x.Tag(i))
+
+			member := field.Name()
+			if fieldTag.Name != "" {
+				member = fieldTag.Name
+			}
+			if other, ok := members[member]; ok {
+				l.fail(path, "fields %v and %v are written as the same member %v", other, field.Name(), member)
+			}
+			members[member] = field.Name()
+
+			// json and yaml tags should mirror wire names, so same types can be reused by REST layers and configs
+			for _, key := range []string{"json", "yaml"} {
+				if mirror, ok := reflect.StructTag(x.Tag(i)).Lookup(key); ok {
+					if mirror = strings.Split(mirror, ",")[0]; mirror != "" && mirror != "-" && mirror != member {
+						l.warn(path+"."+member, "%v tag %q differs from member name", key, mirror)
+					}
+				}
+			}
+
+			l.lintType(path+"."+member, field.Type(), fieldTag)
+		}
+	case *types.Slice:
+		if elem, ok := x.Elem().(*types.Basic); ok && elem.Kind() == types.Byte {
+			return
+		}
+		l.lintType(path+"[]", x.Elem(), tag)
+	case *types.Array:
+		if elem, ok := x.Elem().(*types.Basic); !ok || elem.Kind() != types.Byte || x.Len() != 16 || !tag.Has("uuid") {
+			l.fail(path, "arrays are not supported, use slice (or [16]byte tagged \",uuid\")")
+		}
+	case *types.Map:
+		if key, ok := x.Key().(*types.Basic); !ok || key.Kind() != types.String {
+			l.fail(path, "maps are not supported, keys of %v cannot be names of struct members", name)
+			return
+		}
+		l.fail(path, "maps are not supported, use inline struct or *xmlrpc.Value")
+	case *types.Pointer:
+		if x.Elem().String() == "github.com/phonkee/go-xmlrpc.Value" || isTextMarshaler(x) {
+			return
+		}
+		if _, ok := knownTypes[x.Elem().String()]; ok {
+			return
+		}
+		l.fail(path, "pointer %v is not supported", name)
+	case *types.Signature:
+		// func(yield func(Item) error) error streams results
+		if x.Params().Len() == 1 && x.Results().Len() == 1 && x.Results().At(0).Type().String() == "error" {
+			if yield, ok := x.Params().At(0).Type().(*types.Signature); ok && yield.Params().Len() == 1 {
+				l.lintType(path+"[]", yield.Params().At(0).Type(), tag)
+				return
+			}
+		}
+		l.fail(path, "%v is not supported", name)
+	case *types.Named:
+		switch x.String() {
+		case "error", "time.Time", "github.com/phonkee/go-xmlrpc.RawValue", "io.Reader":
+			return
+		}
+		if _, ok := knownTypes[x.String()]; ok || isTextMarshaler(x) {
+			return
+		}
+		l.fail(path, "named type %v is not supported, use inline definition or register codec "+
+			"(\",codec\" tag or --codec)", name)
+	default:
+		l.fail(path, "%v is not supported", name)
+	}
+}
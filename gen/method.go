@@ -0,0 +1,208 @@
+package gen
+
+import (
+	"bytes"
+	"go/types"
+	"strings"
+	"time"
+)
+
+func newRPCMethod(service, method string, signature *types.Signature, b *backend, opts paramOptions) *rpcMethod {
+	result := &rpcMethod{
+		Method:    method,
+		Service:   service,
+		Params:    []Param{},
+		Signature: signature,
+	}
+
+	start := 0
+
+	// first param can be context.Context, handler passes request context there
+	if result.Signature.Params().Len() > 0 && result.Signature.Params().At(0).Type().String() == "context.Context" {
+		result.Context = true
+		start = 1
+	}
+
+	// iterate over params
+	for i := start; i < result.Signature.Params().Len(); i++ {
+		result.Params = append(result.Params, getParam(result.Signature.Params().At(i), b, opts))
+	}
+
+	// if length is one only error is returned
+	count := result.Signature.Results().Len()
+	if count == 1 {
+		resultType := result.Signature.Results().At(0).Type().String()
+		if resultType != "error" {
+			Exit("Service method %v.%v should return either (value, error) or just error, got %v", result.Service, result.Method, resultType)
+		}
+		result.ResultError = getParam(result.Signature.Results().At(0), b, opts)
+	} else if count == 2 {
+		resultType := result.Signature.Results().At(1).Type().String()
+		if resultType != "error" {
+			Exit("Service method %v.%v should return either (value, error) or just error", result.Service, result.Method)
+		}
+
+		result.Result = getParam(result.Signature.Results().At(0), b, opts)
+		result.ResultError = getParam(result.Signature.Results().At(1), b, opts)
+	} else {
+		Exit("Service %v method %v must return either 2 variables (result, error) or just error")
+	}
+
+	return result
+}
+
+/*
+rpcMethod holds information about method
+*/
+type rpcMethod struct {
+
+	// Method name
+	Method string
+
+	// Service name
+	Service string
+
+	// Method signature
+	Signature *types.Signature
+
+	// Context is true when method accepts context.Context as first param
+	Context bool
+
+	// Doc is doc comment of method
+	Doc string
+
+	// Timeout of method call set by "//xmlrpc:timeout=30s" directive (0 when not set)
+	Timeout time.Duration
+
+	// Roles of callers allowed to call method set by "//xmlrpc:roles=admin,editor" directive (empty when not set)
+	Roles []string
+
+	// params
+	Params []Param
+
+	// Result specification
+	Result Param
+
+	// result error
+	ResultError Param
+}
+
+func (r *rpcMethod) HasResult() bool {
+	return r.Result != nil
+}
+
+/*
+FromEtree writes code to get values from xml
+*/
+func (r *rpcMethod) FromEtree(names *VariableNames, element string, resultvar string, errorvar string) string {
+
+	buf := bytes.Buffer{}
+
+	methodParams := []string{}
+
+	if r.Context {
+		methodParams = append(methodParams, "ctx")
+	}
+
+	// walk params only once
+	paramsVar := names.Next("params")
+	if len(r.Params) > 0 {
+		names.renderInto(&buf, `{{.Variable}} := xmlrpc.XPathParams({{.Root}})`, map[string]interface{}{
+			"Root":     element,
+			"Variable": paramsVar,
+		})
+	}
+
+	for i, param := range r.Params {
+
+		newelem := names.Next()
+
+		elemval := param.FromEtree(names, newelem, param.Name(), "err")
+
+		names.renderInto(&buf, `
+			if len({{.Params}}) < {{.Index}} || {{.Params}}[{{.Position}}] == nil {
+				{{.ErrorVar}} = xmlrpc.Errorf(xmlrpc.FaultInvalidParams, "could not find {{.Name}}")
+				return
+			}
+			{{.Variable}} := {{.Params}}[{{.Position}}]
+			{{.Param}}
+
+		`, map[string]interface{}{
+			"Params":   paramsVar,
+			"Variable": newelem,
+			"Index":    i + 1,
+			"Position": i,
+			"Param":    elemval,
+			"ErrorVar": errorvar,
+			"Result":   r.Result,
+			"Name":     param.Name(),
+		})
+
+		methodParams = append(methodParams, param.Name())
+	}
+
+	names.renderInto(&buf, `
+		// If following method call fails there are 2 possible reasons:
+		// 1. you have either changed method signature or you deleted method. Please re-run "go generate"
+		// 2. you have probably found a bug and you should file issue on github.
+
+		{{if .ResultVar}}
+			var {{.ResultVar}} {{.Result.Type}}
+			{{.ResultVar}}, {{.ErrorVar}} = s.{{.Method}}({{.Params}})
+		{{else}}
+			{{.ErrorVar}} = s.{{.Method}}({{.Params}})
+		{{end}}
+		`, map[string]interface{}{
+		"Service":   r.Service,
+		"Method":    r.Method,
+		"Params":    strings.Join(methodParams, ", "),
+		"ResultVar": resultvar,
+		"ErrorVar":  errorvar,
+		"Result":    r.Result,
+	})
+
+	return buf.String()
+}
+
+/*
+WireTypes returns xmlrpc types of method, result type is first followed by types of params.
+Methods that return only error have "nil" result type.
+*/
+func (r *rpcMethod) WireTypes() []string {
+	result := []string{"nil"}
+	if r.Result != nil {
+		result[0] = wireType(r.Result)
+	}
+	for _, param := range r.Params {
+		result = append(result, wireType(param))
+	}
+	return result
+}
+
+/*
+wireType returns xmlrpc type of param as used in system.methodSignature
+*/
+func wireType(p Param) string {
+	switch param := p.(type) {
+	case WireParam:
+		return param.Wire()
+	case *boolParam:
+		return "boolean"
+	case *intParam:
+		return "int"
+	case *floatParam:
+		return "double"
+	case *structParam, *lazyStructParam:
+		return "struct"
+	case *sliceParam, *streamParam, *tupleParam:
+		return "array"
+	case *bytesParam, *readerParam:
+		return "base64"
+	case *timeParam:
+		return "dateTime.iso8601"
+	case *rawParam, *valueParam, *codecParam:
+		return "undef"
+	default:
+		return "string"
+	}
+}
@@ -0,0 +1,141 @@
+package gen
+
+import (
+	"fmt"
+	"go/format"
+	"go/types"
+	"strings"
+)
+
+/*
+mockMethod holds go code fragments of mocked method
+*/
+type mockMethod struct {
+	// Signature of method without name (params and results)
+	Signature string
+
+	// Args are param names recorded by mock
+	Args string
+
+	// Call are param names passed to programmed func
+	Call string
+
+	// Result is type of result value (empty when method returns only error)
+	Result string
+}
+
+/*
+mockMethod returns go code fragments of mocked method
+*/
+func (g *generator) mockMethod(method *rpcMethod) mockMethod {
+	signature := method.Signature
+	params, args, call := []string{}, []string{}, []string{}
+
+	for i := 0; i < signature.Params().Len(); i++ {
+		variable := signature.Params().At(i)
+
+		name := variable.Name()
+		if name == "" || name == "_" {
+			name = fmt.Sprintf("p%d", i)
+		}
+
+		typ := typeString(variable.Type(), g.pkg)
+		args = append(args, name)
+		if signature.Variadic() && i == signature.Params().Len()-1 {
+			typ = "..." + typeString(variable.Type().(*types.Slice).Elem(), g.pkg)
+			call = append(call, name+"...")
+		} else {
+			call = append(call, name)
+		}
+		params = append(params, name+" "+typ)
+	}
+
+	result := mockMethod{
+		Args: strings.Join(args, ", "),
+		Call: strings.Join(call, ", "),
+	}
+
+	if signature.Results().Len() == 2 {
+		result.Result = typeString(signature.Results().At(0).Type(), g.pkg)
+		result.Signature = fmt.Sprintf("(%v) (%v, error)", strings.Join(params, ", "), result.Result)
+	} else {
+		result.Signature = fmt.Sprintf("(%v) error", strings.Join(params, ", "))
+	}
+
+	return result
+}
+
+/*
+FormatMocks returns formatted source code with interface and mock of every service. Mock records calls and returns
+results of programmable funcs, so code that uses services can be tested without mocking framework.
+*/
+func (g *generator) FormatMocks() []byte {
+	g.buf.Reset()
+
+	g.writeHeader()
+
+	g.WriteTemplate(`
+	package {{.Package}}
+	import (
+		{{range .Imports}}"{{.}}"
+		{{end -}}
+		xmlrpc "github.com/phonkee/go-xmlrpc"
+	)
+
+	{{range $service, $methods := .Services}}
+		/*
+		{{$service}}Interface has all rpc methods of {{$service}}, accept it where {{$service}}Mock should be usable
+		*/
+		type {{$service}}Interface interface { {{range $methods}}
+			{{.Method}}{{(mock .).Signature}}{{end}}
+		}
+
+		var (
+			_ {{$service}}Interface = (*{{$service}})(nil)
+			_ {{$service}}Interface = (*{{$service}}Mock)(nil)
+		)
+
+		/*
+		{{$service}}Mock is mock of {{$service}}, calls are recorded and results are returned by <Method>Func fields
+		(zero value and nil error when func is not set)
+		*/
+		type {{$service}}Mock struct {
+			xmlrpc.MockRecorder
+			{{range $methods}}
+			{{.Method}}Func func{{(mock .).Signature}}{{end}}
+		}
+
+		{{range $methods}}{{$mock := mock .}}
+			/*
+			{{.Method}} records call and returns result of {{.Method}}Func
+			*/
+			func (m *{{$service}}Mock) {{.Method}}{{$mock.Signature}} {
+				m.Record("{{.Method}}"{{if $mock.Args}}, {{$mock.Args}}{{end}})
+				if m.{{.Method}}Func != nil {
+					return m.{{.Method}}Func({{$mock.Call}})
+				}
+				{{if $mock.Result -}}
+					var result {{$mock.Result}}
+					return result, nil
+				{{- else -}}
+					return nil
+				{{- end}}
+			}
+		{{end}}
+	{{end}}
+	`, map[string]interface{}{
+		"Services": g.services,
+		"Package":  g.pkg.Name(),
+		"Imports":  g.imports,
+	})
+
+	src, err := g.removeUnusedImports(g.buf.Bytes())
+	if err == nil {
+		src, err = format.Source(src)
+	}
+	if err != nil {
+		fmt.Printf("warning: internal error: invalid Go generated: %s\n", err)
+		return g.buf.Bytes()
+	}
+	return src
+}
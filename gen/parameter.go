@@ -0,0 +1,1603 @@
+package gen
+
+import (
+	"bytes"
+	"go/token"
+	"go/types"
+	"sort"
+	"strconv"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/phonkee/go-xmlrpc"
+)
+
+/*
+New implementation of parameter
+*/
+type Param interface {
+	// GetName returns code name
+	Name() string
+
+	// GetType returns type
+	Type() string
+
+	// Writes Field
+	FromEtree(names *VariableNames, element string, resultvar string, errvar string) string
+
+	// Writes param to element
+	ToEtree(names *VariableNames, element string, resultvar string, errvar string) string
+}
+
+/*
+responseParam is result Param that writes whole response by itself (streamed results), backend is not used
+*/
+type responseParam interface {
+	Param
+
+	// Response writes code that assigns io.WriterTo to "res" variable
+	Response(names *VariableNames, resultvar string, errvar string) string
+}
+
+/*
+getParam returns appropriate param based on given variable, values are written with given backend and decoded
+with given options
+*/
+func getParam(variable *types.Var, b *backend, opts paramOptions) Param {
+	// custom types are encoded and decoded by codecs registered at runtime (see codec.go)
+	if opts.codec || opts.codecTypes[typeString(variable.Type(), variable.Pkg())] || isValueMarshaler(variable.Type()) {
+		return newCodecParam(variable, b, opts)
+	}
+
+	// strings tagged ",max=N" or ",pattern=" are validated
+	checkStringTags(variable, opts)
+
+	// types supported by registered factories (see paramfactory.go)
+	if param, ok := newFactoryParam(variable, b); ok {
+		return param
+	}
+
+	switch x := variable.Type().(type) {
+	case *types.Basic:
+		bitSize := 0
+		unsigned := false
+		switch x.Kind() {
+		case types.Int, types.Int8, types.Int16, types.Int32, types.Int64,
+			types.Uint, types.Uint8, types.Uint16, types.Uint32, types.Uint64:
+			bitSize = 0
+			unsigned = false
+			switch x.Kind() {
+			case types.Uint, types.Uint8, types.Uint16, types.Uint32, types.Uint64:
+				unsigned = true
+			}
+			switch x.Kind() {
+			case types.Int8, types.Uint8:
+				bitSize = 8
+			case types.Int16, types.Uint16:
+				bitSize = 16
+			case types.Int32, types.Uint32:
+				bitSize = 32
+			case types.Int64, types.Uint64:
+				bitSize = 64
+			}
+			return newIntParam(variable.Name(), bitSize, unsigned, b, opts)
+		case types.Float32:
+			return newFloatParam(variable.Name(), 32, b, opts)
+		case types.Float64:
+			return newFloatParam(variable.Name(), 64, b, opts)
+		case types.String:
+			return newStringParam(variable.Name(), b, opts)
+		case types.Bool:
+			return newBoolParam(variable.Name(), b, opts)
+		}
+	case *types.Struct:
+		// struct tagged ",tuple" is array
+		if opts.tuple {
+			return newTupleParam(variable, b, opts)
+		}
+
+		// struct tagged ",oneof" is one of its variants
+		if opts.oneof {
+			return newUnionParam(variable, b, opts)
+		}
+		return newStructParam(variable, b, opts)
+	case *types.Pointer:
+		// *xmlrpc.Value is dynamic value
+		if x.Elem().String() == "github.com/phonkee/go-xmlrpc.Value" {
+			return newValueParam(variable.Name(), b, opts)
+		}
+
+		// *xmlrpc.LazyStruct is struct decoded on demand
+		if x.Elem().String() == "github.com/phonkee/go-xmlrpc.LazyStruct" {
+			return newLazyStructParam(variable.Name(), b)
+		}
+
+		if known, ok := knownTypes[x.Elem().String()]; ok {
+			return newKnownParam(variable, known, b)
+		}
+
+		// pointers to text marshalers (*big.Int, *big.Float...)
+		if isTextMarshaler(x) {
+			return newTextParam(variable, b)
+		}
+	case *types.Array:
+		// [16]byte tagged ",uuid" is uuid
+		if elem, ok := x.Elem().(*types.Basic); ok && elem.Kind() == types.Byte && x.Len() == 16 && opts.uuid {
+			return newUUIDParam(variable.Name(), b)
+		}
+		Exit("array")
+	case *types.Slice:
+		// []byte is base64
+		if elem, ok := x.Elem().(*types.Basic); ok && elem.Kind() == types.Byte {
+			return newBytesParam(variable.Name(), b)
+		}
+
+		v := types.NewVar(variable.Pos(), variable.Pkg(), variable.Name(), x.Elem())
+		sliceElemParam := getParam(v, b, opts)
+		return newSliceParam(variable.Name(), typeString(x.Elem(), variable.Pkg()), sliceElemParam, b, opts)
+	case *types.Signature:
+		// func(yield func(Item) error) error streams array of items
+		if x.Params().Len() == 1 && x.Results().Len() == 1 && x.Results().At(0).Type().String() == "error" {
+			if yield, ok := x.Params().At(0).Type().(*types.Signature); ok && yield.Params().Len() == 1 &&
+				yield.Results().Len() == 1 && yield.Results().At(0).Type().String() == "error" {
+
+				// items are always written with writer backend directly to stream
+				item := yield.Params().At(0)
+				v := types.NewVar(item.Pos(), item.Pkg(), "item", item.Type())
+				return newStreamParam(variable.Name(), variable.Type().String(), getParam(v, backends["writer"], opts))
+			}
+		}
+	case *types.Named:
+		// first we check for error
+		if variable.Type().String() == "error" {
+			return newErrorParam("err")
+		}
+
+		// time.Time is dateTime.iso8601 (see DateTimePolicy)
+		if variable.Type().String() == "time.Time" {
+			return newTimeParam(variable.Name(), b, opts)
+		}
+
+		// xmlrpc.RawValue is kept unparsed
+		if variable.Type().String() == "github.com/phonkee/go-xmlrpc.RawValue" {
+			return newRawParam(variable.Name(), b)
+		}
+
+		// io.Reader is base64 decoded/encoded in chunks
+		if variable.Type().String() == "io.Reader" {
+			return newReaderParam(variable.Name())
+		}
+
+		if known, ok := knownTypes[x.String()]; ok {
+			return newKnownParam(variable, known, b)
+		}
+
+		// types that marshal to text (decimals, net.IP, netip.Addr...) are transferred as string
+		if isTextMarshaler(x) {
+			return newTextParam(variable, b)
+		}
+
+		// all other is unsupported
+		Exit("No support for named parameters. use inline definitions.")
+	default:
+		// pass
+	}
+	Exit("not supported param: %v", variable.Type().String())
+
+	return nil
+}
+
+/*
+newBoolParam returns boolParam instance (Param implementation for type bool)
+*/
+func newBoolParam(name string, b *backend, opts paramOptions) Param {
+	return &boolParam{
+		name:    name,
+		backend: b,
+		coerce:  opts.coerce,
+	}
+}
+
+/*
+boolParam - Param implementation of boolean values
+*/
+type boolParam struct {
+	name    string
+	backend *backend
+	coerce  bool
+}
+
+func (p *boolParam) Name() string { return p.name }
+func (p *boolParam) Type() string { return "bool" }
+func (p *boolParam) FromEtree(names *VariableNames, element string, resultvar string, errvar string) string {
+	buf := bytes.Buffer{}
+	names.renderInto(&buf, `
+	var {{.Varname}} {{.Type}}
+	if {{.Varname}}, {{.ErrorVar}} = xmlrpc.{{.ParseFunc}}({{.Element}}, "{{.Name}}"); {{.ErrorVar}} != nil {
+		return
+	}
+	`, map[string]interface{}{
+		"Element":   element,
+		"ErrorVar":  errvar,
+		"Type":      p.Type(),
+		"Varname":   resultvar,
+		"Name":      p.name,
+		"ParseFunc": parseFunc("Bool", p.coerce),
+	})
+
+	return buf.String()
+}
+func (p *boolParam) ToEtree(names *VariableNames, element string, resultvar string, errvar string) string {
+	return p.backend.scalar(names, element, "boolean", "xmlrpc.FormatBool("+resultvar+")")
+}
+
+/*
+newIntParam returns new intParam (Param) instance
+*/
+func newIntParam(name string, bitSize int, unsigned bool, b *backend, opts paramOptions) Param {
+	return &intParam{
+		name:     name,
+		bitSize:  bitSize,
+		unsigned: unsigned,
+		backend:  b,
+		coerce:   opts.coerce,
+		apache:   opts.apacheExtensions,
+	}
+}
+
+/*
+intParam is Param implementation covering all integers
+*/
+type intParam struct {
+	bitSize  int
+	name     string
+	typ      string
+	unsigned bool
+	backend  *backend
+	coerce   bool
+	apache   bool
+}
+
+/*
+Name returns name of param
+*/
+func (i *intParam) Name() string {
+	return i.name
+}
+
+/*
+Type returns type of param
+*/
+func (i *intParam) Type() string {
+	result := "int"
+
+	if i.unsigned {
+		result = "u" + result
+	}
+
+	if i.bitSize > 0 {
+		result += strconv.Itoa(i.bitSize)
+	}
+
+	return result
+}
+
+func (i *intParam) getParseFunc() string {
+	return parseFunc("Int", i.coerce)
+}
+
+/*
+sized returns whether integer is decoded by bit size aware helpers (sized and unsigned integers) that detect
+overflow of its type
+*/
+func (i *intParam) sized() bool {
+	return i.bitSize > 0 || i.unsigned
+}
+
+func (i *intParam) FromEtree(names *VariableNames, element string, resultvar string, errvar string) string {
+	buf := bytes.Buffer{}
+
+	if i.sized() {
+		kind, tmpType := "Int", "int64"
+		if i.unsigned {
+			kind, tmpType = "Uint", "uint64"
+		}
+
+		names.renderInto(&buf, `
+		{{$tmp := GenerateVariableName "int" }}
+		var {{$tmp}} {{.TmpType}}
+		if {{$tmp}}, {{.ErrorVar}} = xmlrpc.{{.ParseFunc}}({{.Element}}, "{{.Name}}", {{.BitSize}}); {{.ErrorVar}} != nil {
+			return
+		}
+		{{.Varname}} := {{.Type}}({{$tmp}})
+		`, map[string]interface{}{
+			"Element":   element,
+			"ErrorVar":  errvar,
+			"Type":      i.Type(),
+			"TmpType":   tmpType,
+			"Varname":   resultvar,
+			"Name":      i.Name(),
+			"BitSize":   i.bitSize,
+			"ParseFunc": parseFunc(kind, i.coerce) + "Bits",
+		})
+
+		return buf.String()
+	}
+
+	names.renderInto(&buf, `
+	var {{.Varname}} {{.Type}}
+	if {{.Varname}}, {{.ErrorVar}} = xmlrpc.{{.ParseFunc}}({{.Element}}, "{{.Name}}"); {{.ErrorVar}} != nil {
+		return
+	}`, map[string]interface{}{
+		"Element":   element,
+		"ErrorVar":  errvar,
+		"Type":      i.Type(),
+		"Varname":   resultvar,
+		"Name":      i.Name(),
+		"ParseFunc": i.getParseFunc(),
+	})
+
+	return buf.String()
+}
+
+func (i *intParam) ToEtree(names *VariableNames, element string, resultvar string, errvar string) string {
+	text := "strconv.FormatInt(int64(" + resultvar + "), 10)"
+	if i.unsigned {
+		text = "strconv.FormatUint(uint64(" + resultvar + "), 10)"
+	}
+
+	// 64 bit integers are ex:i8 with Apache extensions
+	if i.apache && i.bitSize == 64 {
+		return i.backend.render(names, "raw", map[string]interface{}{
+			"Element":   element,
+			"ErrorVar":  errvar,
+			"ResultVar": `xmlrpc.ApacheExtension("i8", ` + text + `)`,
+		})
+	}
+
+	if i.sized() {
+		return i.backend.scalar(names, element, "int", text)
+	}
+	return i.backend.scalar(names, element, "int", "strconv.Itoa(int("+resultvar+"))")
+}
+
+/*
+newFloatParam returns floatParam (Param implementation for float32 and float64)
+*/
+func newFloatParam(name string, bitSize int, b *backend, opts paramOptions) Param {
+	if err := checkDoubleFormat(opts.doubleFormat, opts.doublePrecision); err != nil {
+		Exit("%v: %v", name, err)
+	}
+	return &floatParam{
+		name:      name,
+		bitSize:   bitSize,
+		backend:   b,
+		coerce:    opts.coerce,
+		lenient:   opts.lenientDoubles,
+		nonFinite: opts.nonFiniteDoubles,
+		format:    opts.doubleFormat,
+		precision: opts.doublePrecision,
+	}
+}
+
+/*
+floatParam is Param implementation of doubles
+*/
+type floatParam struct {
+	name      string
+	bitSize   int
+	backend   *backend
+	coerce    bool
+	lenient   bool
+	nonFinite bool
+	format    string
+	precision string
+}
+
+func (f *floatParam) Name() string { return f.name }
+func (f *floatParam) Type() string { return "float" + strconv.Itoa(f.bitSize) }
+
+func (f *floatParam) getParseFunc() string {
+	// coercion and lenient parsing are independent
+	result := parseFunc("Double", f.coerce)
+	if f.lenient {
+		result += "Lenient"
+	}
+	return result
+}
+
+func (f *floatParam) FromEtree(names *VariableNames, element string, resultvar string, errvar string) string {
+	buf := bytes.Buffer{}
+	names.renderInto(&buf, `
+	{{$tmp := GenerateVariableName "double" }}
+	var {{$tmp}} float64
+	if {{$tmp}}, {{.ErrorVar}} = xmlrpc.{{.ParseFunc}}({{.Element}}, "{{.Name}}"); {{.ErrorVar}} != nil {
+		{{if .NonFinite}}
+			// NaN and infinities
+			if {{$tmp}}, {{.ErrorVar}} = xmlrpc.XPathValueGetNonFiniteDouble({{.Element}}, "{{.Name}}"); {{.ErrorVar}} != nil {
+				return
+			}
+		{{else}}
+			return
+		{{end}}
+	}
+	{{.Varname}} := {{.Type}}({{$tmp}})
+	`, map[string]interface{}{
+		"Element":   element,
+		"ErrorVar":  errvar,
+		"Type":      f.Type(),
+		"Varname":   resultvar,
+		"Name":      f.name,
+		"ParseFunc": f.getParseFunc(),
+		"NonFinite": f.nonFinite,
+	})
+
+	return buf.String()
+}
+
+func (f *floatParam) ToEtree(names *VariableNames, element string, resultvar string, errvar string) string {
+	buf := bytes.Buffer{}
+
+	// results are unnamed
+	name := f.name
+	if name == "" {
+		name = "result"
+	}
+
+	if !f.nonFinite {
+		names.renderInto(&buf, `
+		if {{.ErrorVar}} = xmlrpc.FiniteDouble(float64({{.ResultVar}}), "{{.Name}}"); {{.ErrorVar}} != nil {
+			return
+		}
+		`, map[string]interface{}{
+			"ErrorVar":  errvar,
+			"ResultVar": resultvar,
+			"Name":      name,
+		})
+	}
+	text := "xmlrpc.FormatDouble(float64(" + resultvar + "), " + strconv.Itoa(f.bitSize) + ")"
+	if f.format != "f" || f.precision != "-1" {
+		text = "xmlrpc.FormatDoubleWith(float64(" + resultvar + "), '" + f.format + "', " + f.precision + ", " + strconv.Itoa(f.bitSize) + ")"
+	}
+	buf.WriteString(f.backend.scalar(names, element, "double", text))
+
+	return buf.String()
+}
+
+func newStructParam(variable *types.Var, b *backend, opts paramOptions) Param {
+	strukt := variable.Type().(*types.Struct)
+
+	result := &structParam{
+		name:         variable.Name(),
+		typ:          typeString(variable.Type(), variable.Pkg()),
+		params:       make([]*structField, 0, strukt.NumFields()),
+		backend:      b,
+		lenientEmpty: opts.lenientEmpty,
+		duplicates:   opts.duplicateMembers,
+		fold:         opts.foldMembers,
+	}
+
+	for i := 0; i < strukt.NumFields(); i++ {
+		field := strukt.Field(i)
+		tag := parseTag(strukt.Tag(i))
+
+		// member name can be changed by tag
+		name := field.Name()
+		if tag.Name != "" {
+			name = tag.Name
+		}
+
+		v := types.NewVar(field.Pos(), field.Pkg(), name, field.Type())
+		result.params = append(result.params, &structField{
+			Param:    getParam(v, b, opts.withTag(tag)),
+			Field:    field.Name(),
+			Required: tag.Has("required"),
+			order:    tag.Options["order"],
+		})
+		result.required = result.required || tag.Has("required")
+	}
+
+	sortMembers(result.params, opts.memberOrder)
+
+	if result.fold {
+		checkFoldedMembers(result.name, result.params)
+	}
+
+	return result
+}
+
+/*
+structField is Param of struct field, Name is name of struct member and Field is name of go field. Required
+members (",required" tag) must be present in decoded struct.
+*/
+type structField struct {
+	Param
+	Field    string
+	Required bool
+
+	// order is position of member given by ",order=N" tag
+	order string
+}
+
+/*
+sortMembers sorts struct members to order in which they are written. Members with ",order=N" tag go first
+(ordered by N), then other members in given order (go field order or alphabetical).
+*/
+func sortMembers(fields []*structField, order string) {
+	position := func(field *structField) int {
+		if field.order == "" {
+			return -1
+		}
+		result, err := strconv.Atoi(field.order)
+		if err != nil {
+			Exit("invalid order %v of struct field %v", field.order, field.Field)
+		}
+		return result
+	}
+
+	sort.SliceStable(fields, func(i, j int) bool {
+		pi, pj := position(fields[i]), position(fields[j])
+		switch {
+		case pi >= 0 && pj >= 0:
+			return pi < pj
+		case pi >= 0 || pj >= 0:
+			return pi >= 0
+		case order == MemberOrderAlpha:
+			return fields[i].Name() < fields[j].Name()
+		}
+		return false
+	})
+}
+
+type structParam struct {
+	name         string
+	typ          string
+	params       []*structField
+	backend      *backend
+	lenientEmpty bool
+	duplicates   string
+	fold         bool
+
+	// required is whether some field is tagged ",required"
+	required bool
+}
+
+func (p *structParam) Name() string { return p.name }
+func (p *structParam) Type() string { return p.typ }
+func (p *structParam) FromEtree(names *VariableNames, element string, resultvar string, errvar string) string {
+	buf := bytes.Buffer{}
+
+	names.renderInto(&buf, `
+	// rendering struct
+	{{.ResultVar}} := {{.Type}}{}
+
+	{{$members := GenerateVariableName "members" }}
+	{{$member := GenerateVariableName "member" }}
+	{{$nameVar := GenerateVariableName "name" }}
+	{{$valueVar := GenerateVariableName "value" }}
+
+	var {{$members}} []*xmlrpc.Element
+	if {{$members}}, {{.ErrorVar}} = xmlrpc.{{.GetFunc}}({{.Element}}, "{{.Name}}"); {{.ErrorVar}} != nil {
+		return
+	}
+	{{if ne .Duplicates "last"}}
+		// duplicate members are removed ("{{.Duplicates}}" policy)
+		if {{$members}}, {{.ErrorVar}} = xmlrpc.XPathUniqueMembers({{$members}}, "{{.Name}}", "{{.Duplicates}}", {{.Fold}}); {{.ErrorVar}} != nil {
+			return
+		}
+	{{end}}
+
+	{{$seen := GenerateVariableName "seen" }}
+	{{if .Required}}
+		// required members that were decoded
+		var {{$seen}} [{{len .Params}}]bool
+	{{end}}
+
+	// Lets iterate over given members (single pass over struct).
+	for _, {{$member}} := range {{$members}} {
+		// stop decoding when call is cancelled
+		if {{.ErrorVar}} = ctx.Err(); {{.ErrorVar}} != nil {
+			return
+		}
+
+		var (
+			{{$nameVar}} string
+			{{$valueVar}} *xmlrpc.Element
+		)
+		if {{$nameVar}}, {{$valueVar}}, {{.ErrorVar}} = xmlrpc.XPathStructMember({{$member}}); {{.ErrorVar}} != nil {
+			return
+		}
+
+		// switch over param names (over all params)
+		switch {{if .Fold}}xmlrpc.FoldMemberName({{$nameVar}}){{else}}{{$nameVar}}{{end}} {
+			{{range $index,$param := .Params}}
+				case "{{MemberName $param.Name}}": {{$paramTmp := GenerateVariableName }}
+				{{$param.FromEtree names $valueVar $paramTmp $.ErrorVar }}
+
+				// Assign to variable (for pointer support we can provide it here
+				{{$.ResultVar}}.{{$param.Field}} = {{$paramTmp}}
+				{{if $param.Required}}{{$seen}}[{{$index}}] = true{{end}}{{end}}
+		}
+	}
+	{{if .Required}}
+		// all missing required members are reported at once
+		{{$missing := GenerateVariableName "missing" }}
+		var {{$missing}} []string
+		{{range $index,$param := .Params}}{{if $param.Required}}
+			if !{{$seen}}[{{$index}}] {
+				{{$missing}} = append({{$missing}}, "{{$param.Name}}")
+			}
+		{{end}}{{end}}
+		if {{.ErrorVar}} = xmlrpc.MissingMembers("{{.Name}}", {{$missing}}); {{.ErrorVar}} != nil {
+			return
+		}
+	{{end}}
+	`, map[string]interface{}{
+		"Type":       p.Type(),
+		"ResultVar":  resultvar,
+		"Element":    element,
+		"ErrorVar":   errvar,
+		"Name":       p.name,
+		"Params":     p.params,
+		"GetFunc":    lenientFunc("XPathValueGetStruct", p.lenientEmpty),
+		"Duplicates": p.duplicates,
+		"Fold":       p.fold,
+		"Required":   p.required,
+	}, template.FuncMap{
+		"MemberName": func(name string) string {
+			if p.fold {
+				return xmlrpc.FoldMemberName(name)
+			}
+			return name
+		},
+	})
+
+	return buf.String()
+}
+func (p *structParam) ToEtree(names *VariableNames, element string, resultvar string, errvar string) string {
+	return p.backend.render(names, "struct", map[string]interface{}{
+		"Element":   element,
+		"ErrorVar":  errvar,
+		"Params":    p.params,
+		"ResultVar": resultvar,
+	})
+}
+
+func newSliceParam(name string, typ string, obj Param, b *backend, opts paramOptions) Param {
+	return &sliceParam{
+		name:         name,
+		typ:          typ,
+		object:       obj,
+		backend:      b,
+		lenientEmpty: opts.lenientEmpty,
+	}
+}
+
+type sliceParam struct {
+	name         string
+	typ          string
+	object       Param
+	backend      *backend
+	lenientEmpty bool
+}
+
+func (p *sliceParam) Name() string { return p.name }
+func (p *sliceParam) Type() string { return "[]" + p.typ }
+func (p *sliceParam) FromEtree(names *VariableNames, element string, resultvar string, errvar string) string {
+
+	buf := bytes.Buffer{}
+	names.renderInto(&buf, `
+	// This is slice implementation of {{.ResultVar}}
+	{{$values := GenerateVariableName "values"}}
+	{{$memberVar := GenerateVariableName "member"}}
+
+	var {{$values}} []*xmlrpc.Element
+	if {{$values}}, {{.ErrVar}} = xmlrpc.{{.GetFunc}}({{.Element}}, "{{.Name}}"); {{.ErrVar}} != nil {
+		return
+	}
+	// only preview of array is decoded when context limits arrays
+	{{$values}} = xmlrpc.LimitArray(ctx, {{$values}})
+
+	{{.ResultVar}} := make({{.Type}}, 0, len({{$values}}))
+
+	// Lets iterate over given members.
+	for _, {{$memberVar}} := range {{$values}} {
+		// stop decoding when call is cancelled
+		if {{.ErrVar}} = ctx.Err(); {{.ErrVar}} != nil {
+			return
+		}
+
+		{{$targetName := GenerateVariableName "value"}}
+		{{.Object.FromEtree names $memberVar $targetName .ErrVar }}
+		{{.ResultVar}} = append({{.ResultVar}}, {{$targetName}})
+	}
+	`, map[string]interface{}{
+		"Element":   element,
+		"ErrVar":    errvar,
+		"ResultVar": resultvar,
+		"Name":      p.name,
+		"Type":      p.Type(),
+		"Object":    p.object,
+		"GetFunc":   lenientFunc("XPathValueGetArray", p.lenientEmpty),
+	})
+
+	return buf.String()
+}
+func (p *sliceParam) ToEtree(names *VariableNames, element string, resultvar string, errvar string) string {
+	return p.backend.render(names, "slice", map[string]interface{}{
+		"Element":   element,
+		"ErrorVar":  errvar,
+		"Object":    p.object,
+		"ResultVar": resultvar,
+	})
+}
+
+/*
+newErrorParam returns new errorParam (Param implementation for error)
+*/
+func newErrorParam(name string) Param {
+	return &errorParam{
+		name: name,
+		typ:  "error",
+	}
+}
+
+/*
+errorParam Param implementation for error
+*/
+type errorParam struct {
+	name string
+	typ  string
+}
+
+func (p *errorParam) Name() string { return p.name }
+func (p *errorParam) Type() string { return p.typ }
+func (p *errorParam) FromEtree(names *VariableNames, element string, resultvar string, errvar string) string {
+	return ""
+}
+func (p *errorParam) ToEtree(names *VariableNames, element string, resultvar string, errvar string) string {
+	// errors are not written by generated code, they are returned from Dispatch and handler writes fault
+	return ""
+}
+
+/*
+newStringParam returns new strinParam
+*/
+func newStringParam(name string, b *backend, opts paramOptions) Param {
+	return &stringParam{
+		name:      name,
+		backend:   b,
+		coerce:    opts.coerce,
+		maxLength: opts.maxLength,
+		pattern:   opts.pattern,
+	}
+}
+
+/*
+stringParam is Param imlpementation for string variables
+*/
+type stringParam struct {
+	name      string
+	backend   *backend
+	coerce    bool
+	maxLength string
+	pattern   string
+}
+
+func (p *stringParam) Name() string { return p.name }
+func (p *stringParam) Type() string { return "string" }
+func (p *stringParam) FromEtree(names *VariableNames, element string, resultvar string, errvar string) string {
+	buf := bytes.Buffer{}
+	names.renderInto(&buf, `
+	var {{.Varname}} {{.Type}}
+	if {{.Varname}}, {{.ErrorVar}} = xmlrpc.{{.ParseFunc}}({{.Element}}, "{{.Name}}"); {{.ErrorVar}} != nil {
+		return
+	}
+	{{if .MaxLength}}
+		if {{.ErrorVar}} = xmlrpc.ValidateMaxLength({{.Varname}}, {{.MaxLength}}, "{{.Name}}"); {{.ErrorVar}} != nil {
+			return
+		}
+	{{end}}
+	{{if .Pattern}}
+		if {{.ErrorVar}} = xmlrpc.ValidatePattern({{.Varname}}, {{printf "%q" .Pattern}}, "{{.Name}}"); {{.ErrorVar}} != nil {
+			return
+		}
+	{{end}}
+	`, map[string]interface{}{
+		"Element":   element,
+		"ErrorVar":  errvar,
+		"Type":      p.Type(),
+		"Varname":   resultvar,
+		"Name":      p.name,
+		"ParseFunc": parseFunc("String", p.coerce),
+		"MaxLength": p.maxLength,
+		"Pattern":   p.pattern,
+	})
+
+	return buf.String()
+}
+func (p *stringParam) ToEtree(names *VariableNames, element string, resultvar string, errvar string) string {
+	return p.backend.scalar(names, element, "string", resultvar)
+}
+
+/*
+newBytesParam returns bytesParam (Param implementation for []byte)
+*/
+func newBytesParam(name string, b *backend) Param {
+	return &bytesParam{
+		name:    name,
+		backend: b,
+	}
+}
+
+/*
+bytesParam is Param implementation for []byte, it's transferred as base64
+*/
+type bytesParam struct {
+	name    string
+	backend *backend
+}
+
+func (p *bytesParam) Name() string { return p.name }
+func (p *bytesParam) Type() string { return "[]byte" }
+func (p *bytesParam) FromEtree(names *VariableNames, element string, resultvar string, errvar string) string {
+	buf := bytes.Buffer{}
+	names.renderInto(&buf, `
+	var {{.Varname}} {{.Type}}
+	if {{.Varname}}, {{.ErrorVar}} = xmlrpc.XPathValueGetBase64({{.Element}}, "{{.Name}}"); {{.ErrorVar}} != nil {
+		return
+	}
+	`, map[string]interface{}{
+		"Element":  element,
+		"ErrorVar": errvar,
+		"Type":     p.Type(),
+		"Varname":  resultvar,
+		"Name":     p.name,
+	})
+
+	return buf.String()
+}
+func (p *bytesParam) ToEtree(names *VariableNames, element string, resultvar string, errvar string) string {
+	return p.backend.scalar(names, element, "base64", "xmlrpc.FormatBase64("+resultvar+")")
+}
+
+/*
+newTimeParam returns timeParam (Param implementation for time.Time)
+*/
+func newTimeParam(name string, b *backend, opts paramOptions) Param {
+	if opts.timezone != "" {
+		if _, err := time.LoadLocation(opts.timezone); err != nil {
+			Exit("Unknown time zone %v of %v: %v", opts.timezone, name, err)
+		}
+	}
+
+	return &timeParam{
+		name:     name,
+		backend:  b,
+		timezone: opts.timezone,
+		apache:   opts.apacheExtensions,
+	}
+}
+
+/*
+timeParam is Param implementation for time.Time, it's transferred as dateTime.iso8601 and formatted/parsed by
+DateTimePolicy that handler stores in context
+*/
+type timeParam struct {
+	name     string
+	backend  *backend
+	timezone string
+	apache   bool
+}
+
+func (p *timeParam) Name() string { return p.name }
+func (p *timeParam) Type() string { return "time.Time" }
+func (p *timeParam) FromEtree(names *VariableNames, element string, resultvar string, errvar string) string {
+	buf := bytes.Buffer{}
+	names.renderInto(&buf, `
+	var {{.Varname}} {{.Type}}
+	{{if .Timezone}}
+		if {{.Varname}}, {{.ErrorVar}} = xmlrpc.XPathValueGetTimeIn(ctx, {{.Element}}, "{{.Name}}", "{{.Timezone}}"); {{.ErrorVar}} != nil {
+			return
+		}
+	{{else}}
+		if {{.Varname}}, {{.ErrorVar}} = xmlrpc.XPathValueGetTime(ctx, {{.Element}}, "{{.Name}}"); {{.ErrorVar}} != nil {
+			return
+		}
+	{{end}}
+	`, map[string]interface{}{
+		"Element":  element,
+		"ErrorVar": errvar,
+		"Type":     p.Type(),
+		"Varname":  resultvar,
+		"Name":     p.name,
+		"Timezone": p.timezone,
+	})
+
+	return buf.String()
+}
+func (p *timeParam) ToEtree(names *VariableNames, element string, resultvar string, errvar string) string {
+	if p.apache {
+		return p.backend.render(names, "raw", map[string]interface{}{
+			"Element":   element,
+			"ErrorVar":  errvar,
+			"ResultVar": "xmlrpc.ApacheDateTime(ctx, " + resultvar + ")",
+		})
+	}
+	return p.backend.scalar(names, element, "dateTime.iso8601", "xmlrpc.FormatTime(ctx, "+resultvar+")")
+}
+
+/*
+newTextParam returns textParam (Param implementation for types that implement encoding.TextMarshaler and
+encoding.TextUnmarshaler)
+*/
+func newTextParam(variable *types.Var, b *backend) Param {
+	result := &textParam{
+		name:    variable.Name(),
+		typ:     typeString(variable.Type(), variable.Pkg()),
+		backend: b,
+	}
+
+	if pointer, ok := variable.Type().(*types.Pointer); ok {
+		result.elem = typeString(pointer.Elem(), variable.Pkg())
+	}
+
+	return result
+}
+
+/*
+textParam is transferred as string, value is written by MarshalText and read by UnmarshalText. It's used for
+big numbers (*big.Int, *big.Float, *big.Rat), decimals and other types that marshal to text.
+*/
+type textParam struct {
+	name    string
+	typ     string
+	backend *backend
+
+	// elem is type of pointed value when param is pointer
+	elem string
+}
+
+func (p *textParam) Name() string { return p.name }
+func (p *textParam) Type() string { return p.typ }
+func (p *textParam) FromEtree(names *VariableNames, element string, resultvar string, errvar string) string {
+	buf := bytes.Buffer{}
+	names.renderInto(&buf, `
+	{{if .Elem}}
+		{{.Varname}} := new({{.Elem}})
+		if {{.ErrorVar}} = xmlrpc.XPathValueGetText({{.Element}}, "{{.Name}}", {{.Varname}}); {{.ErrorVar}} != nil {
+			return
+		}
+	{{else}}
+		var {{.Varname}} {{.Type}}
+		if {{.ErrorVar}} = xmlrpc.XPathValueGetText({{.Element}}, "{{.Name}}", &{{.Varname}}); {{.ErrorVar}} != nil {
+			return
+		}
+	{{end}}
+	`, map[string]interface{}{
+		"Element":  element,
+		"ErrorVar": errvar,
+		"Type":     p.Type(),
+		"Elem":     p.elem,
+		"Varname":  resultvar,
+		"Name":     p.name,
+	})
+
+	return buf.String()
+}
+func (p *textParam) ToEtree(names *VariableNames, element string, resultvar string, errvar string) string {
+	buf := bytes.Buffer{}
+	text := names.Next("text")
+
+	names.renderInto(&buf, `
+	var {{.Text}} string
+	if {{.Text}}, {{.ErrorVar}} = xmlrpc.FormatText({{.ResultVar}}); {{.ErrorVar}} != nil {
+		return
+	}
+	`, map[string]interface{}{
+		"ErrorVar":  errvar,
+		"ResultVar": resultvar,
+		"Text":      text,
+	})
+	buf.WriteString(p.backend.scalar(names, element, "string", text))
+
+	return buf.String()
+}
+
+/*
+newCodecParam returns codecParam (Param implementation for types with codec registered by xmlrpc.RegisterCodec),
+types that implement xmlrpc.ValueMarshaler are registered by generated code
+*/
+func newCodecParam(variable *types.Var, b *backend, opts paramOptions) Param {
+	typ := typeString(variable.Type(), variable.Pkg())
+	if isValueMarshaler(variable.Type()) && opts.codecs != nil {
+		opts.codecs.add(typ)
+	}
+
+	return &codecParam{
+		name:    variable.Name(),
+		typ:     typ,
+		backend: b,
+	}
+}
+
+/*
+codecSet is set of types whose codecs are registered by generated code, params are created concurrently
+*/
+type codecSet struct {
+	mutex sync.Mutex
+	types map[string]bool
+}
+
+func (s *codecSet) add(typ string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.types[typ] = true
+}
+
+/*
+sorted returns types in alphabetical order
+*/
+func (s *codecSet) sorted() []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	result := make([]string, 0, len(s.types))
+	for typ := range s.types {
+		result = append(result, typ)
+	}
+	sort.Strings(result)
+	return result
+}
+
+/*
+codecParam is encoded by EncodeCodec and decoded by XPathValueGetCodec, codec is looked up by go type at runtime
+*/
+type codecParam struct {
+	name    string
+	typ     string
+	backend *backend
+}
+
+func (p *codecParam) Name() string { return p.name }
+func (p *codecParam) Type() string { return p.typ }
+func (p *codecParam) FromEtree(names *VariableNames, element string, resultvar string, errvar string) string {
+	buf := bytes.Buffer{}
+	names.renderInto(&buf, `
+	var {{.Varname}} {{.Type}}
+	if {{.ErrorVar}} = xmlrpc.XPathValueGetCodec({{.Element}}, "{{.Name}}", &{{.Varname}}); {{.ErrorVar}} != nil {
+		return
+	}
+	`, map[string]interface{}{
+		"Element":  element,
+		"ErrorVar": errvar,
+		"Type":     p.typ,
+		"Varname":  resultvar,
+		"Name":     p.name,
+	})
+
+	return buf.String()
+}
+func (p *codecParam) ToEtree(names *VariableNames, element string, resultvar string, errvar string) string {
+	buf := bytes.Buffer{}
+	raw := names.Next("raw")
+
+	names.renderInto(&buf, `
+	var {{.Raw}} xmlrpc.RawValue
+	if {{.Raw}}, {{.ErrorVar}} = xmlrpc.EncodeCodec({{.ResultVar}}); {{.ErrorVar}} != nil {
+		return
+	}
+	`, map[string]interface{}{
+		"ErrorVar":  errvar,
+		"ResultVar": resultvar,
+		"Raw":       raw,
+	})
+	buf.WriteString(p.backend.render(names, "raw", map[string]interface{}{
+		"Element":   element,
+		"ErrorVar":  errvar,
+		"ResultVar": raw,
+	}))
+
+	return buf.String()
+}
+
+/*
+newUUIDParam returns uuidParam (Param implementation for [16]byte tagged as uuid)
+*/
+func newUUIDParam(name string, b *backend) Param {
+	return &uuidParam{
+		name:    name,
+		backend: b,
+	}
+}
+
+/*
+uuidParam is [16]byte transferred as canonical uuid string (xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx)
+*/
+type uuidParam struct {
+	name    string
+	backend *backend
+}
+
+func (p *uuidParam) Name() string { return p.name }
+func (p *uuidParam) Type() string { return "[16]byte" }
+func (p *uuidParam) FromEtree(names *VariableNames, element string, resultvar string, errvar string) string {
+	buf := bytes.Buffer{}
+	names.renderInto(&buf, `
+	var {{.Varname}} {{.Type}}
+	if {{.Varname}}, {{.ErrorVar}} = xmlrpc.XPathValueGetUUID({{.Element}}, "{{.Name}}"); {{.ErrorVar}} != nil {
+		return
+	}
+	`, map[string]interface{}{
+		"Element":  element,
+		"ErrorVar": errvar,
+		"Type":     p.Type(),
+		"Varname":  resultvar,
+		"Name":     p.name,
+	})
+
+	return buf.String()
+}
+func (p *uuidParam) ToEtree(names *VariableNames, element string, resultvar string, errvar string) string {
+	return p.backend.scalar(names, element, "string", "xmlrpc.FormatUUID("+resultvar+")")
+}
+
+/*
+knownType is named type transferred as string by runtime helpers. Parse helper is XPathValueGet* that returns
+pointer to value, Format helper accepts pointer to value and returns string.
+*/
+type knownType struct {
+	Parse  string
+	Format string
+}
+
+var (
+	// knownTypes are types from standard library that don't marshal to text, keyed by type (without pointer)
+	knownTypes = map[string]knownType{
+		"net/url.URL":      {Parse: "XPathValueGetURL", Format: "FormatURL"},
+		"net/mail.Address": {Parse: "XPathValueGetMailAddress", Format: "FormatMailAddress"},
+	}
+)
+
+/*
+newKnownParam returns knownParam (Param implementation for knownTypes)
+*/
+func newKnownParam(variable *types.Var, known knownType, b *backend) Param {
+	result := &knownParam{
+		name:    variable.Name(),
+		typ:     typeString(variable.Type(), variable.Pkg()),
+		elem:    typeString(variable.Type(), variable.Pkg()),
+		known:   known,
+		backend: b,
+	}
+
+	if pointer, ok := variable.Type().(*types.Pointer); ok {
+		result.pointer = true
+		result.elem = typeString(pointer.Elem(), variable.Pkg())
+	}
+
+	return result
+}
+
+/*
+knownParam is value of known type (url.URL, mail.Address) transferred as validated string, both value and pointer
+are supported
+*/
+type knownParam struct {
+	name    string
+	typ     string
+	elem    string
+	pointer bool
+	known   knownType
+	backend *backend
+}
+
+func (p *knownParam) Name() string { return p.name }
+func (p *knownParam) Type() string { return p.typ }
+func (p *knownParam) FromEtree(names *VariableNames, element string, resultvar string, errvar string) string {
+	buf := bytes.Buffer{}
+	names.renderInto(&buf, `
+	{{$parsed := GenerateVariableName "parsed"}}
+	var {{$parsed}} *{{.Elem}}
+	if {{$parsed}}, {{.ErrorVar}} = xmlrpc.{{.Known.Parse}}({{.Element}}, "{{.Name}}"); {{.ErrorVar}} != nil {
+		return
+	}
+	{{if .Pointer}}
+		{{.Varname}} := {{$parsed}}
+	{{else}}
+		{{.Varname}} := *{{$parsed}}
+	{{end}}
+	`, map[string]interface{}{
+		"Element":  element,
+		"ErrorVar": errvar,
+		"Varname":  resultvar,
+		"Name":     p.name,
+		"Elem":     p.elem,
+		"Known":    p.known,
+		"Pointer":  p.pointer,
+	})
+
+	return buf.String()
+}
+func (p *knownParam) ToEtree(names *VariableNames, element string, resultvar string, errvar string) string {
+	if !p.pointer {
+		resultvar = "&" + resultvar
+	}
+	return p.backend.scalar(names, element, "string", "xmlrpc."+p.known.Format+"("+resultvar+")")
+}
+
+/*
+newReaderParam returns readerParam (Param implementation for io.Reader)
+*/
+func newReaderParam(name string) Param {
+	return &readerParam{
+		name: name,
+	}
+}
+
+/*
+readerParam is io.Reader transferred as base64. Argument is decoded while service reads it and result is
+encoded to response in chunks, so big uploads/downloads are never held in memory (apart from request document).
+*/
+type readerParam struct {
+	name string
+}
+
+func (p *readerParam) Name() string { return p.name }
+func (p *readerParam) Type() string { return "io.Reader" }
+func (p *readerParam) FromEtree(names *VariableNames, element string, resultvar string, errvar string) string {
+	buf := bytes.Buffer{}
+	names.renderInto(&buf, `
+	var {{.Varname}} {{.Type}}
+	if {{.Varname}}, {{.ErrorVar}} = xmlrpc.XPathValueGetReader({{.Element}}, "{{.Name}}"); {{.ErrorVar}} != nil {
+		return
+	}
+	`, map[string]interface{}{
+		"Element":  element,
+		"ErrorVar": errvar,
+		"Type":     p.Type(),
+		"Varname":  resultvar,
+		"Name":     p.name,
+	})
+
+	return buf.String()
+}
+func (p *readerParam) ToEtree(names *VariableNames, element string, resultvar string, errvar string) string {
+	Exit("io.Reader can be only returned directly from methods, use []byte instead")
+	return ""
+}
+
+/*
+Response writes code that assigns base64 response read from result to "res" variable
+*/
+func (p *readerParam) Response(names *VariableNames, resultvar string, errvar string) string {
+	return "res = xmlrpc.Base64Response(ctx, " + resultvar + ")"
+}
+
+/*
+newStreamParam returns streamParam (Param implementation for iterator results)
+*/
+func newStreamParam(name string, typ string, obj Param) Param {
+	return &streamParam{
+		name:   name,
+		typ:    typ,
+		object: obj,
+	}
+}
+
+/*
+streamParam is result in form func(yield func(Item) error) error. Items are streamed to response one by one
+as array, so whole result never needs to be in memory.
+*/
+type streamParam struct {
+	name   string
+	typ    string
+	object Param
+}
+
+func (p *streamParam) Name() string { return p.name }
+func (p *streamParam) Type() string { return p.typ }
+func (p *streamParam) FromEtree(names *VariableNames, element string, resultvar string, errvar string) string {
+	Exit("streamed values (%v) can be only returned from methods", p.typ)
+	return ""
+}
+func (p *streamParam) ToEtree(names *VariableNames, element string, resultvar string, errvar string) string {
+	Exit("streamed values (%v) can be only returned directly from methods", p.typ)
+	return ""
+}
+
+/*
+Response writes code that assigns streamed response to "res" variable. Once response starts streaming there is
+no way to return fault, so when iterator fails response is left unfinished and client gets invalid document.
+*/
+func (p *streamParam) Response(names *VariableNames, resultvar string, errvar string) string {
+	buf := bytes.Buffer{}
+
+	names.renderInto(&buf, `
+		{{$stream := GenerateVariableName "stream"}}
+		{{$item := GenerateVariableName "item"}}
+		{{$chunk := GenerateVariableName "chunk"}}
+		{{$iterErr := GenerateVariableName "err"}}
+		res = xmlrpc.WriterToFunc(func(w io.Writer) (int64, error) {
+			{{$stream}} := xmlrpc.NewStreamWriter(w)
+			{{$stream}}.Buffer().WriteString("<?xml version=\"1.0\" encoding=\"UTF-8\"?><methodResponse><params><param><value><array><data>")
+			if {{$iterErr}} := {{$stream}}.Flush(); {{$iterErr}} != nil {
+				return {{$stream}}.Result()
+			}
+
+			{{$iterErr}} := {{.ResultVar}}(func({{$item}} {{.Object.Type}}) (err error) {
+				// stop streaming when call is cancelled (e.g. client disconnected)
+				if err = ctx.Err(); err != nil {
+					return
+				}
+
+				{{$chunk}} := {{$stream}}.Buffer()
+				{{$chunk}}.WriteString("<value>")
+				{{.Object.ToEtree names $chunk $item "err"}}
+				{{$chunk}}.WriteString("</value>")
+				return {{$stream}}.Flush()
+			})
+			if {{$iterErr}} != nil {
+				// response stays unfinished so client does not take partial array as valid result
+				n, werr := {{$stream}}.Result()
+				if werr == nil {
+					werr = {{$iterErr}}
+				}
+				return n, werr
+			}
+
+			{{$stream}}.Buffer().WriteString("</data></array></value></param></params></methodResponse>")
+			{{$stream}}.Flush()
+			return {{$stream}}.Result()
+		})
+	`, map[string]interface{}{
+		"ResultVar": resultvar,
+		"ErrorVar":  errvar,
+		"Object":    p.object,
+	})
+
+	return buf.String()
+}
+
+/*
+parseFunc returns name of xpath helper that decodes scalar of given kind ("Int", "Bool", "String"),
+coercing helpers are used when coercion is enabled
+*/
+func parseFunc(kind string, coerce bool) string {
+	if coerce {
+		return "XPathValueCoerce" + kind
+	}
+	return "XPathValueGet" + kind
+}
+
+/*
+newRawParam returns rawParam (Param implementation for xmlrpc.RawValue)
+*/
+func newRawParam(name string, b *backend) Param {
+	return &rawParam{
+		name:    name,
+		backend: b,
+	}
+}
+
+/*
+rawParam is xmlrpc.RawValue, content of value element that is decoded and written verbatim
+*/
+type rawParam struct {
+	name    string
+	backend *backend
+}
+
+func (p *rawParam) Name() string { return p.name }
+func (p *rawParam) Type() string { return "xmlrpc.RawValue" }
+func (p *rawParam) FromEtree(names *VariableNames, element string, resultvar string, errvar string) string {
+	buf := bytes.Buffer{}
+	names.renderInto(&buf, `
+	var {{.ResultVar}} xmlrpc.RawValue
+	if {{.ResultVar}}, {{.ErrorVar}} = xmlrpc.XPathValueGetRaw({{.Element}}, "{{.Name}}"); {{.ErrorVar}} != nil {
+		return
+	}`, map[string]interface{}{
+		"Element":   element,
+		"ErrorVar":  errvar,
+		"ResultVar": resultvar,
+		"Name":      p.name,
+	})
+	return buf.String()
+}
+func (p *rawParam) ToEtree(names *VariableNames, element string, resultvar string, errvar string) string {
+	return p.backend.render(names, "raw", map[string]interface{}{
+		"Element":   element,
+		"ErrorVar":  errvar,
+		"ResultVar": resultvar,
+	})
+}
+
+/*
+newValueParam returns valueParam (Param implementation for *xmlrpc.Value)
+*/
+func newValueParam(name string, b *backend, opts paramOptions) Param {
+	return &valueParam{
+		name:    name,
+		backend: b,
+		apache:  opts.apacheExtensions,
+	}
+}
+
+/*
+valueParam is *xmlrpc.Value, dynamic value of any type. It's written as raw value.
+*/
+type valueParam struct {
+	name    string
+	backend *backend
+	apache  bool
+}
+
+func (p *valueParam) Name() string { return p.name }
+func (p *valueParam) Type() string { return "*xmlrpc.Value" }
+func (p *valueParam) FromEtree(names *VariableNames, element string, resultvar string, errvar string) string {
+	buf := bytes.Buffer{}
+	names.renderInto(&buf, `
+	var {{.ResultVar}} *xmlrpc.Value
+	if {{.ResultVar}}, {{.ErrorVar}} = xmlrpc.XPathValueGetValue({{.Element}}, "{{.Name}}"); {{.ErrorVar}} != nil {
+		return
+	}`, map[string]interface{}{
+		"Element":   element,
+		"ErrorVar":  errvar,
+		"ResultVar": resultvar,
+		"Name":      p.name,
+	})
+	return buf.String()
+}
+func (p *valueParam) ToEtree(names *VariableNames, element string, resultvar string, errvar string) string {
+	raw := resultvar + ".Raw()"
+	if p.apache {
+		raw = resultvar + ".RawApache()"
+	}
+	return p.backend.render(names, "raw", map[string]interface{}{
+		"Element":   element,
+		"ErrorVar":  errvar,
+		"ResultVar": raw,
+	})
+}
+
+/*
+newLazyStructParam returns lazyStructParam (Param implementation for *xmlrpc.LazyStruct)
+*/
+func newLazyStructParam(name string, b *backend) Param {
+	return &lazyStructParam{
+		name:    name,
+		backend: b,
+	}
+}
+
+/*
+lazyStructParam is *xmlrpc.LazyStruct, struct with members decoded when they are accessed. It's written as raw
+value.
+*/
+type lazyStructParam struct {
+	name    string
+	backend *backend
+}
+
+func (p *lazyStructParam) Name() string { return p.name }
+func (p *lazyStructParam) Type() string { return "*xmlrpc.LazyStruct" }
+func (p *lazyStructParam) FromEtree(names *VariableNames, element string, resultvar string, errvar string) string {
+	buf := bytes.Buffer{}
+	names.renderInto(&buf, `
+	var {{.ResultVar}} *xmlrpc.LazyStruct
+	if {{.ResultVar}}, {{.ErrorVar}} = xmlrpc.XPathValueGetLazyStruct({{.Element}}, "{{.Name}}"); {{.ErrorVar}} != nil {
+		return
+	}`, map[string]interface{}{
+		"Element":   element,
+		"ErrorVar":  errvar,
+		"ResultVar": resultvar,
+		"Name":      p.name,
+	})
+	return buf.String()
+}
+func (p *lazyStructParam) ToEtree(names *VariableNames, element string, resultvar string, errvar string) string {
+	return p.backend.render(names, "raw", map[string]interface{}{
+		"Element":   element,
+		"ErrorVar":  errvar,
+		"ResultVar": resultvar + ".Raw()",
+	})
+}
+
+/*
+lenientFunc returns name of xpath helper that decodes struct or array, helpers that accept empty value of other
+type are used when lenient empty values are enabled
+*/
+func lenientFunc(name string, lenientEmpty bool) string {
+	if lenientEmpty {
+		return name + "OrEmpty"
+	}
+	return name
+}
+
+var (
+	// textMarshaler and textUnmarshaler are encoding.TextMarshaler and encoding.TextUnmarshaler interfaces
+	textMarshaler   = newMethodInterface("MarshalText", nil, []types.Type{types.NewSlice(types.Typ[types.Byte]), types.Universe.Lookup("error").Type()})
+	textUnmarshaler = newMethodInterface("UnmarshalText", []types.Type{types.NewSlice(types.Typ[types.Byte])}, []types.Type{types.Universe.Lookup("error").Type()})
+)
+
+/*
+isTextMarshaler returns whether value of given type can be marshaled to text and unmarshaled back
+(pointer is used for unmarshaling)
+*/
+func isTextMarshaler(typ types.Type) bool {
+	unmarshaled := typ
+	if _, ok := typ.(*types.Pointer); !ok {
+		unmarshaled = types.NewPointer(typ)
+	}
+	return types.Implements(typ, textMarshaler) && types.Implements(unmarshaled, textUnmarshaler)
+}
+
+/*
+isValueMarshaler returns whether type implements xmlrpc.ValueMarshaler and its pointer xmlrpc.ValueUnmarshaler
+*/
+func isValueMarshaler(typ types.Type) bool {
+	unmarshaled := typ
+	if _, ok := typ.(*types.Pointer); !ok {
+		unmarshaled = types.NewPointer(typ)
+	}
+	return hasMethod(typ, "MarshalXMLRPC", "func() (*github.com/phonkee/go-xmlrpc.Value, error)") &&
+		hasMethod(unmarshaled, "UnmarshalXMLRPC", "func(*github.com/phonkee/go-xmlrpc.Value) error")
+}
+
+/*
+hasMethod returns whether method set of type has method with given signature (without param names)
+*/
+func hasMethod(typ types.Type, name string, signature string) bool {
+	mset := types.NewMethodSet(typ)
+	for i := 0; i < mset.Len(); i++ {
+		method := mset.At(i).Obj()
+		if method.Name() != name {
+			continue
+		}
+		sig := method.Type().(*types.Signature)
+		return types.TypeString(types.NewSignature(nil, unnamedTuple(sig.Params()), unnamedTuple(sig.Results()), sig.Variadic()), nil) == signature
+	}
+	return false
+}
+
+/*
+unnamedTuple returns tuple with types of given tuple without names
+*/
+func unnamedTuple(tuple *types.Tuple) *types.Tuple {
+	vars := make([]*types.Var, 0, tuple.Len())
+	for i := 0; i < tuple.Len(); i++ {
+		vars = append(vars, types.NewVar(token.NoPos, nil, "", tuple.At(i).Type()))
+	}
+	return types.NewTuple(vars...)
+}
+
+/*
+newMethodInterface returns interface with single method with given params and results
+*/
+func newMethodInterface(name string, params []types.Type, results []types.Type) *types.Interface {
+	tuple := func(typs []types.Type) *types.Tuple {
+		vars := make([]*types.Var, 0, len(typs))
+		for _, typ := range typs {
+			vars = append(vars, types.NewVar(token.NoPos, nil, "", typ))
+		}
+		return types.NewTuple(vars...)
+	}
+
+	signature := types.NewSignature(nil, tuple(params), tuple(results), false)
+	result := types.NewInterface([]*types.Func{types.NewFunc(token.NoPos, nil, name, signature)}, nil)
+	return result.Complete()
+}
+
+/*
+typeString returns type as written in generated code, types from pkg (package of generated code) are not
+qualified and other packages are qualified by their name
+*/
+func typeString(typ types.Type, pkg *types.Package) string {
+	return types.TypeString(typ, func(other *types.Package) string {
+		if pkg != nil && other.Path() == pkg.Path() {
+			return ""
+		}
+		return other.Name()
+	})
+}
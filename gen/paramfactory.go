@@ -0,0 +1,130 @@
+package gen
+
+import (
+	"bytes"
+	"go/token"
+	"go/types"
+	"sync"
+)
+
+/*
+ParamFactory returns Param of variable whose type it supports (protobuf timestamps, custom ids...), ok is false
+for other variables. Param writes code that decodes value from *xmlrpc.Element (FromEtree) and code that
+writes value to *xmlrpc.Element of xmlrpc value (ToEtree) regardless of backend, it can use runtime helpers of this
+package (XPathValueGet*, Errorf...) and variable names from given VariableNames (names.Next or template functions
+of names.Funcs).
+*/
+type ParamFactory func(variable *types.Var) (param Param, ok bool)
+
+/*
+WireParam is optionally implemented by Param of factory, Wire returns xmlrpc type of its values (used by
+system.methodSignature), "string" is used otherwise
+*/
+type WireParam interface {
+	Param
+
+	// Wire returns xmlrpc type name (int, struct, array...)
+	Wire() string
+}
+
+var (
+	paramFactoriesMutex sync.RWMutex
+	paramFactories      []ParamFactory
+)
+
+/*
+RegisterParamFactory registers factory of params, factories are asked in order of registration before built-in
+types (variables tagged ",codec" are still encoded by codecs). Generator with registered factories has to be
+run from own main package, since xmlrpcgen doesn't know about them.
+*/
+func RegisterParamFactory(factory ParamFactory) {
+	if factory == nil {
+		panic("xmlrpc: param factory is nil")
+	}
+
+	paramFactoriesMutex.Lock()
+	defer paramFactoriesMutex.Unlock()
+	paramFactories = append(paramFactories, factory)
+}
+
+/*
+newFactoryParam returns param of variable created by registered factory, it's written with given backend
+*/
+func newFactoryParam(variable *types.Var, b *backend) (Param, bool) {
+	param, ok := factoryParam(variable)
+	if !ok {
+		return nil, false
+	}
+	return &pluginParam{
+		Param:   param,
+		name:    variable.Name(),
+		backend: b,
+	}, true
+}
+
+/*
+pluginParam is Param created by factory, its value is written to temporary element and then to response as raw
+value (backends don't write to *xmlrpc.Element)
+*/
+type pluginParam struct {
+	Param
+	name    string
+	backend *backend
+}
+
+func (p *pluginParam) Wire() string {
+	if wire, ok := p.Param.(WireParam); ok {
+		return wire.Wire()
+	}
+	return "string"
+}
+func (p *pluginParam) ToEtree(names *VariableNames, element string, resultvar string, errvar string) string {
+	buf := bytes.Buffer{}
+	value := names.Next("value")
+	raw := names.Next("raw")
+
+	names.renderInto(&buf, `
+	{{.Value}} := xmlrpc.NewElement("value")
+	{{.Code}}
+	var {{.Raw}} xmlrpc.RawValue
+	if {{.Raw}}, {{.ErrorVar}} = xmlrpc.XPathValueGetRaw({{.Value}}, "{{.Name}}"); {{.ErrorVar}} != nil {
+		return
+	}
+	`, map[string]interface{}{
+		"Value":    value,
+		"Code":     p.Param.ToEtree(names, value, resultvar, errvar),
+		"Raw":      raw,
+		"ErrorVar": errvar,
+		"Name":     p.name,
+	})
+	buf.WriteString(p.backend.render(names, "raw", map[string]interface{}{
+		"Element":   element,
+		"ErrorVar":  errvar,
+		"ResultVar": raw,
+	}))
+
+	return buf.String()
+}
+
+/*
+factoryParam returns param of variable created by first registered factory that supports it
+*/
+func factoryParam(variable *types.Var) (Param, bool) {
+	paramFactoriesMutex.RLock()
+	defer paramFactoriesMutex.RUnlock()
+
+	for _, factory := range paramFactories {
+		if param, ok := factory(variable); ok {
+			return param, true
+		}
+	}
+	return nil, false
+}
+
+/*
+hasFactoryParam returns whether type is supported by registered factory
+*/
+func hasFactoryParam(typ types.Type, pkg *types.Package) bool {
+	_, ok := factoryParam(types.NewVar(token.NoPos, pkg, "value", typ))
+	return ok
+}
@@ -0,0 +1,141 @@
+package gen
+
+import (
+	"reflect"
+	"strings"
+)
+
+/*
+fieldTag is parsed struct field tag in form `xmlrpc:"name,option,key=value"`. Name is name of struct member
+(field name is used when empty). Option ",pattern=" must be the last one, its value can contain commas.
+*/
+type fieldTag struct {
+	Name    string
+	Options map[string]string
+}
+
+/*
+parseTag parses xmlrpc part of struct field tag
+*/
+func parseTag(tag string) fieldTag {
+	parts := strings.Split(reflect.StructTag(tag).Get("xmlrpc"), ",")
+
+	result := fieldTag{
+		Name:    strings.TrimSpace(parts[0]),
+		Options: map[string]string{},
+	}
+
+	for i, part := range parts[1:] {
+		if part = strings.TrimSpace(part); part == "" {
+			continue
+		}
+		// pattern can contain commas, it's always the last option
+		if strings.HasPrefix(part, "pattern=") {
+			result.Options["pattern"] = strings.TrimPrefix(strings.TrimLeft(strings.Join(parts[i+1:], ","), " "), "pattern=")
+			break
+		}
+		if i := strings.Index(part, "="); i >= 0 {
+			result.Options[part[:i]] = part[i+1:]
+		} else {
+			result.Options[part] = ""
+		}
+	}
+
+	return result
+}
+
+/*
+Has returns whether tag has given option
+*/
+func (t fieldTag) Has(option string) bool {
+	_, ok := t.Options[option]
+	return ok
+}
+
+/*
+paramOptions configure generated code of params. They are set by generator options and can be overridden for
+single struct field by its tag, nested params inherit options of their parent.
+*/
+type paramOptions struct {
+	// coerce converts scalar values sent with other type (e.g. numbers sent as strings), see coerce.go
+	coerce bool
+
+	// lenientEmpty decodes empty array as struct and empty struct as slice (PHP servers), see empty.go
+	lenientEmpty bool
+
+	// uuid transfers [16]byte as canonical uuid string
+	uuid bool
+
+	// tuple transfers struct as array of its fields (by position)
+	tuple bool
+
+	// oneof transfers struct as one of its variant fields (tagged union)
+	oneof bool
+
+	// timezone is location of dateTime values without offset given by ",tz=Europe/Prague" tag
+	timezone string
+
+	// memberOrder is order of written struct members (MemberOrderField or MemberOrderAlpha)
+	memberOrder string
+
+	// duplicateMembers is policy of decoding duplicate struct members, see duplicate.go
+	duplicateMembers string
+
+	// foldMembers matches struct members case-insensitively, see fold.go
+	foldMembers bool
+
+	// lenientDoubles accepts doubles with comma decimal separator or exponent, see double.go
+	lenientDoubles bool
+
+	// nonFiniteDoubles writes and reads NaN and infinities, see double.go
+	nonFiniteDoubles bool
+
+	// doubleFormat and doublePrecision are strconv format and precision of written doubles given by ",fmt=f" and
+	// ",prec=2" tags, see double.go
+	doubleFormat    string
+	doublePrecision string
+
+	// maxLength and pattern validate decoded strings, given by ",max=255" and ",pattern=^[a-z]+$" tags, see
+	// validate.go
+	maxLength string
+	pattern   string
+
+	// apacheExtensions writes ex:i8, ex:dateTime and ex:nil, see apache.go
+	apacheExtensions bool
+
+	// codec encodes and decodes field by codec registered for its type (",codec" tag), see codec.go
+	codec bool
+
+	// codecTypes are types (as written in generated code) always encoded by registered codecs
+	codecTypes map[string]bool
+
+	// codecs collects types that implement xmlrpc.ValueMarshaler, generated code registers their codecs
+	codecs *codecSet
+}
+
+/*
+withTag returns options overridden by field tag (",coerce", ",strict", ",uuid", ",tuple", ",oneof", ",codec",
+",tz=", ",fmt=", ",prec=", ",max=" or ",pattern=")
+*/
+func (o paramOptions) withTag(tag fieldTag) paramOptions {
+	if tag.Has("coerce") {
+		o.coerce = true
+	}
+	if tag.Has("strict") {
+		o.coerce = false
+	}
+	o.uuid = tag.Has("uuid")
+	o.tuple = tag.Has("tuple")
+	o.oneof = tag.Has("oneof")
+	o.timezone = tag.Options["tz"]
+	o.codec = tag.Has("codec")
+	o.maxLength = tag.Options["max"]
+	o.pattern = tag.Options["pattern"]
+	if format, ok := tag.Options["fmt"]; ok {
+		o.doubleFormat = format
+	}
+	if precision, ok := tag.Options["prec"]; ok {
+		o.doublePrecision = precision
+	}
+	return o
+}
@@ -0,0 +1,86 @@
+package gen
+
+import (
+	"bytes"
+	"go/types"
+)
+
+/*
+newTupleParam returns tupleParam, struct tagged ",tuple" is transferred as array with item for every field
+*/
+func newTupleParam(variable *types.Var, b *backend, opts paramOptions) Param {
+	strukt := variable.Type().(*types.Struct)
+
+	result := &tupleParam{
+		name:    variable.Name(),
+		typ:     typeString(variable.Type(), variable.Pkg()),
+		params:  make([]*structField, 0, strukt.NumFields()),
+		backend: b,
+	}
+
+	// items are in order of go fields, names are used only in error messages
+	for i := 0; i < strukt.NumFields(); i++ {
+		field := strukt.Field(i)
+		tag := parseTag(strukt.Tag(i))
+
+		v := types.NewVar(field.Pos(), field.Pkg(), variable.Name()+"."+field.Name(), field.Type())
+		result.params = append(result.params, &structField{
+			Param: getParam(v, b, opts.withTag(tag)),
+			Field: field.Name(),
+		})
+	}
+
+	return result
+}
+
+/*
+tupleParam is struct transferred as fixed-position array of mixed values (e.g. [int, string, struct])
+*/
+type tupleParam struct {
+	name    string
+	typ     string
+	params  []*structField
+	backend *backend
+}
+
+func (p *tupleParam) Name() string { return p.name }
+func (p *tupleParam) Type() string { return p.typ }
+func (p *tupleParam) FromEtree(names *VariableNames, element string, resultvar string, errvar string) string {
+	buf := bytes.Buffer{}
+
+	names.renderInto(&buf, `
+	// tuple is decoded from array by item position
+	{{.ResultVar}} := {{.Type}}{}
+
+	{{$items := GenerateVariableName "items" }}
+	var {{$items}} []*xmlrpc.Element
+	if {{$items}}, {{.ErrorVar}} = xmlrpc.XPathValueGetTuple({{.Element}}, "{{.Name}}", {{len .Params}}); {{.ErrorVar}} != nil {
+		return
+	}
+
+	{{range $index, $param := .Params}}
+		{{$item := GenerateVariableName "item"}}
+		{{$item}} := {{$items}}[{{$index}}]
+		{{$paramTmp := GenerateVariableName }}
+		{{$param.FromEtree names $item $paramTmp $.ErrorVar }}
+		{{$.ResultVar}}.{{$param.Field}} = {{$paramTmp}}
+	{{end}}
+	`, map[string]interface{}{
+		"Type":      p.Type(),
+		"ResultVar": resultvar,
+		"Element":   element,
+		"ErrorVar":  errvar,
+		"Name":      p.name,
+		"Params":    p.params,
+	})
+
+	return buf.String()
+}
+func (p *tupleParam) ToEtree(names *VariableNames, element string, resultvar string, errvar string) string {
+	return p.backend.render(names, "tuple", map[string]interface{}{
+		"Element":   element,
+		"ErrorVar":  errvar,
+		"Params":    p.params,
+		"ResultVar": resultvar,
+	})
+}
@@ -0,0 +1,154 @@
+package gen
+
+import (
+	"bytes"
+	"go/types"
+	"strings"
+)
+
+/*
+newUnionParam returns unionParam. Struct tagged ",oneof" has string field tagged ",kind" (discriminator) and
+variant fields, every variant must have other xmlrpc type. Decoded value is stored to variant of its type and
+name of variant field is stored to kind field, written value is variant given by kind field.
+*/
+func newUnionParam(variable *types.Var, b *backend, opts paramOptions) Param {
+	strukt := variable.Type().(*types.Struct)
+
+	result := &unionParam{
+		name:     variable.Name(),
+		typ:      typeString(variable.Type(), variable.Pkg()),
+		variants: []*unionVariant{},
+	}
+
+	wires := map[string]string{}
+	for i := 0; i < strukt.NumFields(); i++ {
+		field := strukt.Field(i)
+		tag := parseTag(strukt.Tag(i))
+
+		if tag.Has("kind") {
+			if basic, ok := field.Type().(*types.Basic); !ok || basic.Kind() != types.String {
+				Exit("kind field %v of %v must be string", field.Name(), variable.Name())
+			}
+			result.kind = field.Name()
+			continue
+		}
+
+		v := types.NewVar(field.Pos(), field.Pkg(), variable.Name(), field.Type())
+		variant := &unionVariant{
+			structField: &structField{
+				Param: getParam(v, b, opts.withTag(tag)),
+				Field: field.Name(),
+			},
+		}
+		variant.Wire = wireType(variant.Param)
+
+		if other, ok := wires[variant.Wire]; ok {
+			Exit("variants %v and %v of %v have same xmlrpc type %v", other, field.Name(), variable.Name(), variant.Wire)
+		}
+		wires[variant.Wire] = field.Name()
+
+		result.variants = append(result.variants, variant)
+	}
+
+	if result.kind == "" {
+		Exit("%v tagged oneof must have string field tagged kind", variable.Name())
+	}
+
+	return result
+}
+
+/*
+unionVariant is variant field of union
+*/
+type unionVariant struct {
+	*structField
+
+	// Wire is xmlrpc type of variant
+	Wire string
+}
+
+/*
+unionParam is struct that holds one of values of different types (tagged union)
+*/
+type unionParam struct {
+	name     string
+	typ      string
+	kind     string
+	variants []*unionVariant
+}
+
+func (p *unionParam) Name() string { return p.name }
+func (p *unionParam) Type() string { return p.typ }
+
+/*
+Wire returns xmlrpc types of all variants separated by "|"
+*/
+func (p *unionParam) Wire() string {
+	wires := []string{}
+	for _, variant := range p.variants {
+		wires = append(wires, variant.Wire)
+	}
+	return strings.Join(wires, "|")
+}
+
+func (p *unionParam) FromEtree(names *VariableNames, element string, resultvar string, errvar string) string {
+	buf := bytes.Buffer{}
+
+	names.renderInto(&buf, `
+	// union is decoded to variant by type of value
+	{{.ResultVar}} := {{.Type}}{}
+
+	{{$valueType := GenerateVariableName "value_type"}}
+	{{$valueType}} := xmlrpc.XPathValueType({{.Element}})
+	switch {{$valueType}} {
+	{{range .Variants}}
+	case "{{.Wire}}":
+		{{$paramTmp := GenerateVariableName }}
+		{{.FromEtree names $.Element $paramTmp $.ErrorVar }}
+		{{$.ResultVar}}.{{.Field}} = {{$paramTmp}}
+		{{$.ResultVar}}.{{$.Kind}} = "{{.Field}}"
+	{{end}}
+	default:
+		{{.ErrorVar}} = xmlrpc.Errorf(xmlrpc.FaultInvalidParams, "{{.Name}} has unexpected type %v", {{$valueType}})
+		return
+	}
+	`, map[string]interface{}{
+		"Type":      p.Type(),
+		"ResultVar": resultvar,
+		"Element":   element,
+		"ErrorVar":  errvar,
+		"Name":      p.name,
+		"Kind":      p.kind,
+		"Variants":  p.variants,
+	})
+
+	return buf.String()
+}
+
+func (p *unionParam) ToEtree(names *VariableNames, element string, resultvar string, errvar string) string {
+	buf := bytes.Buffer{}
+
+	names.renderInto(&buf, `
+	// variant given by kind is written
+	switch {{.ResultVar}}.{{.Kind}} {
+	{{range .Variants}}
+	case "{{.Field}}":
+		{{$item := GenerateVariableName "variant"}}
+		{{$item}} := {{$.ResultVar}}.{{.Field}}
+		{{.ToEtree names $.Element $item $.ErrorVar }}
+	{{end}}
+	default:
+		{{.ErrorVar}} = xmlrpc.Errorf(xmlrpc.FaultInternalError, "{{.Name}} has unknown kind %q", {{.ResultVar}}.{{.Kind}})
+		return
+	}
+	`, map[string]interface{}{
+		"ResultVar": resultvar,
+		"Element":   element,
+		"ErrorVar":  errvar,
+		"Name":      p.name,
+		"Kind":      p.kind,
+		"Variants":  p.variants,
+	})
+
+	return buf.String()
+}
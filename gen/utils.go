@@ -1,4 +1,4 @@
-package xmlrpc
+package gen
 
 import (
 	"fmt"
@@ -11,35 +11,64 @@ import (
 
 	"crypto/md5"
 
-	"sync/atomic"
-
 	"io"
 
 	"github.com/fatih/color"
 )
 
-var (
-	idcounter uint64 = 0
-)
+/*
+VariableNames generates variable names of generated code, every method has its own, so names are unique in code of
+method and they don't depend on order in which methods are rendered
+*/
+type VariableNames struct {
+	counter int
+}
+
+/*
+newVariableNames returns VariableNames of single method
+*/
+func newVariableNames() *VariableNames {
+	return &VariableNames{}
+}
 
 /*
-GenerateVariableName generates unique variable name
+Next generates unique variable name
 */
-func GenerateVariableName(prefix... string) string {
-	id := atomic.AddUint64(&idcounter, 1)
+func (v *VariableNames) Next(prefix ...string) string {
+	v.counter++
 
 	p := "v_"
-	if len(prefix) > 0 && strings.TrimSpace(prefix[0]) != ""{
+	if len(prefix) > 0 && strings.TrimSpace(prefix[0]) != "" {
 		p = strings.TrimSpace(prefix[0]) + "_"
 	}
 
-	return p + strconv.Itoa(int(id))
+	return p + strconv.Itoa(v.counter)
+}
+
+/*
+Funcs returns template functions that generate names ("GenerateVariableName") and return VariableNames ("names",
+passed to Params rendered by template)
+*/
+func (v *VariableNames) Funcs() template.FuncMap {
+	return template.FuncMap{
+		"GenerateVariableName": v.Next,
+		"names": func() *VariableNames {
+			return v
+		},
+	}
+}
+
+/*
+renderInto renders template with data to w, generated variable names are taken from v
+*/
+func (v *VariableNames) renderInto(w io.Writer, tpl string, data map[string]interface{}, funcmaps ...template.FuncMap) {
+	RenderTemplateInto(w, tpl, data, append(funcmaps, v.Funcs())...)
 }
 
 /*
 Exit prints error to stdout and exits with error
 */
-func Exit(err interface{}, args...interface{}) {
+func Exit(err interface{}, args ...interface{}) {
 	msg := ""
 	switch err := err.(type) {
 	case string:
@@ -71,9 +100,8 @@ func RenderTemplate(tpl string, data map[string]interface{}, funcmaps ...templat
 	)
 
 	funcmap := template.FuncMap{
-		"GenerateVariableName": GenerateVariableName,
-		"IsBlank":              IsBlank,
-		"IsNotBlank":           IsNotBlank,
+		"IsBlank":    IsBlank,
+		"IsNotBlank": IsNotBlank,
 	}
 
 	// update with given funcmaps
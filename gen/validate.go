@@ -0,0 +1,43 @@
+package gen
+
+import (
+	"go/types"
+	"regexp"
+	"strconv"
+)
+
+/*
+checkStringTags exits when ",max=N" or ",pattern=" tag of param is invalid or param is not string (or slice of
+strings, every item is validated)
+*/
+func checkStringTags(variable *types.Var, opts paramOptions) {
+	if opts.maxLength == "" && opts.pattern == "" {
+		return
+	}
+	if !isStringType(variable.Type()) {
+		Exit("max and pattern tags of %v are supported only by strings", variable.Name())
+	}
+	if opts.maxLength != "" {
+		if max, err := strconv.Atoi(opts.maxLength); err != nil || max < 0 {
+			Exit("invalid max %v of %v", opts.maxLength, variable.Name())
+		}
+	}
+	if opts.pattern != "" {
+		if _, err := regexp.Compile(opts.pattern); err != nil {
+			Exit("invalid pattern of %v: %v", variable.Name(), err)
+		}
+	}
+}
+
+/*
+isStringType returns whether type is string or slice of strings
+*/
+func isStringType(typ types.Type) bool {
+	switch x := typ.(type) {
+	case *types.Basic:
+		return x.Kind() == types.String
+	case *types.Slice:
+		return isStringType(x.Elem())
+	}
+	return false
+}
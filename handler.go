@@ -1,13 +1,23 @@
 package xmlrpc
 
 import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
+	"io"
 	"net/http"
 	"reflect"
 	"strings"
+	"time"
 
 	"github.com/beevik/etree"
-	"fmt"
+)
+
+const (
+	// RequestIDHeader is http header that carries request id
+	RequestIDHeader = "X-Request-ID"
 )
 
 var (
@@ -24,24 +34,135 @@ type Handler interface {
 	// AddService under given namespace
 	AddService(service interface{}, name string) error
 
+	// AddTenantService under given namespace, it's used instead of service added by AddService for calls of tenant
+	AddTenantService(tenant string, service interface{}, name string) error
+
 	// ListMethods returns all available xmlrpc methods
 	ListMethods() []string
 
+	// Manifest returns xmlrpc types of methods of services that implement SignatureLister
+	Manifest() Manifest
+
+	// DescribeMethod returns param names, types and help of method (system.describeMethod)
+	DescribeMethod(name string) (MethodDescription, bool)
+
+	// Dispatch calls method of registered service with given params element
+	Dispatch(ctx context.Context, method string, params *Element) (io.WriterTo, error)
+
 	// ServeHTTP satisfy http.Handler
 	ServeHTTP(w http.ResponseWriter, r *http.Request)
+
+	// Serve reads call from r and writes response to w (other transports than http, files...)
+	Serve(ctx context.Context, r io.Reader, w io.Writer) error
+}
+
+/*
+HandlerOption configures handler
+*/
+type HandlerOption func(*handler)
+
+/*
+WithAccessLogger sets logger that receives access log record for every call
+*/
+func WithAccessLogger(logger AccessLogger) HandlerOption {
+	return func(h *handler) {
+		h.accessLogger = logger
+	}
+}
+
+/*
+WithFaultRequestID adds "requestId" member to all fault responses
+*/
+func WithFaultRequestID() HandlerOption {
+	return func(h *handler) {
+		h.faultRequestID = true
+	}
 }
 
 /*
 NewHandler returns xmlrpc handler
 */
-func NewHandler() Handler {
-	return &handler{
-		services: map[string]Service{},
+func NewHandler(options ...HandlerOption) Handler {
+	result := &handler{
+		services:       map[string]Service{},
+		deprecated:     map[string]string{},
+		contentTypes:   DefaultContentTypes,
+		dateTimePolicy: DefaultDateTimePolicy,
 	}
+
+	for _, option := range options {
+		option(result)
+	}
+
+	return result
 }
 
 type handler struct {
 	services map[string]Service
+
+	// tenantServices are services of tenants by tenant key
+	tenantServices map[string]map[string]Service
+
+	// deprecated methods (full name) with their deprecation notice
+	deprecated map[string]string
+
+	// contentTypes accepted in requests
+	contentTypes []string
+
+	// canonicalOutput writes responses in canonical form
+	canonicalOutput bool
+
+	// indent of responses (empty for compact responses)
+	indent string
+
+	// dateTimePolicy is passed to generated code in context
+	dateTimePolicy DateTimePolicy
+
+	// accessLogger receives record for every call
+	accessLogger AccessLogger
+
+	// auditSink receives audit record for every call, params named in auditRedact are redacted
+	auditSink   AuditSink
+	auditRedact map[string]bool
+
+	// faultRequestID adds request id to faults
+	faultRequestID bool
+
+	// errorChains adds chain of wrapped errors to fault detail
+	errorChains bool
+
+	// callValidators are called before call is dispatched
+	callValidators []CallValidator
+
+	// responseProcessors are called with response of successful calls
+	responseProcessors []ResponseProcessor
+
+	// methodNotFound is called for unknown methods
+	methodNotFound MethodNotFoundHandler
+
+	// shadow sends calls also to upstream and compares responses
+	shadow *Shadow
+
+	// strictNamespaces rejects requests with namespaces (they are ignored otherwise)
+	strictNamespaces bool
+
+	// decode limits (0 means unlimited)
+	maxArrayElements int
+	maxStructMembers int
+	maxBase64Size    int
+
+	// maxRequestSize is limit of request body in bytes (0 means unlimited)
+	maxRequestSize int64
+
+	// heartbeat is interval of heartbeats of long calls (0 disables them)
+	heartbeat time.Duration
+
+	// etagPatterns are patterns of methods with ETags
+	etagPatterns []string
+
+	// attachments accepts multipart/related requests with attachments of at most maxAttachmentsSize bytes
+	attachments        bool
+	maxAttachmentsSize int64
 }
 
 /*
@@ -49,6 +170,13 @@ AddService adds service to handler, you ask why interface{} and not Service? Wel
 some meaningful error message.
 */
 func (h *handler) AddService(service interface{}, name string) error {
+	return h.register(h.services, service, name)
+}
+
+/*
+register checks service and adds it to services under given name
+*/
+func (h *handler) register(services map[string]Service, service interface{}, name string) error {
 
 	// True whether it's really service
 	if s, ok := service.(Service); !ok {
@@ -61,10 +189,19 @@ func (h *handler) AddService(service interface{}, name string) error {
 		}
 
 		// check if service already exists
-		if _, ok := h.services[name]; ok {
+		if _, ok := services[name]; ok {
 			return ErrServiceAlreadyRegistered
 		}
-		h.services[name] = s
+		services[name] = s
+
+		// remember deprecated methods, calls of them are marked by headers
+		if lister, ok := service.(HelpLister); ok {
+			for method, help := range lister.MethodHelp() {
+				if notice := DeprecationNotice(help); notice != "" {
+					h.deprecated[fullMethodName(name, method)] = notice
+				}
+			}
+		}
 	}
 
 	return nil
@@ -74,15 +211,27 @@ func (h *handler) AddService(service interface{}, name string) error {
 ListMethods returns list of all available XML rpc methods
 */
 func (h *handler) ListMethods() []string {
-	fmt.Printf("this %#v\n", h.services)
 	result := []string{}
 	for name, service := range h.services {
 		for _, method := range service.ListMethods() {
-			if name == "" {
-				result = append(result, method)
-			} else {
-				result = append(result, name+"."+method)
-			}
+			result = append(result, fullMethodName(name, method))
+		}
+	}
+	return result
+}
+
+/*
+Manifest returns xmlrpc types of all methods of services that implement SignatureLister
+*/
+func (h *handler) Manifest() Manifest {
+	result := Manifest{}
+	for name, service := range h.services {
+		lister, ok := service.(SignatureLister)
+		if !ok {
+			continue
+		}
+		for method, signature := range lister.MethodSignatures() {
+			result[fullMethodName(name, method)] = signature
 		}
 	}
 	return result
@@ -95,97 +244,425 @@ func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 	// check for POST method
 	if strings.ToUpper(r.Method) != "POST" {
+		w.Header().Set("Allow", "POST")
 		w.WriteHeader(http.StatusMethodNotAllowed)
 		return
 	}
 
+	start := time.Now()
+
+	// accept request id from caller or generate new one
+	requestID := r.Header.Get(RequestIDHeader)
+	if requestID == "" {
+		requestID = newRequestID()
+	}
+	ctx, cancel := contextWithTimeoutHeader(r.Context(), r)
+	defer cancel()
+	ctx = ContextWithRequestID(ctx, requestID)
+	ctx = ContextWithDateTimePolicy(ctx, h.dateTimePolicy)
+	if len(h.etagPatterns) > 0 {
+		ctx = context.WithValue(ctx, etagContextKey, new(string))
+	}
+
+	w.Header().Set(RequestIDHeader, requestID)
 	w.Header().Set("Content-Type", "application/xml")
 
 	var (
-		doc *etree.Document
-		err error
+		method string
+		res    io.WriterTo
+	)
+
+	var (
+		body        io.Reader = r.Body
+		contentType           = r.Header.Get("Content-Type")
+		err         error
+
+		// status and headers were sent with first heartbeat
+		heartbeatStarted bool
 	)
+	if h.attachments && isMultipart(contentType) {
+		ctx, body, err = readMultipart(ctx, contentType, r.Body, h.contentTypes, h.maxAttachmentsSize)
+	} else {
+		err = checkContentType(contentType, h.contentTypes)
+	}
+	// deprecation headers are set once method is known, before heartbeats could send headers
+	known := func(method string) {
+		h.deprecate(w.Header(), method)
+	}
+	if err == nil && h.heartbeat > 0 {
+		heartbeat := &heartbeatWriter{ResponseWriter: w}
+		stop := startHeartbeat(heartbeat, h.heartbeat)
+		method, res, err = h.handle(ctx, body, func(method string) {
+			heartbeat.beforeStart(func() { known(method) })
+		})
+		stop()
+		w, heartbeatStarted = heartbeat, heartbeat.started
+	} else if err == nil {
+		method, res, err = h.handle(ctx, body, known)
+	}
+
+	if err != nil {
+		res = h.faultDocument(ctx, err)
+		w.WriteHeader(faultStatus(ctx, err))
+	}
+
+	out := h.output(res)
+	// response with 304 status cannot be sent once heartbeats started
+	if err == nil && !heartbeatStarted && len(h.etagPatterns) > 0 && h.etagged(method) {
+		var notModified bool
+		if out, notModified = h.etag(ctx, w, r, out); notModified {
+			w.WriteHeader(http.StatusNotModified)
+		}
+	}
+	if out != nil {
+		out.WriteTo(w)
+	}
+
+	if h.accessLogger != nil {
+		record := AccessLogRecord{
+			Time:       start,
+			RequestID:  requestID,
+			RemoteAddr: r.RemoteAddr,
+			Method:     method,
+			Duration:   time.Since(start),
+		}
+		if identity := IdentityFromContext(ctx); identity != nil {
+			record.Caller = identity.Name
+		}
+		if err != nil {
+			record.FaultCode = errorCode(err)
+			record.FaultString = err.Error()
+		}
+		h.accessLogger.LogAccess(record)
+	}
+}
+
+/*
+Serve reads call from r and streams response to w, faults are written as fault responses. Returned error is error
+of writing response.
+*/
+func (h *handler) Serve(ctx context.Context, r io.Reader, w io.Writer) error {
+	if RequestIDFromContext(ctx) == "" {
+		ctx = ContextWithRequestID(ctx, newRequestID())
+	}
+	ctx = ContextWithDateTimePolicy(ctx, h.dateTimePolicy)
+
+	_, res, err := h.handle(ctx, r, nil)
+	if err != nil {
+		res = h.faultDocument(ctx, err)
+	}
+
+	_, err = h.output(res).WriteTo(w)
+	return err
+}
 
-	resultDoc := etree.NewDocument()
-	resultDoc.CreateProcInst("xml", `version="1.0" encoding="UTF-8"`)
+/*
+output returns response formatted by canonical output and indent options
+*/
+func (h *handler) output(res io.WriterTo) io.WriterTo {
+	if h.canonicalOutput {
+		return canonicalWriterTo{response: res}
+	} else if h.indent != "" {
+		return canonicalWriterTo{response: res, indent: h.indent}
+	}
+	return res
+}
+
+/*
+deprecate marks response of deprecated method by Deprecation and Warning headers
+*/
+func (h *handler) deprecate(header http.Header, method string) {
+	if notice, ok := h.deprecated[method]; ok {
+		header.Set("Deprecation", "true")
+		header.Set("Warning", deprecationWarning(notice))
+	}
+}
+
+/*
+handle parses xmlrpc call from body and dispatches it to appropriate service. Known (when not nil) is called with
+method name as soon as it's read.
+*/
+func (h *handler) handle(ctx context.Context, body io.Reader, known func(method string)) (method string,
+	res io.WriterTo, err error) {
+	var el *etree.Element
+	if h.auditSink != nil {
+		defer func(start time.Time) {
+			h.audit(ctx, start, method, el, err)
+		}(time.Now())
+	}
 
 	// create new document
-	doc = etree.NewDocument()
+	doc := etree.NewDocument()
 
-	if _, err = doc.ReadFrom(r.Body); err != nil {
-		faultStruct := resultDoc.CreateElement("methodResponse").CreateElement("fault").CreateElement("value")
-		XMLWriteError(faultStruct, errors.New("cannot parse body"))
-		resultDoc.WriteTo(w)
+	if h.maxRequestSize > 0 {
+		body = &sizeLimitedReader{reader: body, max: h.maxRequestSize}
+	}
+
+	// limits are checked while request is read, before document is built
+	if h.maxArrayElements > 0 || h.maxStructMembers > 0 || h.maxBase64Size > 0 {
+		var data []byte
+		if data, err = readLimited(body, doc.ReadSettings.CharsetReader, h.maxArrayElements, h.maxStructMembers,
+			h.maxBase64Size); err != nil {
+			return
+		}
+		body = bytes.NewReader(data)
+	}
+
+	if _, err = doc.ReadFrom(body); err != nil {
+		if err != ErrRequestTooLarge {
+			err = parseError(err)
+		}
 		return
 	}
 
-	var method string
+	if h.strictNamespaces && doc.Root() != nil {
+		if err = checkNamespaces(doc.Root()); err != nil {
+			return
+		}
+	}
 
 	if element := doc.FindElement("methodCall/methodName"); element == nil {
-		faultStruct := resultDoc.CreateElement("methodResponse").CreateElement("fault").CreateElement("value")
-		XMLWriteError(faultStruct, errors.New("methodName not found"))
-		resultDoc.WriteTo(w)
+		err = Errorf(FaultInvalidRequest, "methodName not found")
 		return
 	} else {
 		method = element.Text()
 	}
+	if known != nil {
+		known(method)
+	}
 
 	// list methods serve directly
 	if method == "system.listMethods" {
-		value := resultDoc.CreateElement("methodResponse").CreateElement("params").CreateElement("param").CreateElement("value")
+		doc := newResponseDocument()
+		value := doc.CreateElement("methodResponse").CreateElement("params").CreateElement("param").CreateElement("value")
 		availMethods := h.ListMethods()
-		availMethods = append(availMethods, "system.listMethods")
+		availMethods = append(availMethods, "system.listMethods", "system.methodSignature", "system.methodHelp",
+			"system.describeMethod")
 		XMLWriteStringSlice(value, availMethods)
-		resultDoc.WriteTo(w)
+		res = doc
+		return
+	}
+
+	// method signatures are served from manifest
+	if method == "system.methodSignature" {
+		res, err = h.methodSignature(doc)
 		return
 	}
 
-	// now we need to split methods by dot make a lookup and perform
-	splitted := strings.SplitN(method, ".", 2)
-	service := ""
+	// method help is served from doc comments
+	if method == "system.methodHelp" {
+		res, err = h.methodHelp(doc)
+		return
+	}
 
-	if len(splitted) == 2 {
-		service = splitted[0]
-		method = splitted[1]
+	// extended introspection (param names, help, deprecation)
+	if method == "system.describeMethod" {
+		res, err = h.describeMethod(doc)
+		return
 	}
 
-	s, ok := h.services[service]
-	if !ok {
-		faultStruct := resultDoc.CreateElement("methodResponse").CreateElement("fault").CreateElement("value")
-		XMLWriteError(faultStruct, errors.New("method not found"))
-		resultDoc.WriteTo(w)
+	// now we need to split methods by last dot (namespace can contain dots, e.g. "v2.posts") make a lookup and perform
+	service, serviceMethod := splitMethod(method)
+
+	s, ok := h.service(ctx, service)
+	found := ok && s.MethodExists(serviceMethod)
+
+	// unknown methods are passed to fallback handler
+	if !found && h.methodNotFound == nil {
+		err = ErrMethodNotFound
+		return
+	}
+
+	if el = doc.FindElement("methodCall/params"); el == nil {
+		err = Errorf(FaultInvalidRequest, "params not found")
+		return
+	}
+
+	// methods with roles are allowed only to callers with one of them
+	if found {
+		if err = authorize(ctx, s, serviceMethod); err != nil {
+			return
+		}
+	}
+
+	// call validators can reject call
+	if err = h.validateCall(ctx, method, el); err != nil {
 		return
 	}
 
-	if !s.MethodExists(method) {
-		faultStruct := resultDoc.CreateElement("methodResponse").CreateElement("fault").CreateElement("value")
-		XMLWriteError(faultStruct, errors.New("method not found"))
-		resultDoc.WriteTo(w)
+	// call dispatch
+	if found && h.shadow != nil && h.shadow.matches(method) {
+		res, err = h.shadowCall(ctx, method, el, func() (io.WriterTo, error) {
+			return s.Dispatch(ctx, serviceMethod, el)
+		})
+	} else if found {
+		res, err = s.Dispatch(ctx, serviceMethod, el)
+	} else {
+		res, err = h.methodNotFound(ctx, method, el)
+	}
+	if err != nil {
+		return
+	}
+
+	// response processors can modify response
+	if len(h.responseProcessors) > 0 {
+		res, err = h.processResponse(ctx, method, res)
+	}
+	return
+}
+
+/*
+methodSignature returns array of signatures of method given as first param, "undef" string is returned when
+service does not list signatures (as introspection specification says)
+*/
+func (h *handler) methodSignature(doc *etree.Document) (res io.WriterTo, err error) {
+	var name string
+	if name, err = introspectedMethod(doc); err != nil {
 		return
 	}
 
+	response := newResponseDocument()
+	value := response.CreateElement("methodResponse").CreateElement("params").CreateElement("param").CreateElement("value")
+
+	signature, ok := h.Manifest()[name]
+	if !ok {
+		value.CreateElement("string").SetText("undef")
+		return response, nil
+	}
+
+	XMLWriteInterface(value, []interface{}{signature})
+	return response, nil
+}
+
+/*
+introspectedMethod returns method name given as first param of introspection call
+*/
+func introspectedMethod(doc *etree.Document) (name string, err error) {
 	el := doc.FindElement("methodCall/params")
 	if el == nil {
-		faultStruct := resultDoc.CreateElement("methodResponse").CreateElement("fault").CreateElement("value")
-		XMLWriteError(faultStruct, errors.New("params not found"))
-		resultDoc.WriteTo(w)
+		err = Errorf(FaultInvalidRequest, "params not found")
 		return
 	}
 
-	var (
-		res *etree.Document
-	)
+	params := XPathParams(el)
+	if len(params) < 1 || params[0] == nil {
+		err = Errorf(FaultInvalidParams, "could not find method name")
+		return
+	}
 
-	// call dispatch and write result
-	res, err = s.Dispatch(method, el)
-	if err != nil {
-		faultStruct := resultDoc.CreateElement("methodResponse").CreateElement("fault").CreateElement("value")
-		XMLWriteError(faultStruct, err)
-		resultDoc.WriteTo(w)
+	return XPathValueGetString(params[0], "method name")
+}
+
+/*
+methodHelp returns doc comment of method given as first param (empty string when method is not documented)
+*/
+func (h *handler) methodHelp(doc *etree.Document) (res io.WriterTo, err error) {
+	var name string
+	if name, err = introspectedMethod(doc); err != nil {
 		return
 	}
 
-	res.WriteTo(w)
+	help := ""
+	service, method := splitMethod(name)
+	if lister, ok := h.services[service].(HelpLister); ok {
+		help = lister.MethodHelp()[method]
+	}
 
-	return
+	response := newResponseDocument()
+	response.CreateElement("methodResponse").CreateElement("params").CreateElement("param").CreateElement("value").
+		CreateElement("string").SetText(help)
+	return response, nil
+}
+
+/*
+DescribeMethod returns description of method given by full name, ok is false when method does not exist
+*/
+func (h *handler) DescribeMethod(name string) (result MethodDescription, ok bool) {
+	service, method := splitMethod(name)
+
+	s, found := h.services[service]
+	if !found || !s.MethodExists(method) {
+		return
+	}
+
+	result = MethodDescription{Name: name, Params: []ParamDescription{}}
+
+	var signature, names []string
+	if lister, isLister := s.(SignatureLister); isLister {
+		signature = lister.MethodSignatures()[method]
+	}
+	if lister, isLister := s.(ParamNamesLister); isLister {
+		names = lister.MethodParamNames()[method]
+	}
+	if lister, isLister := s.(HelpLister); isLister {
+		result.Help = lister.MethodHelp()[method]
+		result.Deprecated = DeprecationNotice(result.Help)
+	}
+
+	if len(signature) > 0 {
+		result.Result = signature[0]
+		signature = signature[1:]
+	}
+	for i, typ := range signature {
+		param := ParamDescription{Type: typ}
+		if i < len(names) {
+			param.Name = names[i]
+		}
+		result.Params = append(result.Params, param)
+	}
+
+	return result, true
+}
+
+/*
+describeMethod returns description of method given as first param
+*/
+func (h *handler) describeMethod(doc *etree.Document) (res io.WriterTo, err error) {
+	var name string
+	if name, err = introspectedMethod(doc); err != nil {
+		return
+	}
+
+	description, ok := h.DescribeMethod(name)
+	if !ok {
+		err = ErrMethodNotFound
+		return
+	}
+
+	response := newResponseDocument()
+	value := response.CreateElement("methodResponse").CreateElement("params").CreateElement("param").CreateElement("value")
+	XMLWriteInterface(value, description.value())
+	return response, nil
+}
+
+/*
+faultDocument returns methodResponse document with fault
+*/
+func (h *handler) faultDocument(ctx context.Context, err error) *etree.Document {
+	doc := newResponseDocument()
+	value := doc.CreateElement("methodResponse").CreateElement("fault").CreateElement("value")
+	XMLWriteError(value, err)
+
+	if h.errorChains {
+		xmlWriteErrorChain(value.FindElement("struct"), err)
+	}
+
+	if h.faultRequestID {
+		if requestID := RequestIDFromContext(ctx); requestID != "" {
+			member := value.FindElement("struct").CreateElement("member")
+			member.CreateElement("name").SetText("requestId")
+			member.CreateElement("value").CreateElement("string").SetText(requestID)
+		}
+	}
+
+	return doc
+}
+
+/*
+newRequestID generates random request id
+*/
+func newRequestID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
 }
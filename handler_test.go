@@ -0,0 +1,90 @@
+package xmlrpc
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+
+	"github.com/beevik/etree"
+)
+
+/*
+testService is hand written service with single method Echo that returns "<service name>.Echo"
+*/
+type testService struct {
+	name string
+
+	mutex sync.Mutex
+	calls int
+	ctx   context.Context
+}
+
+func (t *testService) Dispatch(ctx context.Context, method string, root *etree.Element) (io.WriterTo, error) {
+	t.mutex.Lock()
+	t.calls++
+	t.ctx = ctx
+	t.mutex.Unlock()
+
+	doc := etree.NewDocument()
+	value := doc.CreateElement("methodResponse").CreateElement("params").CreateElement("param").CreateElement("value")
+	value.CreateElement("string").SetText(t.name + "." + method)
+	return doc, nil
+}
+
+func (t *testService) ListMethods() []string {
+	return []string{"Echo"}
+}
+
+func (t *testService) MethodExists(method string) bool {
+	return method == "Echo"
+}
+
+/*
+called returns count of calls and context of last one
+*/
+func (t *testService) called() (int, context.Context) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return t.calls, t.ctx
+}
+
+/*
+newTestCall returns http request with call of method with raw params (content of params element)
+*/
+func newTestCall(method, params string) *http.Request {
+	request := httptest.NewRequest("POST", "/", strings.NewReader(`<?xml version="1.0"?><methodCall><methodName>`+
+		method+`</methodName><params>`+params+`</params></methodCall>`))
+	request.Header.Set("Content-Type", "text/xml")
+	return request
+}
+
+/*
+serveTestCall serves request by handler and returns response
+*/
+func serveTestCall(handler http.Handler, request *http.Request) *httptest.ResponseRecorder {
+	response := httptest.NewRecorder()
+	handler.ServeHTTP(response, request)
+	return response
+}
+
+/*
+responseFault returns fault of response, nil for successful responses
+*/
+func responseFault(response *httptest.ResponseRecorder) *Fault {
+	doc := etree.NewDocument()
+	if err := doc.ReadFromBytes(response.Body.Bytes()); err != nil {
+		return &Fault{FaultString: err.Error()}
+	}
+	element := doc.FindElement("methodResponse/fault")
+	if element == nil {
+		return nil
+	}
+	fault, err := ParseFault(element)
+	if err != nil {
+		return &Fault{FaultString: err.Error()}
+	}
+	return fault
+}
@@ -0,0 +1,131 @@
+package xmlrpc
+
+import (
+	"bytes"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// xmlDeclaration is written before heartbeats
+	xmlDeclaration = `<?xml version="1.0" encoding="UTF-8"?>`
+)
+
+/*
+WithHeartbeat keeps connections of long calls alive, when call takes longer than interval, response is started
+with xml declaration followed by newline (whitespace before root element) every interval until response is
+ready, so proxies and load balancers don't close idle connections. Response status and headers are sent with the
+first heartbeat, so faults of such calls have 200 status. Response writer must be http.Flusher.
+*/
+func WithHeartbeat(interval time.Duration) HandlerOption {
+	return func(h *handler) {
+		h.heartbeat = interval
+	}
+}
+
+/*
+startHeartbeat sends heartbeats to writer until returned stop function is called
+*/
+func startHeartbeat(w *heartbeatWriter, interval time.Duration) (stop func()) {
+	done := make(chan struct{})
+	wg := sync.WaitGroup{}
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				w.beat()
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		wg.Wait()
+	}
+}
+
+/*
+heartbeatWriter writes heartbeats, after the first one status is not written and xml declaration of response is
+skipped (it was already written)
+*/
+type heartbeatWriter struct {
+	http.ResponseWriter
+	mutex   sync.Mutex
+	started bool
+
+	// prolog buffers beginning of response until it's known whether it's xml declaration
+	prolog    []byte
+	skipped   bool
+	skippable bool
+}
+
+/*
+beat writes heartbeat
+*/
+func (w *heartbeatWriter) beat() {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if !w.started {
+		w.started = true
+		w.skippable = true
+		w.ResponseWriter.WriteHeader(http.StatusOK)
+		w.ResponseWriter.Write([]byte(xmlDeclaration))
+	}
+	w.ResponseWriter.Write([]byte("\n"))
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+/*
+beforeStart calls fn unless the first heartbeat was written, so headers set by fn are sent with response
+*/
+func (w *heartbeatWriter) beforeStart(fn func()) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if !w.started {
+		fn()
+	}
+}
+
+func (w *heartbeatWriter) WriteHeader(status int) {
+	if !w.started {
+		w.ResponseWriter.WriteHeader(status)
+	}
+}
+
+func (w *heartbeatWriter) Write(data []byte) (int, error) {
+	if !w.skippable || w.skipped {
+		return w.ResponseWriter.Write(data)
+	}
+
+	// xml declaration of response is skipped
+	w.prolog = append(w.prolog, data...)
+	if len(w.prolog) < 5 && bytes.HasPrefix([]byte("<?xml"), w.prolog) {
+		return len(data), nil
+	}
+	if bytes.HasPrefix(w.prolog, []byte("<?xml")) {
+		end := bytes.Index(w.prolog, []byte("?>"))
+		if end == -1 {
+			return len(data), nil
+		}
+		w.prolog = w.prolog[end+2:]
+	}
+
+	w.skipped = true
+	if _, err := w.ResponseWriter.Write(w.prolog); err != nil {
+		return 0, err
+	}
+	w.prolog = nil
+	return len(data), nil
+}
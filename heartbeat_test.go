@@ -0,0 +1,41 @@
+package xmlrpc
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHeartbeatWithETag(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register("Slow", func(ctx context.Context, params ...*Value) (*Value, error) {
+		time.Sleep(50 * time.Millisecond)
+		return NewInt(42), nil
+	})
+	h := NewHandler(WithHeartbeat(10*time.Millisecond), WithETags("posts.*"))
+	h.AddService(registry, "posts")
+
+	server := httptest.NewServer(h)
+	defer server.Close()
+
+	request, _ := http.NewRequest("POST", server.URL, bytes.NewReader(encodeCall("posts.Slow", nil)))
+	request.Header.Set("Content-Type", "text/xml")
+	request.Header.Set("If-None-Match", "*")
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		t.Fatalf("call failed: %v", err)
+	}
+	defer response.Body.Close()
+	body, _ := ioutil.ReadAll(response.Body)
+
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("expected status 200, got %v", response.StatusCode)
+	}
+	if result, err := parseResponse(context.Background(), body); err != nil || result.Int() != 42 {
+		t.Errorf("expected full response, got %s (%v)", body, err)
+	}
+}
@@ -0,0 +1,98 @@
+package xmlrpc
+
+import (
+	"strconv"
+	"strings"
+)
+
+/*
+HelpLister is implemented by generated services, it returns doc comments of methods (system.methodHelp)
+*/
+type HelpLister interface {
+	MethodHelp() map[string]string
+}
+
+/*
+ParamNamesLister is implemented by generated services, it returns names of method params (as in go code)
+*/
+type ParamNamesLister interface {
+	MethodParamNames() map[string][]string
+}
+
+/*
+MethodDescription is result of system.describeMethod, it extends system.methodSignature with param names and
+system.methodHelp with deprecation notice
+*/
+type MethodDescription struct {
+	Name       string
+	Result     string
+	Params     []ParamDescription
+	Help       string
+	Deprecated string
+}
+
+/*
+ParamDescription is name and xmlrpc type of method param
+*/
+type ParamDescription struct {
+	Name string
+	Type string
+}
+
+/*
+value returns description as xmlrpc struct value (members are in lower camel case)
+*/
+func (m MethodDescription) value() map[string]interface{} {
+	params := []interface{}{}
+	for _, param := range m.Params {
+		params = append(params, map[string]interface{}{"name": param.Name, "type": param.Type})
+	}
+	return map[string]interface{}{
+		"name":       m.Name,
+		"result":     m.Result,
+		"params":     params,
+		"help":       m.Help,
+		"deprecated": m.Deprecated,
+	}
+}
+
+/*
+DeprecationNotice returns deprecation paragraph of method help, method is deprecated same way as in go doc comments
+(paragraph that starts with "Deprecated: "). Empty string is returned for methods that are not deprecated.
+*/
+func DeprecationNotice(help string) string {
+	for _, paragraph := range strings.Split(help, "\n\n") {
+		paragraph = strings.TrimSpace(paragraph)
+		if strings.HasPrefix(paragraph, "Deprecated: ") {
+			return strings.Join(strings.Fields(paragraph), " ")
+		}
+	}
+	return ""
+}
+
+/*
+deprecationWarning returns value of Warning header for deprecation notice
+*/
+func deprecationWarning(notice string) string {
+	return "299 - " + strconv.Quote(notice)
+}
+
+/*
+splitMethod splits full method name to service namespace and method, namespace can contain dots (e.g. "v2.posts").
+*/
+func splitMethod(method string) (service, serviceMethod string) {
+	if index := strings.LastIndex(method, "."); index != -1 {
+		return method[:index], method[index+1:]
+	}
+	return "", method
+}
+
+/*
+fullMethodName returns method name with service namespace
+*/
+func fullMethodName(service, method string) string {
+	if service == "" {
+		return method
+	}
+	return service + "." + method
+}
@@ -0,0 +1,72 @@
+package xmlrpc
+
+import (
+	"context"
+	"io"
+
+	"github.com/beevik/etree"
+)
+
+/*
+CallValidator is called with method name and params element ("methodCall/params") of every call before it's
+dispatched to service. Returned error rejects call and is written as fault (use Errorf for custom fault code),
+params must not be modified.
+*/
+type CallValidator func(ctx context.Context, method string, params *etree.Element) error
+
+/*
+WithCallValidator adds validator of calls (quota checks, tenant routing, security filtering...), validators are
+called in order they were added and first error stops the call.
+*/
+func WithCallValidator(validator CallValidator) HandlerOption {
+	return func(h *handler) {
+		h.callValidators = append(h.callValidators, validator)
+	}
+}
+
+/*
+validateCall runs all call validators
+*/
+func (h *handler) validateCall(ctx context.Context, method string, params *etree.Element) error {
+	for _, validator := range h.callValidators {
+		if err := validator(ctx, method, params); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+/*
+ResponseProcessor is called with response document of every successful call before it's written, it can inspect
+and modify document (strip members for unprivileged callers, inject audit members...). Returned error is written
+as fault instead of response.
+*/
+type ResponseProcessor func(ctx context.Context, method string, doc *etree.Document) error
+
+/*
+WithResponseProcessor adds processor of responses, processors are called in order they were added. Responses
+written by other backends than etree (and streamed responses) are parsed to document first, so they lose their
+performance benefits.
+*/
+func WithResponseProcessor(processor ResponseProcessor) HandlerOption {
+	return func(h *handler) {
+		h.responseProcessors = append(h.responseProcessors, processor)
+	}
+}
+
+/*
+processResponse runs all response processors and returns processed document
+*/
+func (h *handler) processResponse(ctx context.Context, method string, res io.WriterTo) (io.WriterTo, error) {
+	doc, err := responseDocument(res)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, processor := range h.responseProcessors {
+		if err := processor(ctx, method, doc); err != nil {
+			return nil, err
+		}
+	}
+	return doc, nil
+}
@@ -0,0 +1,298 @@
+package xmlrpc
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// IdempotencyKeyHeader is http header that carries idempotency key of call
+	IdempotencyKeyHeader = "Idempotency-Key"
+
+	// DefaultIdempotencyCacheSize is count of responses kept by Idempotency by default
+	DefaultIdempotencyCacheSize = 10000
+)
+
+var (
+	// ErrIdempotencyKeyReused is returned by Idempotency middleware when key is sent again with different call
+	ErrIdempotencyKeyReused = Errorf(FaultInvalidRequest, "idempotency key reused by different call")
+)
+
+/*
+NewIdempotencyKey returns random idempotency key, caller sends same key with all retries of call
+*/
+func NewIdempotencyKey() string {
+	return newRequestID()
+}
+
+/*
+ContextWithIdempotencyKey returns new context with idempotency key
+*/
+func ContextWithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKeyContextKey, key)
+}
+
+/*
+IdempotencyKeyFromContext returns idempotency key of current call (stored by Idempotency middleware)
+*/
+func IdempotencyKeyFromContext(ctx context.Context) string {
+	key, _ := ctx.Value(idempotencyKeyContextKey).(string)
+	return key
+}
+
+/*
+WithForwardIdempotencyKey sends idempotency key with forwarded calls, key of incoming call is passed upstream
+(see IdempotencyKeyFromContext), calls without it get new key
+*/
+func WithForwardIdempotencyKey() ForwarderOption {
+	return WithForwardRequest(func(ctx context.Context, method string, r *http.Request) error {
+		key := IdempotencyKeyFromContext(ctx)
+		if key == "" {
+			key = NewIdempotencyKey()
+		}
+		r.Header.Set(IdempotencyKeyHeader, key)
+		return nil
+	})
+}
+
+/*
+NewIdempotencyTransport returns http transport for clients that sends idempotency key of request context (see
+ContextWithIdempotencyKey) in IdempotencyKeyHeader. Retries of call made with same context have same key, so
+server with Idempotency middleware executes call only once. Requests without key in context (or with header
+already set) are sent unchanged. Nil base is http.DefaultTransport.
+
+	ctx = xmlrpc.ContextWithIdempotencyKey(ctx, xmlrpc.NewIdempotencyKey())
+	// retries of request with ctx send same key
+*/
+func NewIdempotencyTransport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return idempotencyTransport{base: base}
+}
+
+/*
+idempotencyTransport sets IdempotencyKeyHeader of requests
+*/
+type idempotencyTransport struct {
+	base http.RoundTripper
+}
+
+func (i idempotencyTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	key := IdempotencyKeyFromContext(request.Context())
+	if key == "" || request.Header.Get(IdempotencyKeyHeader) != "" {
+		return i.base.RoundTrip(request)
+	}
+
+	// request must not be modified, it's cloned
+	clone := request.WithContext(request.Context())
+	clone.Header = http.Header{}
+	for name, values := range request.Header {
+		clone.Header[name] = values
+	}
+	clone.Header.Set(IdempotencyKeyHeader, key)
+	return i.base.RoundTrip(clone)
+}
+
+/*
+IdempotencyOption configures middleware of Idempotency
+*/
+type IdempotencyOption func(c *idempotencyCache)
+
+/*
+WithIdempotencyCacheSize sets count of kept responses (DefaultIdempotencyCacheSize by default), least recently
+used are evicted
+*/
+func WithIdempotencyCacheSize(size int) IdempotencyOption {
+	return func(c *idempotencyCache) {
+		c.size = size
+	}
+}
+
+/*
+Idempotency returns middleware that replays responses of calls with same idempotency key (IdempotencyKeyHeader)
+for ttl, so retried non-idempotent calls are executed only once. Retries that come while call is executed wait for
+its response. Keys are scoped by caller identity (use after auth middleware), key reused with different call body
+is rejected with ErrIdempotencyKeyReused. Calls without key and responses with 5xx status are not cached. At most
+DefaultIdempotencyCacheSize responses are kept (see WithIdempotencyCacheSize), retries of evicted calls are
+executed again.
+*/
+func Idempotency(ttl time.Duration, options ...IdempotencyOption) Middleware {
+	cache := &idempotencyCache{
+		ttl:  ttl,
+		size: DefaultIdempotencyCacheSize,
+	}
+	for _, option := range options {
+		option(cache)
+	}
+	cache.entries = newLRUCache(cache.size)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(IdempotencyKeyHeader)
+			if key == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			body, err := ioutil.ReadAll(r.Body)
+			if err != nil {
+				writeFault(w, r, Errorf(FaultInvalidRequest, "cannot read request: %v", err))
+				return
+			}
+			r.Body = ioutil.NopCloser(bytes.NewReader(body))
+			sum := sha256.Sum256(body)
+
+			ctx := ContextWithIdempotencyKey(r.Context(), key)
+			if identity := IdentityFromContext(r.Context()); identity != nil {
+				key = identity.Name + "\x00" + key
+			}
+
+			for {
+				entry, owner := cache.acquire(key, sum)
+				if owner {
+					serveIdempotent(next, w, r.WithContext(ctx), func(recorder *idempotencyRecorder) {
+						cache.release(key, entry, recorder)
+					})
+					return
+				}
+
+				if entry.sum != sum {
+					writeFault(w, r, ErrIdempotencyKeyReused)
+					return
+				}
+
+				select {
+				case <-entry.done:
+				case <-r.Context().Done():
+					return
+				}
+
+				// failed call is executed again by one of waiting retries
+				if !entry.ok {
+					continue
+				}
+
+				for name, values := range entry.header {
+					w.Header()[name] = values
+				}
+				w.WriteHeader(entry.status)
+				w.Write(entry.body)
+				return
+			}
+		})
+	}
+}
+
+/*
+serveIdempotent serves call and releases its recorded response, panicking call is released as 5xx response, so
+waiting retries don't wait forever
+*/
+func serveIdempotent(next http.Handler, w http.ResponseWriter, r *http.Request, release func(*idempotencyRecorder)) {
+	recorder := &idempotencyRecorder{ResponseWriter: w, status: http.StatusOK}
+	completed := false
+	defer func() {
+		if !completed {
+			recorder.status = http.StatusInternalServerError
+		}
+		release(recorder)
+	}()
+
+	next.ServeHTTP(recorder, r)
+	completed = true
+}
+
+/*
+idempotencyCache holds responses by idempotency key, least recently used are evicted when cache is full
+*/
+type idempotencyCache struct {
+	mutex   sync.Mutex
+	ttl     time.Duration
+	size    int
+	entries *lruCache
+}
+
+/*
+idempotencyEntry is response of call, done is closed when call finished
+*/
+type idempotencyEntry struct {
+	done    chan struct{}
+	sum     [sha256.Size]byte
+	expires time.Time
+	ok      bool
+
+	status int
+	header http.Header
+	body   []byte
+}
+
+/*
+acquire returns entry of key, owner is true when caller executes call and must release entry
+*/
+func (c *idempotencyCache) acquire(key string, sum [sha256.Size]byte) (entry *idempotencyEntry, owner bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	now := time.Now()
+	if value, ok := c.entries.get(key); ok {
+		if entry = value.(*idempotencyEntry); !entry.ok || now.Before(entry.expires) {
+			return entry, false
+		}
+	}
+
+	entry = &idempotencyEntry{done: make(chan struct{}), sum: sum}
+	c.entries.put(key, entry)
+	return entry, true
+}
+
+/*
+release stores recorded response, responses with 5xx status are not stored
+*/
+func (c *idempotencyCache) release(key string, entry *idempotencyEntry, recorder *idempotencyRecorder) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if recorder.status < http.StatusInternalServerError {
+		entry.ok = true
+		entry.expires = time.Now().Add(c.ttl)
+		entry.status = recorder.status
+		entry.header = http.Header{}
+		for name, values := range recorder.Header() {
+			entry.header[name] = append([]string(nil), values...)
+		}
+		entry.body = recorder.body.Bytes()
+	} else if value, ok := c.entries.get(key); ok && value == entry {
+		// key could be evicted and acquired again meanwhile
+		c.entries.remove(key)
+	}
+	close(entry.done)
+}
+
+/*
+idempotencyRecorder writes response and keeps its copy
+*/
+type idempotencyRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (r *idempotencyRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.wroteHeader = true
+		r.status = status
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *idempotencyRecorder) Write(data []byte) (int, error) {
+	r.wroteHeader = true
+	r.body.Write(data)
+	return r.ResponseWriter.Write(data)
+}
@@ -0,0 +1,93 @@
+package xmlrpc
+
+import (
+	"testing"
+	"time"
+)
+
+func TestIdempotency(t *testing.T) {
+	service := &testService{name: "test"}
+	h := NewHandler()
+	if err := h.AddService(service, "test"); err != nil {
+		t.Fatalf("cannot add service: %v", err)
+	}
+	handler := Chain(h, Idempotency(time.Minute))
+
+	call := func(key, params string, identity *Identity) (string, *Fault) {
+		request := newTestCall("test.Echo", params)
+		if key != "" {
+			request.Header.Set(IdempotencyKeyHeader, key)
+		}
+		if identity != nil {
+			request = request.WithContext(ContextWithIdentity(request.Context(), identity))
+		}
+		response := serveTestCall(handler, request)
+		return response.Body.String(), responseFault(response)
+	}
+	expectCalls := func(expected int) {
+		t.Helper()
+		if calls, _ := service.called(); calls != expected {
+			t.Errorf("expected %v calls, got %v", expected, calls)
+		}
+	}
+
+	first, fault := call("key", "<param><value>1</value></param>", nil)
+	if fault != nil {
+		t.Fatalf("unexpected fault %v", fault)
+	}
+	expectCalls(1)
+
+	// retry gets same response without call
+	if retry, _ := call("key", "<param><value>1</value></param>", nil); retry != first {
+		t.Errorf("expected replayed response %v, got %v", first, retry)
+	}
+	expectCalls(1)
+
+	// key reused with different call is rejected
+	if _, fault = call("key", "<param><value>2</value></param>", nil); fault == nil || fault.Code() != errorCode(ErrIdempotencyKeyReused) {
+		t.Errorf("expected key reused fault, got %v", fault)
+	}
+	expectCalls(1)
+
+	// keys are scoped by caller
+	call("key", "<param><value>1</value></param>", &Identity{Name: "other"})
+	expectCalls(2)
+
+	// calls without key are always executed
+	call("", "<param><value>1</value></param>", nil)
+	call("", "<param><value>1</value></param>", nil)
+	expectCalls(4)
+}
+
+func TestIdempotencyCacheSize(t *testing.T) {
+	service := &testService{name: "test"}
+	h := NewHandler()
+	if err := h.AddService(service, "test"); err != nil {
+		t.Fatalf("cannot add service: %v", err)
+	}
+	handler := Chain(h, Idempotency(time.Minute, WithIdempotencyCacheSize(2)))
+
+	call := func(key string) {
+		request := newTestCall("test.Echo", "<param><value>1</value></param>")
+		request.Header.Set(IdempotencyKeyHeader, key)
+		serveTestCall(handler, request)
+	}
+	expectCalls := func(expected int) {
+		t.Helper()
+		if calls, _ := service.called(); calls != expected {
+			t.Errorf("expected %v calls, got %v", expected, calls)
+		}
+	}
+
+	call("a")
+	call("b")
+	call("a")
+	expectCalls(2)
+
+	// b is least recently used, it's evicted
+	call("c")
+	call("a")
+	expectCalls(3)
+	call("b")
+	expectCalls(4)
+}
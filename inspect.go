@@ -0,0 +1,348 @@
+package xmlrpc
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/beevik/etree"
+)
+
+/*
+Payload is decoded methodCall or methodResponse, it's used for debugging of captured payloads
+*/
+type Payload struct {
+	// Kind is "call", "response" or "fault"
+	Kind string `json:"kind"`
+
+	// Method is name of called method (calls only)
+	Method string `json:"method,omitempty"`
+
+	// Params are decoded values (fault is single struct param)
+	Params []interface{} `json:"params"`
+
+	// Violations are spec violations found in payload
+	Violations []Violation `json:"violations,omitempty"`
+}
+
+/*
+Violation is violation of xmlrpc specification found in payload
+*/
+type Violation struct {
+	// Path of element (e.g. "params/1/struct/name")
+	Path string `json:"path"`
+
+	// Message describes violation
+	Message string `json:"message"`
+}
+
+/*
+Inspect decodes captured methodCall or methodResponse document to dynamic values and checks it against
+specification. Values are decoded as int, float64, bool, string, time.Time, []byte, []interface{} and
+map[string]interface{}. Error is returned only when document cannot be parsed, spec violations are reported
+in payload.
+*/
+func Inspect(r io.Reader) (payload *Payload, err error) {
+	doc := etree.NewDocument()
+	if _, err = doc.ReadFrom(r); err != nil {
+		return nil, parseError(err)
+	}
+
+	root := doc.Root()
+	if root == nil {
+		return nil, Errorf(FaultInvalidRequest, "document has no root element")
+	}
+
+	i := &inspector{}
+	payload = &Payload{}
+
+	switch root.Tag {
+	case "methodCall":
+		payload.Kind = "call"
+		if name := childElement(root, "methodName"); name == nil {
+			i.violation(root.Tag, "methodName not found")
+		} else {
+			payload.Method = strings.TrimSpace(name.Text())
+			if !isMethodName(payload.Method) {
+				i.violation("methodName", "invalid characters in method name %q", payload.Method)
+			}
+		}
+		payload.Params = i.params(root, false)
+	case "methodResponse":
+		payload.Kind = "response"
+		if fault := childElement(root, "fault"); fault != nil {
+			payload.Kind = "fault"
+			payload.Params = []interface{}{i.fault(fault)}
+		} else {
+			payload.Params = i.params(root, true)
+		}
+	default:
+		i.violation(root.Tag, "root element must be methodCall or methodResponse")
+	}
+
+	payload.Violations = i.violations
+	return
+}
+
+/*
+inspector collects violations while payload is decoded
+*/
+type inspector struct {
+	violations []Violation
+}
+
+func (i *inspector) violation(path string, format string, args ...interface{}) {
+	i.violations = append(i.violations, Violation{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+/*
+params decodes params of call or response, response must have exactly one param
+*/
+func (i *inspector) params(root *etree.Element, response bool) (result []interface{}) {
+	params := childElement(root, "params")
+	if params == nil {
+		i.violation(root.Tag, "params not found")
+		return
+	}
+
+	for index, param := range params.ChildElements() {
+		path := fmt.Sprintf("params/%d", index)
+		if param.Tag != "param" {
+			i.violation(path, "unexpected element %v", param.Tag)
+			continue
+		}
+		value := childElement(param, "value")
+		if value == nil {
+			i.violation(path, "param without value")
+			continue
+		}
+		result = append(result, i.value(value, path))
+	}
+
+	if response && len(result) != 1 {
+		i.violation("params", "response must have exactly one param, got %d", len(result))
+	}
+
+	return
+}
+
+/*
+fault decodes fault struct and checks its members
+*/
+func (i *inspector) fault(fault *etree.Element) interface{} {
+	value := childElement(fault, "value")
+	if value == nil {
+		i.violation("fault", "fault without value")
+		return nil
+	}
+
+	result := i.value(value, "fault")
+	members, ok := result.(map[string]interface{})
+	if !ok {
+		i.violation("fault", "fault value must be struct")
+		return result
+	}
+
+	if _, ok := members["faultCode"].(int); !ok {
+		i.violation("fault", "faultCode must be int")
+	}
+	if _, ok := members["faultString"].(string); !ok {
+		i.violation("fault", "faultString must be string")
+	}
+
+	return result
+}
+
+/*
+value decodes value element
+*/
+func (i *inspector) value(value *etree.Element, path string) interface{} {
+	children := value.ChildElements()
+
+	switch len(children) {
+	case 0:
+		// value without type is string
+		return value.Text()
+	case 1:
+	default:
+		i.violation(path, "value has %d type elements", len(children))
+	}
+
+	typ := children[0]
+	path += "/" + typ.Tag
+	text := strings.TrimSpace(typ.Text())
+
+	switch typ.Tag {
+	case "int", "i4":
+		result, err := strconv.ParseInt(text, 10, 64)
+		if err != nil {
+			i.violation(path, "invalid int %q", text)
+		} else if result < math.MinInt32 || result > math.MaxInt32 {
+			i.violation(path, "int %v out of 32 bit range", result)
+		}
+		return int(result)
+	case "i8":
+		i.violation(path, "i8 is extension, not in specification")
+		result, err := strconv.ParseInt(text, 10, 64)
+		if err != nil {
+			i.violation(path, "invalid int %q", text)
+		}
+		return int(result)
+	case "boolean":
+		if text != "0" && text != "1" {
+			i.violation(path, "boolean must be 0 or 1, got %q", text)
+		}
+		return text == "1"
+	case "string":
+		return typ.Text()
+	case "double":
+		result, err := ParseDouble(text)
+		if err != nil {
+			i.violation(path, "invalid double %q", text)
+		}
+		return result
+	case "dateTime.iso8601":
+		result, err := DefaultDateTimePolicy.Parse(text)
+		if err != nil {
+			i.violation(path, "invalid dateTime %q", text)
+		}
+		return result
+	case "dateTime":
+		i.violation(path, "dateTime is extension, not in specification")
+		result, err := DefaultDateTimePolicy.Parse(text)
+		if err != nil {
+			i.violation(path, "invalid dateTime %q", text)
+		}
+		return result
+	case "base64":
+		result, err := base64.StdEncoding.DecodeString(stripSpace(typ.Text()))
+		if err != nil {
+			i.violation(path, "invalid base64")
+		}
+		return result
+	case "nil":
+		i.violation(path, "nil is extension, not in specification")
+		return nil
+	case "struct":
+		return i.structValue(typ, path)
+	case "array":
+		return i.arrayValue(typ, path)
+	}
+
+	i.violation(path, "unknown type %v", typ.Tag)
+	return typ.Text()
+}
+
+func (i *inspector) structValue(element *etree.Element, path string) interface{} {
+	result := map[string]interface{}{}
+
+	for index, member := range element.ChildElements() {
+		memberPath := fmt.Sprintf("%v/%d", path, index)
+		if member.Tag != "member" {
+			i.violation(memberPath, "unexpected element %v", member.Tag)
+			continue
+		}
+
+		name, value, err := XPathStructMember(member)
+		if err != nil {
+			i.violation(memberPath, "%v", err)
+			continue
+		}
+		if _, ok := result[name]; ok {
+			i.violation(memberPath, "duplicate member %v", name)
+		}
+		result[name] = i.value(value, path+"/"+name)
+	}
+
+	return result
+}
+
+func (i *inspector) arrayValue(element *etree.Element, path string) interface{} {
+	result := []interface{}{}
+
+	data := childElement(element, "data")
+	if data == nil {
+		i.violation(path, "array without data")
+		return result
+	}
+
+	for index, value := range data.ChildElements() {
+		valuePath := fmt.Sprintf("%v/%d", path, index)
+		if value.Tag != "value" {
+			i.violation(valuePath, "unexpected element %v", value.Tag)
+			continue
+		}
+		result = append(result, i.value(value, valuePath))
+	}
+
+	return result
+}
+
+/*
+isMethodName returns whether name contains only characters allowed by specification (A-Z, a-z, 0-9, _, ., :, /)
+*/
+func isMethodName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case r == '_', r == '.', r == ':', r == '/':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+/*
+FprintValue prints dynamic value (as returned by Inspect) to w with its xmlrpc type, nested values are indented
+*/
+func FprintValue(w io.Writer, value interface{}, indent string) {
+	switch v := value.(type) {
+	case nil:
+		fmt.Fprint(w, "nil")
+	case int:
+		fmt.Fprintf(w, "int %d", v)
+	case bool:
+		fmt.Fprintf(w, "boolean %v", v)
+	case string:
+		fmt.Fprintf(w, "string %q", v)
+	case float64:
+		fmt.Fprintf(w, "double %v", v)
+	case time.Time:
+		fmt.Fprintf(w, "dateTime %v", v.Format(time.RFC3339))
+	case []byte:
+		fmt.Fprintf(w, "base64 (%d bytes)", len(v))
+	case []interface{}:
+		fmt.Fprintln(w, "array [")
+		for i, item := range v {
+			fmt.Fprintf(w, "%v  %d: ", indent, i)
+			FprintValue(w, item, indent+"  ")
+			fmt.Fprintln(w)
+		}
+		fmt.Fprint(w, indent+"]")
+	case map[string]interface{}:
+		names := make([]string, 0, len(v))
+		for name := range v {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		fmt.Fprintln(w, "struct {")
+		for _, name := range names {
+			fmt.Fprintf(w, "%v  %v: ", indent, name)
+			FprintValue(w, v[name], indent+"  ")
+			fmt.Fprintln(w)
+		}
+		fmt.Fprint(w, indent+"}")
+	default:
+		fmt.Fprint(w, v)
+	}
+}
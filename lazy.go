@@ -0,0 +1,131 @@
+package xmlrpc
+
+import (
+	"sync"
+
+	"github.com/beevik/etree"
+)
+
+/*
+LazyStruct is struct value that keeps its members undecoded, members are decoded when they are accessed (and
+only once). It's useful for big structs of which callers read only few members. Service methods can accept and
+return *xmlrpc.LazyStruct (it's written back verbatim).
+*/
+type LazyStruct struct {
+	mutex   sync.Mutex
+	name    string
+	element *etree.Element
+	names   []string
+	members map[string]*etree.Element
+	decoded map[string]*Value
+}
+
+/*
+XPathValueGetLazyStruct returns lazy struct of value element, only member names are read
+*/
+func XPathValueGetLazyStruct(element *etree.Element, name string) (result *LazyStruct, err error) {
+	var members []*etree.Element
+	if members, err = XPathValueGetStruct(element, name); err != nil {
+		return
+	}
+
+	result = &LazyStruct{
+		name:    name,
+		element: element,
+		members: make(map[string]*etree.Element, len(members)),
+		decoded: map[string]*Value{},
+	}
+	for _, member := range members {
+		var (
+			memberName string
+			value      *etree.Element
+		)
+		if memberName, value, err = XPathStructMember(member); err != nil {
+			return nil, err
+		}
+		if _, ok := result.members[memberName]; !ok {
+			result.names = append(result.names, memberName)
+		}
+		result.members[memberName] = value
+	}
+	return result, nil
+}
+
+/*
+ParseLazyStruct returns lazy struct of raw value
+*/
+func ParseLazyStruct(raw RawValue) (*LazyStruct, error) {
+	element, err := raw.element()
+	if err != nil {
+		return nil, err
+	}
+	return XPathValueGetLazyStruct(element, "value")
+}
+
+/*
+Names returns names of members in order of document
+*/
+func (l *LazyStruct) Names() []string {
+	return append([]string(nil), l.names...)
+}
+
+/*
+Has returns whether struct has member
+*/
+func (l *LazyStruct) Has(name string) bool {
+	_, ok := l.members[name]
+	return ok
+}
+
+/*
+Member decodes member, nil is returned when struct doesn't have it
+*/
+func (l *LazyStruct) Member(name string) (*Value, error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if value, ok := l.decoded[name]; ok {
+		return value, nil
+	}
+	element, ok := l.members[name]
+	if !ok {
+		return nil, nil
+	}
+
+	value, err := XPathValueGetValue(element, l.name+"."+name)
+	if err != nil {
+		return nil, err
+	}
+	l.decoded[name] = value
+	return value, nil
+}
+
+/*
+Element returns undecoded value element of member (e.g. for XPath helpers), nil when struct doesn't have it
+*/
+func (l *LazyStruct) Element(name string) *Element {
+	return l.members[name]
+}
+
+/*
+Value decodes whole struct
+*/
+func (l *LazyStruct) Value() (*Value, error) {
+	result := NewStruct()
+	for _, name := range l.names {
+		value, err := l.Member(name)
+		if err != nil {
+			return nil, err
+		}
+		result.Set(name, value)
+	}
+	return result, nil
+}
+
+/*
+Raw returns struct as raw value (it's written verbatim)
+*/
+func (l *LazyStruct) Raw() RawValue {
+	raw, _ := XPathValueGetRaw(l.element, l.name)
+	return raw
+}
@@ -0,0 +1,151 @@
+package xmlrpc
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/xml"
+	"io"
+)
+
+var (
+	// ErrRequestTooLarge is returned when request body exceeds WithMaxRequestSize
+	ErrRequestTooLarge = Errorf(FaultInvalidRequest, "request too large")
+)
+
+/*
+WithMaxArrayElements limits number of elements of every array in request params. Requests with bigger arrays
+are rejected while they are read, before their document is built.
+*/
+func WithMaxArrayElements(max int) HandlerOption {
+	return func(h *handler) {
+		h.maxArrayElements = max
+	}
+}
+
+/*
+WithMaxStructMembers limits number of members of every struct in request params. Requests with bigger structs
+are rejected while they are read, before their document is built.
+*/
+func WithMaxStructMembers(max int) HandlerOption {
+	return func(h *handler) {
+		h.maxStructMembers = max
+	}
+}
+
+/*
+WithMaxBase64Size limits decoded size (in bytes) of every base64 value in request params. Requests with bigger
+values are rejected while they are read, before their document is built.
+*/
+func WithMaxBase64Size(max int) HandlerOption {
+	return func(h *handler) {
+		h.maxBase64Size = max
+	}
+}
+
+/*
+WithMaxRequestSize limits size (in bytes) of request body. Requests with bigger bodies are rejected once limit is
+read, whether or not other limits are set.
+*/
+func WithMaxRequestSize(max int64) HandlerOption {
+	return func(h *handler) {
+		h.maxRequestSize = max
+	}
+}
+
+/*
+sizeLimitedReader reads at most max bytes of request, reading more than that fails with ErrRequestTooLarge
+*/
+type sizeLimitedReader struct {
+	reader io.Reader
+	max    int64
+}
+
+/*
+Read reads from underlying reader until limit is exceeded
+*/
+func (s *sizeLimitedReader) Read(p []byte) (n int, err error) {
+	if s.max < 0 {
+		return 0, ErrRequestTooLarge
+	}
+	if int64(len(p)) > s.max+1 {
+		p = p[:s.max+1]
+	}
+	n, err = s.reader.Read(p)
+	if s.max -= int64(n); s.max < 0 {
+		return 0, ErrRequestTooLarge
+	}
+	return
+}
+
+/*
+passThroughCharsetReader leaves data of other charsets as they are, same as etree does by default
+*/
+func passThroughCharsetReader(charset string, input io.Reader) (io.Reader, error) {
+	return input, nil
+}
+
+/*
+limitsCounter is element of request that is counted by readLimited
+*/
+type limitsCounter struct {
+	tag   string
+	count int
+}
+
+/*
+readLimited reads request and checks number of array elements, struct members and size of base64 values while
+request is tokenized, before document is built, so oversized requests are rejected without allocating their
+document. Zero limit means no limit. Other charsets are read by charsetReader, the one parser uses, so requests
+that cannot be tokenized would not be parsed either and they are rejected.
+*/
+func readLimited(body io.Reader, charsetReader func(string, io.Reader) (io.Reader, error), maxArrayElements,
+	maxStructMembers, maxBase64Size int) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	decoder := xml.NewDecoder(io.TeeReader(body, buf))
+	decoder.CharsetReader = charsetReader
+	if decoder.CharsetReader == nil {
+		decoder.CharsetReader = passThroughCharsetReader
+	}
+
+	var stack []limitsCounter
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return buf.Bytes(), nil
+		}
+		if err != nil {
+			if err == ErrRequestTooLarge {
+				return nil, err
+			}
+			return nil, parseError(err)
+		}
+
+		switch token := token.(type) {
+		case xml.StartElement:
+			if len(stack) > 0 {
+				parent := &stack[len(stack)-1]
+				parent.count++
+				switch {
+				case parent.tag == "data" && maxArrayElements > 0 && parent.count > maxArrayElements:
+					return nil, Errorf(FaultInvalidParams, "array has more than %d elements", maxArrayElements)
+				case parent.tag == "struct" && maxStructMembers > 0 && parent.count > maxStructMembers:
+					return nil, Errorf(FaultInvalidParams, "struct has more than %d members", maxStructMembers)
+				}
+			}
+			stack = append(stack, limitsCounter{tag: token.Name.Local})
+		case xml.EndElement:
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+		case xml.CharData:
+			// count of base64 characters without whitespace
+			if len(stack) > 0 && stack[len(stack)-1].tag == "base64" && maxBase64Size > 0 {
+				current := &stack[len(stack)-1]
+				current.count += len(stripSpace(string(token)))
+				if base64.StdEncoding.DecodedLen(current.count) > maxBase64Size {
+					return nil, Errorf(FaultInvalidParams, "base64 value has more than %d bytes", maxBase64Size)
+				}
+			}
+		}
+	}
+}
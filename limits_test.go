@@ -0,0 +1,173 @@
+package xmlrpc
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/beevik/etree"
+)
+
+func TestLimitsBeforeDispatch(t *testing.T) {
+	service := &testService{name: "test"}
+	h := NewHandler(WithMaxArrayElements(3), WithMaxStructMembers(2))
+	if err := h.AddService(service, "test"); err != nil {
+		t.Fatalf("cannot add service: %v", err)
+	}
+
+	array := "<array><data>" + strings.Repeat("<value><int>1</int></value>", 4) + "</data></array>"
+	for _, item := range []struct {
+		value string
+		ok    bool
+	}{
+		{"<array><data><value>1</value><value>2</value><value>3</value></data></array>", true},
+		{array, false},
+		{"<struct><member><name>a</name><value>1</value></member><member><name>b</name><value>2</value></member></struct>", true},
+		{"<struct>" + strings.Repeat("<member><name>a</name><value>1</value></member>", 3) + "</struct>", false},
+		{"<struct><member><name>a</name><value>" + array + "</value></member></struct>", false},
+	} {
+		calls, _ := service.called()
+		response := serveTestCall(h, newTestCall("test.Echo", "<param><value>"+item.value+"</value></param>"))
+
+		if fault := strings.Contains(response.Body.String(), "<fault>"); fault == item.ok {
+			t.Errorf("%v: expected fault %v, got %v", item.value, !item.ok, response.Body.String())
+		}
+		if after, _ := service.called(); (after > calls) != item.ok {
+			t.Errorf("%v: expected dispatch %v", item.value, item.ok)
+		}
+	}
+}
+
+func TestLimits(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register("Len", func(ctx context.Context, params ...*Value) (*Value, error) {
+		return NewInt(params[0].Len()), nil
+	})
+	h := NewHandler(WithMaxArrayElements(3), WithMaxStructMembers(2), WithMaxBase64Size(6))
+	h.AddService(registry, "x")
+
+	array := func(n int) string {
+		return "<array><data>" + strings.Repeat("<value><int>1</int></value>", n) + "</data></array>"
+	}
+	strukt := func(n int) string {
+		result := "<struct>"
+		for i := 0; i < n; i++ {
+			result += "<member><name>" + string(rune('a'+i)) + "</name><value>1</value></member>"
+		}
+		return result + "</struct>"
+	}
+
+	for _, item := range []struct {
+		value string
+		ok    bool
+	}{
+		{array(3), true},
+		{array(4), false},
+		{strukt(2), true},
+		{strukt(3), false},
+		{"<array><data><value>" + array(4) + "</value></data></array>", false},
+		{"<array><data><value>" + strukt(3) + "</value></data></array>", false},
+		{"<base64>YWJjZGVm</base64>", true},
+		{"<base64>YWJj\nZGVm</base64>", true},
+		{"<base64>YWJj\nZGVmZ2g=</base64>", false},
+	} {
+		call := `<?xml version="1.0"?><methodCall><methodName>x.Len</methodName><params><param><value>` +
+			item.value + `</value></param></params></methodCall>`
+		_, _, err := h.(*handler).handle(context.Background(), bytes.NewReader([]byte(call)), nil)
+		if (err == nil) != item.ok {
+			t.Errorf("%v: expected ok %v, got error %v", item.value, item.ok, err)
+		}
+		if err != nil && errorCode(err) != FaultInvalidParams {
+			t.Errorf("%v: expected invalid params, got %v", item.value, err)
+		}
+	}
+
+	// documents that cannot be tokenized are rejected as parse errors
+	if _, _, err := h.(*handler).handle(context.Background(), strings.NewReader("<methodCall><a></b></methodCall>"), nil); errorCode(err) != FaultParseError {
+		t.Errorf("expected parse error, got %v", err)
+	}
+}
+
+func TestLimitsCharset(t *testing.T) {
+	service := &testService{name: "test"}
+	h := NewHandler(WithMaxArrayElements(3))
+	if err := h.AddService(service, "test"); err != nil {
+		t.Fatalf("cannot add service: %v", err)
+	}
+
+	for _, item := range []struct {
+		elements int
+		ok       bool
+	}{
+		{3, true},
+		{1000, false},
+	} {
+		call := `<?xml version="1.0" encoding="ISO-8859-1"?><methodCall><methodName>test.Echo</methodName>` +
+			`<params><param><value><array><data>` + strings.Repeat("<value><int>1</int></value>", item.elements) +
+			`</data></array></value></param></params></methodCall>`
+		calls, _ := service.called()
+		_, _, err := h.(*handler).handle(context.Background(), strings.NewReader(call), nil)
+		if (err == nil) != item.ok {
+			t.Errorf("%v elements: expected ok %v, got error %v", item.elements, item.ok, err)
+		}
+		if after, _ := service.called(); (after > calls) != item.ok {
+			t.Errorf("%v elements: expected dispatch %v", item.elements, item.ok)
+		}
+	}
+}
+
+func TestMaxRequestSize(t *testing.T) {
+	call := `<?xml version="1.0"?><methodCall><methodName>test.Echo</methodName><params></params></methodCall>`
+
+	for _, item := range []struct {
+		options []HandlerOption
+		size    int64
+		ok      bool
+	}{
+		{nil, int64(len(call)), true},
+		{nil, int64(len(call)) - 1, false},
+		{[]HandlerOption{WithMaxArrayElements(3)}, int64(len(call)), true},
+		{[]HandlerOption{WithMaxArrayElements(3)}, int64(len(call)) - 1, false},
+	} {
+		h := NewHandler(append(item.options, WithMaxRequestSize(item.size))...)
+		h.AddService(&testService{name: "test"}, "test")
+
+		_, _, err := h.(*handler).handle(context.Background(), strings.NewReader(call), nil)
+		if (err == nil) != item.ok {
+			t.Errorf("size %v: expected ok %v, got error %v", item.size, item.ok, err)
+		}
+		if err != nil && err != ErrRequestTooLarge {
+			t.Errorf("size %v: expected %v, got %v", item.size, ErrRequestTooLarge, err)
+		}
+	}
+}
+
+func TestReaderLimit(t *testing.T) {
+	for _, item := range []struct {
+		value string
+		data  string
+		ok    bool
+	}{
+		{"aGVs\nbG8g\r\n d29y bGQ=", "hello world", true},
+		{"aGVsbG8gd29ybA==", "hello worl", true},
+		{"aGVsbG8gd29ybGQh", "", false},
+	} {
+		doc := etree.NewDocument()
+		if err := doc.ReadFromString("<value><base64>" + item.value + "</base64></value>"); err != nil {
+			t.Fatalf("invalid value: %v", err)
+		}
+
+		reader, err := XPathValueGetReaderLimit(doc.Root(), "data", 11)
+		if (err == nil) != item.ok {
+			t.Errorf("%v: expected ok %v, got %v", item.value, item.ok, err)
+		}
+		if err != nil {
+			continue
+		}
+		if data, err := ioutil.ReadAll(reader); err != nil || string(data) != item.data {
+			t.Errorf("%v: expected %q, got %q (%v)", item.value, item.data, data, err)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+/*
+Package loader loads services of go plugins (built with "go build -buildmode=plugin") to handler. It's separate
+package, so programs that don't load plugins don't depend on package plugin (and cgo).
+*/
+package loader
+
+import (
+	"fmt"
+	"plugin"
+
+	"github.com/phonkee/go-xmlrpc"
+)
+
+const (
+	// ProviderSymbol is name of exported variable of plugin with its xmlrpc.ServiceProvider
+	ProviderSymbol = "Provider"
+)
+
+/*
+Open opens go plugin and adds services of its provider (variable named ProviderSymbol) to handler
+*/
+func Open(h xmlrpc.Handler, path string) error {
+	p, err := plugin.Open(path)
+	if err != nil {
+		return err
+	}
+
+	symbol, err := p.Lookup(ProviderSymbol)
+	if err != nil {
+		return err
+	}
+
+	var provider xmlrpc.ServiceProvider
+	switch symbol := symbol.(type) {
+	case *xmlrpc.ServiceProvider:
+		provider = *symbol
+	case xmlrpc.ServiceProvider:
+		provider = symbol
+	default:
+		return fmt.Errorf("plugin %v: %v is %T, not xmlrpc.ServiceProvider", path, ProviderSymbol, symbol)
+	}
+
+	return xmlrpc.AddProviders(h, provider)
+}
@@ -0,0 +1,88 @@
+package xmlrpc
+
+import (
+	"container/list"
+	"sync"
+)
+
+/*
+lruCache keeps at most size values, least recently used value is evicted when new one is added to full cache. It's
+safe for concurrent use.
+*/
+type lruCache struct {
+	mutex sync.Mutex
+	size  int
+	items map[string]*list.Element
+	order *list.List
+}
+
+/*
+lruItem is value of lruCache with its key (needed for eviction)
+*/
+type lruItem struct {
+	key   string
+	value interface{}
+}
+
+/*
+newLRUCache returns cache of given size (at least 1)
+*/
+func newLRUCache(size int) *lruCache {
+	if size < 1 {
+		size = 1
+	}
+	return &lruCache{
+		size:  size,
+		items: map[string]*list.Element{},
+		order: list.New(),
+	}
+}
+
+/*
+get returns value and marks it as recently used
+*/
+func (c *lruCache) get(key string) (interface{}, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	element, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(element)
+	return element.Value.(*lruItem).value, true
+}
+
+/*
+put adds or replaces value, least recently used value is evicted when cache is full
+*/
+func (c *lruCache) put(key string, value interface{}) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if element, ok := c.items[key]; ok {
+		element.Value.(*lruItem).value = value
+		c.order.MoveToFront(element)
+		return
+	}
+
+	c.items[key] = c.order.PushFront(&lruItem{key: key, value: value})
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*lruItem).key)
+	}
+}
+
+/*
+remove removes value
+*/
+func (c *lruCache) remove(key string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if element, ok := c.items[key]; ok {
+		c.order.Remove(element)
+		delete(c.items, key)
+	}
+}
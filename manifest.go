@@ -0,0 +1,82 @@
+package xmlrpc
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+/*
+SignatureLister is implemented by generated services, it returns xmlrpc types of methods (result type first,
+followed by types of params)
+*/
+type SignatureLister interface {
+	MethodSignatures() map[string][]string
+}
+
+/*
+Manifest maps full method names (with service namespace) to their xmlrpc types, result type is first followed by
+types of params. Handler serves it as system.methodSignature and clients can keep manifest of server they were
+written against.
+*/
+type Manifest map[string][]string
+
+/*
+CompatibilityError lists all differences between expected and actual manifest
+*/
+type CompatibilityError struct {
+	Problems []string
+}
+
+/*
+Error returns all problems in single message
+*/
+func (c *CompatibilityError) Error() string {
+	return "incompatible server: " + strings.Join(c.Problems, "; ")
+}
+
+/*
+CheckCompatibility compares manifest expected by client with manifest of server (e.g. collected with
+system.listMethods and system.methodSignature) and returns *CompatibilityError with all missing methods and
+changed signatures. Methods that server has on top of expected ones are not reported.
+*/
+func CheckCompatibility(expected, actual Manifest) error {
+	names := make([]string, 0, len(expected))
+	for name := range expected {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	problems := []string{}
+	for _, name := range names {
+		got, ok := actual[name]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("method %v not found", name))
+			continue
+		}
+		if !equalSignature(expected[name], got) {
+			problems = append(problems, fmt.Sprintf("method %v: expected (%v), got (%v)", name,
+				strings.Join(expected[name], ", "), strings.Join(got, ", ")))
+		}
+	}
+
+	if len(problems) > 0 {
+		return &CompatibilityError{Problems: problems}
+	}
+	return nil
+}
+
+/*
+equalSignature returns whether signatures have same types
+*/
+func equalSignature(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
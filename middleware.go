@@ -0,0 +1,18 @@
+package xmlrpc
+
+import "net/http"
+
+/*
+Middleware wraps http.Handler with additional functionality (signature verification, authentication, ...)
+*/
+type Middleware func(http.Handler) http.Handler
+
+/*
+Chain wraps handler with given middlewares. First middleware is the outermost one, so it's called first.
+*/
+func Chain(handler http.Handler, middlewares ...Middleware) http.Handler {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+	return handler
+}
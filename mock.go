@@ -0,0 +1,50 @@
+package xmlrpc
+
+import "sync"
+
+/*
+MockCall is single recorded call of generated mock
+*/
+type MockCall struct {
+	Method string
+	Args   []interface{}
+}
+
+/*
+MockRecorder records calls of generated mocks, it's safe for concurrent use
+*/
+type MockRecorder struct {
+	mutex sync.Mutex
+	calls []MockCall
+}
+
+/*
+Record records call of method with given args
+*/
+func (m *MockRecorder) Record(method string, args ...interface{}) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.calls = append(m.calls, MockCall{Method: method, Args: args})
+}
+
+/*
+Calls returns all recorded calls in order they were made
+*/
+func (m *MockRecorder) Calls() []MockCall {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return append([]MockCall{}, m.calls...)
+}
+
+/*
+CallsOf returns recorded calls of given method
+*/
+func (m *MockRecorder) CallsOf(method string) []MockCall {
+	result := []MockCall{}
+	for _, call := range m.Calls() {
+		if call.Method == method {
+			result = append(result, call)
+		}
+	}
+	return result
+}
@@ -0,0 +1,37 @@
+package xmlrpc
+
+import "github.com/beevik/etree"
+
+/*
+WithStrictNamespaces rejects requests with namespaced elements or namespace declarations. By default namespaces
+are ignored and elements are matched by their local names, since some toolkits emit prefixes on value elements
+(this also rejects Apache extensions as <ex:i8>).
+*/
+func WithStrictNamespaces() HandlerOption {
+	return func(h *handler) {
+		h.strictNamespaces = true
+	}
+}
+
+/*
+checkNamespaces returns error when element or any of its descendants is namespaced or declares namespace
+*/
+func checkNamespaces(element *etree.Element) error {
+	if element.Space != "" {
+		return Errorf(FaultInvalidRequest, "namespaced element %v is not allowed", element.FullTag())
+	}
+
+	for _, attr := range element.Attr {
+		if attr.Space == "xmlns" || (attr.Space == "" && attr.Key == "xmlns") {
+			return Errorf(FaultInvalidRequest, "namespace declaration in %v is not allowed", element.Tag)
+		}
+	}
+
+	for _, child := range element.ChildElements() {
+		if err := checkNamespaces(child); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
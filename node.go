@@ -0,0 +1,123 @@
+package xmlrpc
+
+import (
+	"io"
+
+	"github.com/beevik/etree"
+)
+
+/*
+Node is element of response document built by code generated with etree backend. Generated code uses only these
+methods (and XMLWriteNode), so it doesn't import DOM library and runtime can build responses with other DOM (see
+SetDocumentFactory) without regenerating services.
+*/
+type Node interface {
+	// CreateElement appends child element with tag and returns it
+	CreateElement(tag string) Node
+
+	// SetText sets text of element
+	SetText(text string)
+}
+
+/*
+Document is response document, Node that writes itself (with xml declaration) when response is sent
+*/
+type Document interface {
+	Node
+	io.WriterTo
+}
+
+/*
+DocumentFactory returns new empty response document
+*/
+type DocumentFactory func() Document
+
+var (
+	// documentFactory creates documents of generated code, etree by default
+	documentFactory DocumentFactory = newEtreeDocument
+)
+
+/*
+SetDocumentFactory sets factory of response documents built by generated code, nil restores default (etree). It
+must be called before handlers serve calls.
+*/
+func SetDocumentFactory(factory DocumentFactory) {
+	if factory == nil {
+		factory = newEtreeDocument
+	}
+	documentFactory = factory
+}
+
+/*
+NewDocument returns new response document of current factory
+*/
+func NewDocument() Document {
+	return documentFactory()
+}
+
+/*
+XMLWriteNode writes raw value to value node
+*/
+func XMLWriteNode(node Node, raw RawValue) error {
+	if node, ok := node.(etreeNode); ok {
+		return XMLWriteRaw(node.element, raw)
+	}
+
+	value, err := raw.element()
+	if err != nil {
+		return err
+	}
+	writeNode(node, value)
+	return nil
+}
+
+/*
+writeNode copies content of etree element to node, xmlrpc values have either child elements or text
+*/
+func writeNode(node Node, element *etree.Element) {
+	children := element.ChildElements()
+	if len(children) == 0 {
+		if text := element.Text(); text != "" {
+			node.SetText(text)
+		}
+		return
+	}
+	for _, child := range children {
+		writeNode(node.CreateElement(child.FullTag()), child)
+	}
+}
+
+/*
+etreeNode is Node of etree document
+*/
+type etreeNode struct {
+	element *etree.Element
+}
+
+func (e etreeNode) CreateElement(tag string) Node {
+	return etreeNode{element: e.element.CreateElement(tag)}
+}
+
+func (e etreeNode) SetText(text string) {
+	e.element.SetText(text)
+}
+
+/*
+etreeDocument is Document built with etree
+*/
+type etreeDocument struct {
+	etreeNode
+	doc *etree.Document
+}
+
+/*
+newEtreeDocument returns etree document with xml declaration
+*/
+func newEtreeDocument() Document {
+	doc := newResponseDocument()
+	return etreeDocument{etreeNode: etreeNode{element: &doc.Element}, doc: doc}
+}
+
+func (e etreeDocument) WriteTo(w io.Writer) (int64, error) {
+	return e.doc.WriteTo(w)
+}
@@ -0,0 +1,70 @@
+package xmlrpc
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+/*
+testNode is minimal DOM that isn't etree
+*/
+type testNode struct {
+	tag      string
+	text     string
+	children []*testNode
+}
+
+func (t *testNode) CreateElement(tag string) Node {
+	child := &testNode{tag: tag}
+	t.children = append(t.children, child)
+	return child
+}
+
+func (t *testNode) SetText(text string) { t.text = text }
+
+func (t *testNode) write(buf *bytes.Buffer) {
+	buf.WriteString("<" + t.tag + ">")
+	XMLWriteEscaped(buf, t.text)
+	for _, child := range t.children {
+		child.write(buf)
+	}
+	buf.WriteString("</" + t.tag + ">")
+}
+
+type testDocument struct {
+	testNode
+}
+
+func (t *testDocument) WriteTo(w io.Writer) (int64, error) {
+	buf := bytes.NewBufferString(`<?xml version="1.0" encoding="UTF-8"?>`)
+	for _, child := range t.children {
+		child.write(buf)
+	}
+	return buf.WriteTo(w)
+}
+
+func TestDocumentFactory(t *testing.T) {
+	build := func() string {
+		doc := NewDocument()
+		value := doc.CreateElement("methodResponse").CreateElement("params").CreateElement("param").CreateElement("value")
+		member := value.CreateElement("struct").CreateElement("member")
+		member.CreateElement("name").SetText("raw")
+		if err := XMLWriteNode(member.CreateElement("value"), RawValue("<array><data><value><i4>1</i4></value><value>a &amp; b</value></data></array>")); err != nil {
+			t.Fatalf("cannot write raw value: %v", err)
+		}
+
+		buf := &bytes.Buffer{}
+		doc.WriteTo(buf)
+		return buf.String()
+	}
+
+	expected := build()
+
+	SetDocumentFactory(func() Document { return &testDocument{} })
+	defer SetDocumentFactory(nil)
+
+	if got := build(); got != expected {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+}
@@ -0,0 +1,156 @@
+package xmlrpc
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/beevik/etree"
+)
+
+/*
+OverflowError is returned when integer received in param doesn't fit go type it's decoded to (e.g. <i8> into
+int32 field). It's fault with FaultInvalidParams code.
+*/
+type OverflowError struct {
+	// Name of param or struct member
+	Name string
+
+	// Value is received value
+	Value string
+
+	// Type is go type of param
+	Type string
+}
+
+/*
+Error satisfies error interface
+*/
+func (o *OverflowError) Error() string {
+	return fmt.Sprintf("value %v of %v overflows %v", o.Value, o.Name, o.Type)
+}
+
+/*
+Code returns fault code of overflow
+*/
+func (o *OverflowError) Code() int {
+	return FaultInvalidParams
+}
+
+/*
+XPathValueGetIntBits Returns integer that fits into signed integer of given bit size (0 is int), <int>, <i4>
+and <i8> are accepted
+*/
+func XPathValueGetIntBits(element *etree.Element, name string, bitSize int) (result int64, err error) {
+	var tmp *etree.Element
+
+	if tmp = childElement(element, "int", "i4", "i8"); tmp == nil {
+		err = Errorf(FaultInvalidParams, "not found %v", name)
+		return
+	}
+
+	return parseIntBits(name, strings.TrimSpace(tmp.Text()), bitSize)
+}
+
+/*
+XPathValueGetUintBits Returns integer that fits into unsigned integer of given bit size (0 is uint), negative
+values overflow
+*/
+func XPathValueGetUintBits(element *etree.Element, name string, bitSize int) (result uint64, err error) {
+	var tmp *etree.Element
+
+	if tmp = childElement(element, "int", "i4", "i8"); tmp == nil {
+		err = Errorf(FaultInvalidParams, "not found %v", name)
+		return
+	}
+
+	return parseUintBits(name, strings.TrimSpace(tmp.Text()), bitSize)
+}
+
+/*
+XPathValueCoerceIntBits is XPathValueCoerceInt for signed integers of given bit size
+*/
+func XPathValueCoerceIntBits(element *etree.Element, name string, bitSize int) (result int64, err error) {
+	if isIntValue(element) {
+		return XPathValueGetIntBits(element, name, bitSize)
+	}
+
+	var i int
+	if i, err = XPathValueCoerceInt(element, name); err != nil {
+		return
+	}
+	return parseIntBits(name, strconv.Itoa(i), bitSize)
+}
+
+/*
+XPathValueCoerceUintBits is XPathValueCoerceInt for unsigned integers of given bit size
+*/
+func XPathValueCoerceUintBits(element *etree.Element, name string, bitSize int) (result uint64, err error) {
+	if isIntValue(element) {
+		return XPathValueGetUintBits(element, name, bitSize)
+	}
+
+	var i int
+	if i, err = XPathValueCoerceInt(element, name); err != nil {
+		return
+	}
+	return parseUintBits(name, strconv.Itoa(i), bitSize)
+}
+
+/*
+isIntValue returns whether value is integer (not coerced)
+*/
+func isIntValue(element *etree.Element) bool {
+	tag, _, _ := scalarValue(element)
+	return tag == "int" || tag == "i4" || tag == "i8"
+}
+
+func parseIntBits(name, text string, bitSize int) (result int64, err error) {
+	if result, err = strconv.ParseInt(text, 10, bitSizeOrDefault(bitSize)); err != nil {
+		err = intError(name, text, "int", bitSize, err)
+	}
+	return
+}
+
+func parseUintBits(name, text string, bitSize int) (result uint64, err error) {
+	// negative numbers are syntax errors for ParseUint
+	if strings.HasPrefix(text, "-") {
+		if _, err = strconv.ParseInt(text, 10, 64); err == nil || isRangeError(err) {
+			return 0, overflowError(name, text, "uint", bitSize)
+		}
+	}
+
+	if result, err = strconv.ParseUint(text, 10, bitSizeOrDefault(bitSize)); err != nil {
+		err = intError(name, text, "uint", bitSize, err)
+	}
+	return
+}
+
+/*
+intError returns OverflowError for range errors and invalid params fault otherwise
+*/
+func intError(name, text, typ string, bitSize int, err error) error {
+	if !isRangeError(err) {
+		return Errorf(FaultInvalidParams, "invalid int %v", name)
+	}
+	return overflowError(name, text, typ, bitSize)
+}
+
+func overflowError(name, text, typ string, bitSize int) error {
+	if bitSize > 0 {
+		typ += strconv.Itoa(bitSize)
+	}
+	return &OverflowError{Name: name, Value: text, Type: typ}
+}
+
+func isRangeError(err error) bool {
+	numError, ok := err.(*strconv.NumError)
+	return ok && numError.Err == strconv.ErrRange
+}
+
+func bitSizeOrDefault(bitSize int) int {
+	if bitSize == 0 {
+		return strconv.IntSize
+	}
+	return bitSize
+}
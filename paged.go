@@ -0,0 +1,160 @@
+package xmlrpc
+
+import (
+	"context"
+)
+
+const (
+	// DefaultPageSize is count of items requested by single call of Paged
+	DefaultPageSize = 100
+)
+
+/*
+PageOption configures Paged
+*/
+type PageOption func(p *PageIterator)
+
+/*
+WithPageSize sets count of items requested by single call (DefaultPageSize by default)
+*/
+func WithPageSize(size int) PageOption {
+	return func(p *PageIterator) {
+		if size > 0 {
+			p.size = size
+		}
+	}
+}
+
+/*
+WithPageParams sets names of struct members with offset and limit ("offset" and "limit" by default)
+*/
+func WithPageParams(offset, limit string) PageOption {
+	return func(p *PageIterator) {
+		p.offsetName, p.limitName = offset, limit
+	}
+}
+
+/*
+WithPositionalPage sends offset and limit as last two int params of call instead of struct members
+*/
+func WithPositionalPage() PageOption {
+	return func(p *PageIterator) {
+		p.positional = true
+	}
+}
+
+/*
+PageIterator iterates over items of collection returned by method page by page (see Paged), it's not safe for
+concurrent use
+*/
+type PageIterator struct {
+	ctx    context.Context
+	caller Caller
+	method string
+	params []*Value
+
+	size       int
+	offsetName string
+	limitName  string
+	positional bool
+
+	offset int
+	page   []*Value
+	value  *Value
+	last   bool
+	err    error
+}
+
+/*
+Paged returns iterator over items of collection returned by method in pages (common pattern of list methods of
+legacy servers). Method is called with params and offset and limit, by default they are members of last struct
+param (struct param is added when last param is not struct). Method must return array, iteration ends with page
+that has less items than limit.
+
+	items := xmlrpc.Paged(ctx, client, "posts.List", []*xmlrpc.Value{filter}, xmlrpc.WithPageSize(50))
+	for items.Next() {
+		process(items.Value())
+	}
+	if err := items.Err(); err != nil {...}
+*/
+func Paged(ctx context.Context, caller Caller, method string, params []*Value, options ...PageOption) *PageIterator {
+	result := &PageIterator{
+		ctx:        ctx,
+		caller:     caller,
+		method:     method,
+		params:     params,
+		size:       DefaultPageSize,
+		offsetName: "offset",
+		limitName:  "limit",
+	}
+	for _, option := range options {
+		option(result)
+	}
+	return result
+}
+
+/*
+Next moves to next item, next page is requested when items of current one are used. It returns false when
+collection ends or call fails.
+*/
+func (p *PageIterator) Next() bool {
+	if p.err != nil {
+		return false
+	}
+	for len(p.page) == 0 {
+		if p.last {
+			return false
+		}
+		if p.err = p.fetch(); p.err != nil {
+			return false
+		}
+	}
+	p.value, p.page = p.page[0], p.page[1:]
+	return true
+}
+
+/*
+Value returns current item
+*/
+func (p *PageIterator) Value() *Value {
+	return p.value
+}
+
+/*
+Err returns error of call that failed
+*/
+func (p *PageIterator) Err() error {
+	return p.err
+}
+
+/*
+fetch calls method for next page
+*/
+func (p *PageIterator) fetch() error {
+	params := append([]*Value(nil), p.params...)
+	if p.positional {
+		params = append(params, NewInt(p.offset), NewInt(p.size))
+	} else {
+		var page *Value
+		if len(params) > 0 && params[len(params)-1].Kind() == KindStruct {
+			page = params[len(params)-1].Clone()
+			params = params[:len(params)-1]
+		} else {
+			page = NewStruct()
+		}
+		params = append(params, page.Set(p.offsetName, NewInt(p.offset)).Set(p.limitName, NewInt(p.size)))
+	}
+
+	result, err := p.caller.Call(p.ctx, p.method, params...)
+	if err != nil {
+		return err
+	}
+	if result.Kind() != KindArray {
+		return Errorf(FaultTransportError, "page of %v is %v, not array", p.method, result.Kind())
+	}
+
+	p.page = result.Items()
+	p.offset += len(p.page)
+	p.last = len(p.page) < p.size
+	return nil
+}
@@ -0,0 +1,55 @@
+package xmlrpc
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+/*
+arrayLimit is maximum count of decoded array elements, truncated is set when array had more elements
+*/
+type arrayLimit struct {
+	max       int
+	truncated int32
+}
+
+/*
+ContextWithArrayLimit returns context in which at most max elements of every array are decoded (by generated code
+and clients), rest is skipped. It's for callers that need only preview of big results, ArraysTruncated then
+returns whether some array was truncated. Negative max is 0 (arrays are decoded empty).
+*/
+func ContextWithArrayLimit(ctx context.Context, max int) context.Context {
+	if max < 0 {
+		max = 0
+	}
+	return context.WithValue(ctx, arrayLimitContextKey, &arrayLimit{max: max})
+}
+
+/*
+ArraysTruncated returns whether some array decoded with context (see ContextWithArrayLimit) had more elements
+than limit
+*/
+func ArraysTruncated(ctx context.Context) bool {
+	limit, ok := ctx.Value(arrayLimitContextKey).(*arrayLimit)
+	return ok && atomic.LoadInt32(&limit.truncated) != 0
+}
+
+/*
+LimitArray returns elements of array that are decoded in context (see ContextWithArrayLimit), generated code
+calls it before array is decoded
+*/
+func LimitArray(ctx context.Context, values []*Element) []*Element {
+	limit, _ := ctx.Value(arrayLimitContextKey).(*arrayLimit)
+	return limit.apply(values)
+}
+
+/*
+apply returns at most max values and marks limit as truncated when some were skipped, nil limit returns all values
+*/
+func (l *arrayLimit) apply(values []*Element) []*Element {
+	if l == nil || len(values) <= l.max {
+		return values
+	}
+	atomic.StoreInt32(&l.truncated, 1)
+	return values[:l.max]
+}
@@ -0,0 +1,34 @@
+package xmlrpc
+
+import (
+	"context"
+	"testing"
+)
+
+func TestLimitArray(t *testing.T) {
+	values := []*Element{NewElement("value"), NewElement("value"), NewElement("value")}
+
+	for _, item := range []struct {
+		max       int
+		expected  int
+		truncated bool
+	}{
+		{5, 3, false},
+		{3, 3, false},
+		{2, 2, true},
+		{0, 0, true},
+		{-1, 0, true},
+	} {
+		ctx := ContextWithArrayLimit(context.Background(), item.max)
+		if result := LimitArray(ctx, values); len(result) != item.expected {
+			t.Errorf("max %v: expected %v values, got %v", item.max, item.expected, len(result))
+		}
+		if ArraysTruncated(ctx) != item.truncated {
+			t.Errorf("max %v: expected truncated %v", item.max, item.truncated)
+		}
+	}
+
+	if result := LimitArray(context.Background(), values); len(result) != len(values) {
+		t.Errorf("expected all values without limit, got %v", len(result))
+	}
+}
@@ -0,0 +1,235 @@
+package xmlrpc
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+)
+
+const (
+	// peerCall and peerResponse are kinds of peer frames
+	peerCall     = 'C'
+	peerResponse = 'R'
+
+	// peerHeaderSize is size of kind and id that precede document in peer frame
+	peerHeaderSize = 5
+)
+
+var (
+	// ErrPeerClosed is returned by calls of peer whose connection was closed
+	ErrPeerClosed = Errorf(FaultTransportError, "peer connection closed")
+)
+
+/*
+Peer calls methods and serves calls over single connection in both directions (TCP, unix socket, websocket
+connection adapted to io.ReadWriteCloser...). Frames are length prefixed (see WriteFrame), every frame starts with
+kind ('C' call, 'R' response) and id (4 bytes, big endian), response has id of its call. So both sides can call
+concurrently and responses come in any order. It's safe for concurrent use by multiple goroutines.
+*/
+type Peer struct {
+	conn    io.ReadWriteCloser
+	handler Handler
+
+	// writeMutex keeps frames whole
+	writeMutex sync.Mutex
+
+	mutex   sync.Mutex
+	nextID  uint32
+	pending map[uint32]chan []byte
+	err     error
+	done    chan struct{}
+}
+
+/*
+NewPeer returns peer on connection, calls of other side are served by handler (nil handler serves no methods). Peer
+doesn't read connection until Serve is called.
+*/
+func NewPeer(conn io.ReadWriteCloser, h Handler) *Peer {
+	if h == nil {
+		h = NewHandler()
+	}
+	return &Peer{
+		conn:    conn,
+		handler: h,
+		pending: map[uint32]chan []byte{},
+		done:    make(chan struct{}),
+	}
+}
+
+/*
+Serve reads frames until connection ends, every call is served by handler in its own goroutine (context of call
+has the peer, see PeerFromContext) and responses are passed to calls waiting for them. Connection is closed when Serve returns and calls in progress fail with
+ErrPeerClosed. It returns nil when connection ends or peer is closed.
+*/
+func (p *Peer) Serve(ctx context.Context) (err error) {
+	ctx, cancel := context.WithCancel(context.WithValue(ctx, peerContextKey, p))
+	defer cancel()
+
+	defer p.close()
+
+	for {
+		var frame []byte
+		if frame, err = ReadFrame(p.conn, MaxFrameSize); err == io.EOF || err != nil && p.closed() {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if len(frame) < peerHeaderSize {
+			return fmt.Errorf("xmlrpc: peer frame of %v bytes", len(frame))
+		}
+
+		id := binary.BigEndian.Uint32(frame[1:peerHeaderSize])
+		switch frame[0] {
+		case peerCall:
+			go p.serveCall(ctx, id, frame[peerHeaderSize:])
+		case peerResponse:
+			p.mutex.Lock()
+			response, ok := p.pending[id]
+			delete(p.pending, id)
+			p.mutex.Unlock()
+
+			// response of cancelled call is dropped
+			if ok {
+				response <- frame[peerHeaderSize:]
+			}
+		default:
+			return fmt.Errorf("xmlrpc: unknown peer frame kind %q", frame[0])
+		}
+	}
+}
+
+/*
+ServePeers serves peers on connections accepted on listener (see Peer), every connection is served in its own
+goroutine. Methods of handler call back the client that called them by PeerFromContext, e.g. event listener
+registers itself by subscribe call and server keeps its peer to push events to it later.
+*/
+func ServePeers(ctx context.Context, l net.Listener, h Handler) error {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return err
+		}
+		go NewPeer(conn, h).Serve(ctx)
+	}
+}
+
+/*
+PeerFromContext returns peer that received current call, methods call other side of connection by it (callbacks
+of client served by its own handler). It returns nil when call didn't come through peer.
+*/
+func PeerFromContext(ctx context.Context) *Peer {
+	peer, _ := ctx.Value(peerContextKey).(*Peer)
+	return peer
+}
+
+/*
+Done returns channel that is closed when peer is closed (connection ended or Close was called), e.g. to drop
+subscribed listeners
+*/
+func (p *Peer) Done() <-chan struct{} {
+	return p.done
+}
+
+/*
+serveCall serves call of other side and writes its response
+*/
+func (p *Peer) serveCall(ctx context.Context, id uint32, call []byte) {
+	// broken connection is reported by Serve
+	p.write(peerResponse, id, serveFrame(ctx, p.handler, call))
+}
+
+/*
+Call calls method of other side with params and returns its result, fault responses are returned as *Fault. Serve
+must be running, it reads the response.
+*/
+func (p *Peer) Call(ctx context.Context, method string, params ...*Value) (*Value, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	response := make(chan []byte, 1)
+
+	p.mutex.Lock()
+	if p.err != nil {
+		p.mutex.Unlock()
+		return nil, p.err
+	}
+	p.nextID++
+	id := p.nextID
+	p.pending[id] = response
+	p.mutex.Unlock()
+
+	if err := p.write(peerCall, id, encodeCall(method, params)); err != nil {
+		p.forget(id)
+		return nil, err
+	}
+
+	select {
+	case body := <-response:
+		return parseResponse(ctx, body)
+	case <-p.done:
+		return nil, ErrPeerClosed
+	case <-ctx.Done():
+		p.forget(id)
+		return nil, ctx.Err()
+	}
+}
+
+/*
+write writes frame of given kind and id
+*/
+func (p *Peer) write(kind byte, id uint32, document []byte) error {
+	frame := make([]byte, peerHeaderSize, peerHeaderSize+len(document))
+	frame[0] = kind
+	binary.BigEndian.PutUint32(frame[1:], id)
+	frame = append(frame, document...)
+
+	p.writeMutex.Lock()
+	defer p.writeMutex.Unlock()
+	return WriteFrame(p.conn, frame)
+}
+
+/*
+forget stops waiting for response of call
+*/
+func (p *Peer) forget(id uint32) {
+	p.mutex.Lock()
+	delete(p.pending, id)
+	p.mutex.Unlock()
+}
+
+/*
+Close closes connection, Serve returns and calls in progress fail with ErrPeerClosed
+*/
+func (p *Peer) Close() error {
+	return p.close()
+}
+
+/*
+closed returns whether peer was closed
+*/
+func (p *Peer) closed() bool {
+	select {
+	case <-p.done:
+		return true
+	default:
+		return false
+	}
+}
+
+/*
+close closes connection once
+*/
+func (p *Peer) close() error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.err != nil {
+		return nil
+	}
+	p.err = ErrPeerClosed
+	close(p.done)
+	return p.conn.Close()
+}
@@ -0,0 +1,108 @@
+package xmlrpc
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestPeer(t *testing.T) {
+	left, right := net.Pipe()
+	client, server := NewPeer(left, nil), NewPeer(right, newTestHandler())
+
+	served := make(chan error, 2)
+	go func() { served <- client.Serve(context.Background()) }()
+	go func() { served <- server.Serve(context.Background()) }()
+
+	callConcurrently(t, client)
+
+	// client serves no methods
+	if _, err := server.Call(context.Background(), "math.Add", NewInt(1), NewInt(2)); errorCode(err) != FaultMethodNotFound {
+		t.Errorf("expected method not found, got %v", err)
+	}
+
+	// cancelled call doesn't wait for response
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := client.Call(ctx, "math.Add", NewInt(1), NewInt(2)); err != context.Canceled {
+		t.Errorf("expected cancelled call, got %v", err)
+	}
+
+	client.Close()
+	for i := 0; i < 2; i++ {
+		select {
+		case err := <-served:
+			if err != nil {
+				t.Errorf("expected end of connection, got %v", err)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("serve didn't return after close")
+		}
+	}
+	if _, err := client.Call(context.Background(), "math.Add", NewInt(1), NewInt(2)); err != ErrPeerClosed {
+		t.Errorf("expected %v, got %v", ErrPeerClosed, err)
+	}
+}
+
+func TestPeerCallbacks(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("cannot listen: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// server calls events.Notify of subscribed client for every event
+	subscribed := make(chan *Peer, 1)
+	server := NewRegistry()
+	server.Register("Subscribe", func(ctx context.Context, params ...*Value) (*Value, error) {
+		subscribed <- PeerFromContext(ctx)
+		return nil, nil
+	})
+	h := NewHandler()
+	h.AddService(server, "server")
+	go ServePeers(ctx, listener, h)
+
+	events := make(chan string, 3)
+	callbacks := NewRegistry()
+	callbacks.Register("Notify", func(ctx context.Context, params ...*Value) (*Value, error) {
+		events <- params[0].Text()
+		return NewBool(true), nil
+	})
+	clientHandler := NewHandler()
+	clientHandler.AddService(callbacks, "events")
+
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	if err != nil {
+		t.Fatalf("cannot dial: %v", err)
+	}
+	client := NewPeer(conn, clientHandler)
+	defer client.Close()
+	go client.Serve(ctx)
+
+	if _, err := client.Call(ctx, "server.Subscribe"); err != nil {
+		t.Fatalf("cannot subscribe: %v", err)
+	}
+	subscriber := <-subscribed
+	if subscriber == nil {
+		t.Fatal("expected peer in context of call")
+	}
+
+	for _, event := range []string{"started", "exited"} {
+		result, err := subscriber.Call(ctx, "events.Notify", NewString(event))
+		if err != nil || !result.Bool() {
+			t.Fatalf("cannot notify: %v %v", result, err)
+		}
+		if got := <-events; got != event {
+			t.Errorf("expected event %v, got %v", event, got)
+		}
+	}
+
+	client.Close()
+	select {
+	case <-subscriber.Done():
+	case <-time.After(time.Second):
+		t.Fatal("server peer not closed after client closed connection")
+	}
+}
@@ -0,0 +1,187 @@
+package xmlrpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/beevik/etree"
+)
+
+const (
+	// MaxFrameSize is maximum size of frame read by pipe transport
+	MaxFrameSize = 64 << 20
+)
+
+/*
+WriteFrame writes payload prefixed by its length (4 bytes, big endian)
+*/
+func WriteFrame(w io.Writer, payload []byte) error {
+	prefix := make([]byte, 4)
+	binary.BigEndian.PutUint32(prefix, uint32(len(payload)))
+	if _, err := w.Write(prefix); err != nil {
+		return err
+	}
+	_, err := w.Write(payload)
+	return err
+}
+
+/*
+ReadFrame reads payload prefixed by its length, frames bigger than maxSize are rejected. io.EOF is returned only
+when r ends before frame starts.
+*/
+func ReadFrame(r io.Reader, maxSize int) ([]byte, error) {
+	prefix := make([]byte, 4)
+	if _, err := io.ReadFull(r, prefix); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			return nil, fmt.Errorf("xmlrpc: truncated frame length")
+		}
+		return nil, err
+	}
+
+	size := binary.BigEndian.Uint32(prefix)
+	if uint64(size) > uint64(maxSize) {
+		return nil, fmt.Errorf("xmlrpc: frame of %v bytes exceeds %v bytes", size, maxSize)
+	}
+
+	payload := make([]byte, size)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, fmt.Errorf("xmlrpc: truncated frame: %v", err)
+	}
+	return payload, nil
+}
+
+/*
+ServePipe serves calls framed by length prefixes read from r and writes framed responses to w in order, e.g.
+ServePipe(ctx, handler, os.Stdin, os.Stdout) in subprocess driven by PipeClient. It returns nil when r ends.
+Streamed responses that fail are sent as faults.
+*/
+func ServePipe(ctx context.Context, h Handler, r io.Reader, w io.Writer) error {
+	for {
+		call, err := ReadFrame(r, MaxFrameSize)
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		if err = WriteFrame(w, serveFrame(ctx, h, call)); err != nil {
+			return err
+		}
+	}
+}
+
+/*
+serveFrame serves framed call and returns its response. Streamed response that failed (iterator error or panic) is
+unfinished, whole response is buffered so fault is sent instead.
+*/
+func serveFrame(ctx context.Context, h Handler, call []byte) []byte {
+	response := &bytes.Buffer{}
+	if err := h.Serve(ctx, bytes.NewReader(call), response); err != nil {
+		response.Reset()
+		doc := newResponseDocument()
+		XMLWriteError(doc.CreateElement("methodResponse").CreateElement("fault").CreateElement("value"), err)
+		doc.WriteTo(response)
+	}
+	return response.Bytes()
+}
+
+/*
+PipeClient calls methods over framed pipe (e.g. stdin and stdout of subprocess served by ServePipe). It's safe for
+concurrent use by multiple goroutines, calls are serialized (one call is on the pipe at a time) and context is
+checked before call is sent.
+*/
+type PipeClient struct {
+	mutex sync.Mutex
+	r     io.Reader
+	w     io.Writer
+}
+
+/*
+NewPipeClient returns client that writes calls to w and reads responses from r
+*/
+func NewPipeClient(r io.Reader, w io.Writer) *PipeClient {
+	return &PipeClient{r: r, w: w}
+}
+
+/*
+Call calls method with params and returns its result, fault responses are returned as *Fault
+*/
+func (c *PipeClient) Call(ctx context.Context, method string, params ...*Value) (*Value, error) {
+	call := encodeCall(method, params)
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if err := WriteFrame(c.w, call); err != nil {
+		return nil, err
+	}
+	response, err := ReadFrame(c.r, MaxFrameSize)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseResponse(ctx, response)
+}
+
+/*
+encodeCall returns methodCall document of method with params
+*/
+func encodeCall(method string, params []*Value) []byte {
+	call := &bytes.Buffer{}
+	call.WriteString(`<?xml version="1.0" encoding="UTF-8"?><methodCall><methodName>`)
+	XMLWriteEscaped(call, method)
+	call.WriteString("</methodName><params>")
+	for _, param := range params {
+		call.WriteString("<param><value>")
+		param.writeTo(call, false)
+		call.WriteString("</value></param>")
+	}
+	call.WriteString("</params></methodCall>")
+	return call.Bytes()
+}
+
+/*
+ParseResponse returns result of methodResponse document (e.g. read from other transport than clients of this
+package support), fault is returned as *Fault
+*/
+func ParseResponse(response []byte) (*Value, error) {
+	return parseResponse(context.Background(), response)
+}
+
+/*
+parseResponse returns result of methodResponse document, fault is returned as *Fault. Arrays are limited by
+context (see ContextWithArrayLimit).
+*/
+func parseResponse(ctx context.Context, response []byte) (*Value, error) {
+	doc := etree.NewDocument()
+	if err := doc.ReadFromBytes(response); err != nil {
+		return nil, parseError(err)
+	}
+
+	if value := doc.FindElement("methodResponse/params/param/value"); value != nil {
+		limit, _ := ctx.Value(arrayLimitContextKey).(*arrayLimit)
+		return xpathValue(value, "result", limit)
+	}
+	if element := doc.FindElement("methodResponse/fault"); element != nil {
+		fault, err := ParseFault(element)
+		if err != nil {
+			return nil, err
+		}
+		return nil, fault
+	}
+	if doc.FindElement("methodResponse") != nil {
+		return NewNil(), nil
+	}
+
+	return nil, Errorf(FaultTransportError, "invalid methodResponse")
+}
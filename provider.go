@@ -0,0 +1,90 @@
+package xmlrpc
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+/*
+ServiceProvider contributes services to host server, it's implemented by separately built modules (registered by
+RegisterProvider from their init or loaded as go plugins, see package loader)
+*/
+type ServiceProvider interface {
+
+	// Namespace is prefix of service names ("billing" adds service "invoices" as "billing.invoices"), empty
+	// namespace adds services under their names
+	Namespace() string
+
+	// Services returns generated services by their names
+	Services() map[string]interface{}
+}
+
+/*
+NewServiceProvider returns provider of given services
+*/
+func NewServiceProvider(namespace string, services map[string]interface{}) ServiceProvider {
+	return &serviceProvider{namespace: namespace, services: services}
+}
+
+/*
+serviceProvider is provider of fixed services
+*/
+type serviceProvider struct {
+	namespace string
+	services  map[string]interface{}
+}
+
+func (s *serviceProvider) Namespace() string                { return s.namespace }
+func (s *serviceProvider) Services() map[string]interface{} { return s.services }
+
+var (
+	providersMutex sync.RWMutex
+	providers      []ServiceProvider
+)
+
+/*
+RegisterProvider registers provider, modules call it from init so host adds their services by
+AddProviders(handler, xmlrpc.Providers()...)
+*/
+func RegisterProvider(provider ServiceProvider) {
+	providersMutex.Lock()
+	defer providersMutex.Unlock()
+	providers = append(providers, provider)
+}
+
+/*
+Providers returns registered providers in order they were registered
+*/
+func Providers() []ServiceProvider {
+	providersMutex.RLock()
+	defer providersMutex.RUnlock()
+	return append([]ServiceProvider(nil), providers...)
+}
+
+/*
+AddProviders adds services of providers to handler under namespaces of providers. Added services are listed by
+introspection (system.listMethods, system.methodSignature, system.methodHelp) as services added by AddService.
+*/
+func AddProviders(h Handler, providers ...ServiceProvider) error {
+	for _, provider := range providers {
+		services := provider.Services()
+
+		names := make([]string, 0, len(services))
+		for name := range services {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			fullName := name
+			if namespace := provider.Namespace(); namespace != "" {
+				fullName = namespace + "." + name
+			}
+			if err := h.AddService(services[name], fullName); err != nil {
+				return fmt.Errorf("cannot add service %v: %v", fullName, err)
+			}
+		}
+	}
+	return nil
+}
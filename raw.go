@@ -0,0 +1,121 @@
+package xmlrpc
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+
+	"github.com/beevik/etree"
+)
+
+/*
+RawValue is unparsed content of value element (e.g. "<struct>...</struct>" or text of value without type). It's
+decoded and written verbatim, use it for values that are decoded later or passed through (as json.RawMessage).
+*/
+type RawValue []byte
+
+/*
+Value decodes raw value to dynamic value (same values as Inspect returns)
+*/
+func (r RawValue) Value() (result interface{}, err error) {
+	var element *etree.Element
+	if element, err = r.element(); err != nil {
+		return
+	}
+
+	i := &inspector{}
+	result = i.value(element, "value")
+	if len(i.violations) > 0 {
+		err = Errorf(FaultInvalidParams, "%v: %v", i.violations[0].Path, i.violations[0].Message)
+	}
+	return
+}
+
+/*
+element parses raw value to value element
+*/
+func (r RawValue) element() (*etree.Element, error) {
+	doc := etree.NewDocument()
+	if err := doc.ReadFromBytes(append(append([]byte("<value>"), r...), "</value>"...)); err != nil {
+		return nil, parseError(err)
+	}
+	return doc.Root(), nil
+}
+
+/*
+XPathValueGetRaw returns content of value element
+*/
+func XPathValueGetRaw(element *etree.Element, name string) (result RawValue, err error) {
+	doc := etree.NewDocument()
+	for _, token := range element.Child {
+		switch token := token.(type) {
+		case *etree.Element:
+			doc.AddChild(token.Copy())
+		case *etree.CharData:
+			doc.CreateCharData(token.Data)
+		}
+	}
+
+	var raw []byte
+	if raw, err = doc.WriteToBytes(); err != nil {
+		err = Errorf(FaultInvalidParams, "invalid %v: %v", name, err)
+		return
+	}
+	return RawValue(raw), nil
+}
+
+/*
+XMLWriteRaw writes raw value to value element
+*/
+func XMLWriteRaw(element *etree.Element, raw RawValue) error {
+	value, err := raw.element()
+	if err != nil {
+		return err
+	}
+	for _, token := range value.Child {
+		element.AddChild(token)
+	}
+	return nil
+}
+
+/*
+XMLEncodeRaw encodes raw value with encoder
+*/
+func XMLEncodeRaw(enc *xml.Encoder, raw RawValue) error {
+	decoder := xml.NewDecoder(bytes.NewReader(raw))
+	for {
+		// raw tokens keep namespace prefixes (e.g. ex:i8), encoder checks that elements match
+		token, err := decoder.RawToken()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return parseError(err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			attrs := make([]xml.Attr, 0, len(t.Attr))
+			for _, attr := range t.Attr {
+				attrs = append(attrs, xml.Attr{Name: prefixedName(attr.Name), Value: attr.Value})
+			}
+			token = xml.StartElement{Name: prefixedName(t.Name), Attr: attrs}
+		case xml.EndElement:
+			token = xml.EndElement{Name: prefixedName(t.Name)}
+		}
+
+		if err = enc.EncodeToken(xml.CopyToken(token)); err != nil {
+			return err
+		}
+	}
+}
+
+/*
+prefixedName returns raw name with prefix in local name, so encoder writes it unchanged
+*/
+func prefixedName(name xml.Name) xml.Name {
+	if name.Space == "" {
+		return name
+	}
+	return xml.Name{Local: name.Space + ":" + name.Local}
+}
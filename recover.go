@@ -0,0 +1,21 @@
+package xmlrpc
+
+import (
+	"log"
+	"runtime/debug"
+)
+
+var (
+	ErrInternal = Errorf(FaultInternalError, "internal error")
+)
+
+/*
+RecoverPanic recovers from panic in service method, logs it with stack trace and sets err to ErrInternal, so
+client receives fault without any details. It's used by generated Dispatch and must be called with defer.
+*/
+func RecoverPanic(method string, err *error) {
+	if r := recover(); r != nil {
+		log.Printf("xmlrpc: panic in method %v: %v\n%s", method, r, debug.Stack())
+		*err = ErrInternal
+	}
+}
@@ -0,0 +1,141 @@
+package xmlrpc
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+/*
+MethodFunc is dynamically registered method, it's called with decoded params. Nil result is written as empty
+response (same as methods without result).
+*/
+type MethodFunc func(ctx context.Context, params ...*Value) (*Value, error)
+
+/*
+Registry is service with methods registered at runtime (plugins, scripted methods...), it's added to handler as
+any other service (handler.AddService(registry, "plugins")). Methods can be added, removed or swapped while
+handler serves calls, calls in progress finish with method they started with.
+*/
+type Registry struct {
+	mutex   sync.RWMutex
+	methods map[string]MethodFunc
+}
+
+/*
+NewRegistry returns registry without methods
+*/
+func NewRegistry() *Registry {
+	return &Registry{methods: map[string]MethodFunc{}}
+}
+
+/*
+Register adds method to registry, method with same name is replaced
+*/
+func (r *Registry) Register(name string, method MethodFunc) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.methods[name] = method
+}
+
+/*
+Unregister removes methods from registry
+*/
+func (r *Registry) Unregister(names ...string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	for _, name := range names {
+		delete(r.methods, name)
+	}
+}
+
+/*
+Swap replaces all methods of registry at once (e.g. when plugins are reloaded) and returns previous methods, calls
+never see mix of old and new methods
+*/
+func (r *Registry) Swap(methods map[string]MethodFunc) map[string]MethodFunc {
+	replacement := make(map[string]MethodFunc, len(methods))
+	for name, method := range methods {
+		replacement[name] = method
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	previous := r.methods
+	r.methods = replacement
+	return previous
+}
+
+/*
+method returns registered method
+*/
+func (r *Registry) method(name string) (method MethodFunc, ok bool) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	method, ok = r.methods[name]
+	return
+}
+
+/*
+MethodExists returns whether method is registered
+*/
+func (r *Registry) MethodExists(name string) bool {
+	_, ok := r.method(name)
+	return ok
+}
+
+/*
+ListMethods returns names of registered methods
+*/
+func (r *Registry) ListMethods() []string {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	result := make([]string, 0, len(r.methods))
+	for name := range r.methods {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+	return result
+}
+
+/*
+Dispatch decodes params and calls registered method
+*/
+func (r *Registry) Dispatch(ctx context.Context, name string, root *Element) (res io.WriterTo, err error) {
+	method, ok := r.method(name)
+	if !ok {
+		return nil, ErrMethodNotFound
+	}
+
+	// panic in method is returned as internal error
+	defer RecoverPanic(name, &err)
+
+	params := []*Value{}
+	for i, element := range XPathParams(root) {
+		var param *Value
+		if param, err = XPathValueGetValue(element, "param "+strconv.Itoa(i+1)); err != nil {
+			return
+		}
+		params = append(params, param)
+	}
+
+	var result *Value
+	if result, err = method(ctx, params...); err != nil {
+		return
+	}
+
+	buf := &bytes.Buffer{}
+	buf.WriteString(`<?xml version="1.0" encoding="UTF-8"?>`)
+	if result == nil {
+		buf.WriteString("<methodResponse/>")
+		return buf, nil
+	}
+	buf.WriteString("<methodResponse><params><param><value>")
+	result.writeTo(buf, false)
+	buf.WriteString("</value></param></params></methodResponse>")
+	return buf, nil
+}
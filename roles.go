@@ -0,0 +1,52 @@
+package xmlrpc
+
+import "context"
+
+/*
+RolesLister is implemented by generated services, it returns roles of callers allowed to call methods (set by
+"//xmlrpc:roles=admin,editor" directive), methods that are not listed are allowed to all callers
+*/
+type RolesLister interface {
+	MethodRoles() map[string][]string
+}
+
+/*
+HasRole returns whether identity has given role
+*/
+func (i *Identity) HasRole(role string) bool {
+	if i == nil {
+		return false
+	}
+	for _, r := range i.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+authorize checks that caller has one of roles of method, unauthenticated callers get ErrUnauthorized and callers
+without role ErrForbidden
+*/
+func authorize(ctx context.Context, s Service, method string) error {
+	lister, ok := s.(RolesLister)
+	if !ok {
+		return nil
+	}
+	roles := lister.MethodRoles()[method]
+	if len(roles) == 0 {
+		return nil
+	}
+
+	identity := IdentityFromContext(ctx)
+	if identity == nil {
+		return ErrUnauthorized
+	}
+	for _, role := range roles {
+		if identity.HasRole(role) {
+			return nil
+		}
+	}
+	return ErrForbidden
+}
@@ -0,0 +1,57 @@
+package xmlrpc
+
+import "testing"
+
+/*
+rolesService is test service whose Echo method is allowed to editors
+*/
+type rolesService struct {
+	testService
+}
+
+func (r *rolesService) MethodRoles() map[string][]string {
+	return map[string][]string{
+		"Echo": {"admin", "editor"},
+	}
+}
+
+func TestRoles(t *testing.T) {
+	service := &rolesService{testService{name: "test"}}
+	h := NewHandler()
+	if err := h.AddService(service, "test"); err != nil {
+		t.Fatalf("cannot add service: %v", err)
+	}
+
+	for _, item := range []struct {
+		name     string
+		identity *Identity
+		expected error
+	}{
+		{"anonymous", nil, ErrUnauthorized},
+		{"without roles", &Identity{Name: "guest"}, ErrForbidden},
+		{"other role", &Identity{Name: "viewer", Roles: []string{"viewer"}}, ErrForbidden},
+		{"editor", &Identity{Name: "editor", Roles: []string{"viewer", "editor"}}, nil},
+	} {
+		calls, _ := service.called()
+
+		request := newTestCall("test.Echo", "")
+		if item.identity != nil {
+			request = request.WithContext(ContextWithIdentity(request.Context(), item.identity))
+		}
+		fault := responseFault(serveTestCall(h, request))
+
+		after, _ := service.called()
+		if item.expected == nil {
+			if fault != nil || after != calls+1 {
+				t.Errorf("%v: expected call, got fault %v", item.name, fault)
+			}
+			continue
+		}
+		if fault == nil || fault.Code() != errorCode(item.expected) {
+			t.Errorf("%v: expected fault %v, got %v", item.name, item.expected, fault)
+		}
+		if after != calls {
+			t.Errorf("%v: method was called", item.name)
+		}
+	}
+}
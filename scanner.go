@@ -0,0 +1,311 @@
+package xmlrpc
+
+import (
+	"bufio"
+	"encoding/xml"
+	"io"
+)
+
+const (
+	// DefaultScanWindow is size of window of reads of NewValueScannerAt
+	DefaultScanWindow = 64 << 10
+)
+
+/*
+ValueScanner decodes big documents (request replays, archived responses) value by value, only current value is
+held in memory. Values are params of methodCall or items of array result of methodResponse (other results are
+single value), fault of response is returned by Err as *Fault. Scanner is not safe for concurrent use.
+
+	scanner := xmlrpc.NewValueScannerAt(file, size, 0)
+	for scanner.Scan() {
+		process(scanner.Value())
+	}
+	if err := scanner.Err(); err != nil {...}
+*/
+type ValueScanner struct {
+	decoder *xml.Decoder
+	method  string
+	value   *Value
+	err     error
+
+	// state is position in document
+	state scanState
+}
+
+/*
+scanState is position of scanner in document
+*/
+type scanState int
+
+const (
+	scanStart scanState = iota
+	scanParams
+	scanArray
+	scanSingle
+	scanDone
+)
+
+/*
+NewValueScanner returns scanner of document read from r
+*/
+func NewValueScanner(r io.Reader) *ValueScanner {
+	return &ValueScanner{decoder: xml.NewDecoder(r)}
+}
+
+/*
+NewValueScannerAt returns scanner of document of given size read from r (e.g. *os.File or memory mapped file) in
+windows of given size (DefaultScanWindow when window is 0)
+*/
+func NewValueScannerAt(r io.ReaderAt, size int64, window int) *ValueScanner {
+	if window <= 0 {
+		window = DefaultScanWindow
+	}
+	return NewValueScanner(bufio.NewReaderSize(io.NewSectionReader(r, 0, size), window))
+}
+
+/*
+Method returns method name of methodCall (available after first Scan)
+*/
+func (s *ValueScanner) Method() string {
+	return s.method
+}
+
+/*
+Value returns value decoded by last Scan
+*/
+func (s *ValueScanner) Value() *Value {
+	return s.value
+}
+
+/*
+Err returns first error of scanner, it's *Fault for fault responses
+*/
+func (s *ValueScanner) Err() error {
+	if s.err == io.EOF {
+		return nil
+	}
+	return s.err
+}
+
+/*
+Scan decodes next value, it returns false at the end of document or on error
+*/
+func (s *ValueScanner) Scan() bool {
+	if s.err != nil || s.state == scanDone {
+		return false
+	}
+	s.value = nil
+
+	if s.state == scanStart {
+		if s.err = s.start(); s.err != nil {
+			return false
+		}
+	}
+
+	switch s.state {
+	case scanParams:
+		// <param><value>...</value></param>
+		if _, ok := s.next("param"); !ok {
+			return false
+		}
+		start, ok := s.next("value")
+		if !ok {
+			return s.fail(Errorf(FaultInvalidRequest, "param without value"))
+		}
+		if !s.decodeValue(start, "param") {
+			return false
+		}
+		// </param>
+		s.end()
+		return s.err == nil
+	case scanArray:
+		start, ok := s.next("value")
+		if !ok {
+			return false
+		}
+		return s.decodeValue(start, "result item")
+	case scanSingle:
+		s.state = scanDone
+		return s.value != nil
+	}
+	return false
+}
+
+/*
+start reads document until first value
+*/
+func (s *ValueScanner) start() error {
+	root, ok := s.next("")
+	if !ok {
+		return Errorf(FaultInvalidRequest, "empty document")
+	}
+
+	switch root.Name.Local {
+	case "methodCall":
+		name, ok := s.next("methodName")
+		if !ok {
+			return Errorf(FaultInvalidRequest, "methodName not found")
+		}
+		var method struct {
+			Text string `xml:",chardata"`
+		}
+		if err := s.decoder.DecodeElement(&method, &name); err != nil {
+			return parseError(err)
+		}
+		s.method = method.Text
+		if _, ok := s.next("params"); !ok {
+			s.state = scanDone
+			return s.err
+		}
+		s.state = scanParams
+	case "methodResponse":
+		element, ok := s.next("")
+		if !ok {
+			s.state = scanDone
+			return s.err
+		}
+		if element.Name.Local == "fault" {
+			return s.fault()
+		}
+		if _, ok := s.next("param"); !ok {
+			s.state = scanDone
+			return s.err
+		}
+		value, ok := s.next("value")
+		if !ok {
+			return Errorf(FaultInvalidRequest, "param without value")
+		}
+		return s.result(value)
+	default:
+		return Errorf(FaultInvalidRequest, "unknown document %v", root.Name.Local)
+	}
+	return nil
+}
+
+/*
+result reads result of response, items of array result are scanned one by one
+*/
+func (s *ValueScanner) result(value xml.StartElement) error {
+	for {
+		token, err := s.decoder.Token()
+		if err != nil {
+			return parseError(err)
+		}
+		switch token := token.(type) {
+		case xml.StartElement:
+			if token.Name.Local == "array" {
+				if _, ok := s.next("data"); !ok {
+					return Errorf(FaultTransportError, "array without data")
+				}
+				s.state = scanArray
+				return nil
+			}
+			var inner struct {
+				XML []byte `xml:",innerxml"`
+			}
+			if err = s.decoder.DecodeElement(&inner, &token); err != nil {
+				return parseError(err)
+			}
+			raw := "<" + token.Name.Local + ">" + string(inner.XML) + "</" + token.Name.Local + ">"
+			if s.value, err = ParseValue(RawValue(raw)); err != nil {
+				return err
+			}
+			s.state = scanSingle
+			return nil
+		case xml.CharData:
+			// untyped value is string
+			var text []byte
+			text = append(text, token...)
+			s.value = NewString(string(text))
+		case xml.EndElement:
+			if s.value == nil {
+				s.value = NewString("")
+			}
+			s.state = scanSingle
+			return nil
+		}
+	}
+}
+
+/*
+fault reads fault of response
+*/
+func (s *ValueScanner) fault() error {
+	value, ok := s.next("value")
+	if !ok {
+		return Errorf(FaultTransportError, "fault without value")
+	}
+	if !s.decodeValue(value, "fault") {
+		return s.err
+	}
+	s.state = scanDone
+	return multicallFault(s.value)
+}
+
+/*
+decodeValue decodes value element
+*/
+func (s *ValueScanner) decodeValue(start xml.StartElement, name string) bool {
+	var inner struct {
+		XML []byte `xml:",innerxml"`
+	}
+	if err := s.decoder.DecodeElement(&inner, &start); err != nil {
+		return s.fail(parseError(err))
+	}
+	element, err := RawValue(inner.XML).element()
+	if err != nil {
+		return s.fail(err)
+	}
+	if s.value, err = XPathValueGetValue(element, name); err != nil {
+		return s.fail(err)
+	}
+	return true
+}
+
+/*
+next returns next start element (with given local name when name is not empty), ok is false when parent element
+ends first
+*/
+func (s *ValueScanner) next(name string) (start xml.StartElement, ok bool) {
+	for {
+		token, err := s.decoder.Token()
+		if err != nil {
+			if err != io.EOF {
+				err = parseError(err)
+			}
+			s.fail(err)
+			return
+		}
+		switch token := token.(type) {
+		case xml.StartElement:
+			if name != "" && token.Name.Local != name {
+				s.fail(Errorf(FaultInvalidRequest, "expected %v, got %v", name, token.Name.Local))
+				return
+			}
+			return token, true
+		case xml.EndElement:
+			s.state = scanDone
+			return
+		}
+	}
+}
+
+/*
+end skips rest of current element
+*/
+func (s *ValueScanner) end() {
+	if err := s.decoder.Skip(); err != nil {
+		s.fail(parseError(err))
+	}
+}
+
+/*
+fail stores first error and returns false
+*/
+func (s *ValueScanner) fail(err error) bool {
+	if s.err == nil {
+		s.err = err
+	}
+	s.state = scanDone
+	return false
+}
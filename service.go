@@ -3,7 +3,10 @@ Protocol handles first level of marshalling/unmarshalling
 */
 package xmlrpc
 
-import "github.com/beevik/etree"
+import (
+	"context"
+	"io"
+)
 
 /*
 Service interface must be satisfied when registering service
@@ -11,8 +14,9 @@ This interface is satisfied when go generate is called
 */
 type Service interface {
 
-	// Dispatch method dispatches xmlrpc call
-	Dispatch(method string, root *etree.Element) (result *etree.Document, err error)
+	// Dispatch method dispatches xmlrpc call, ctx is passed to service methods that accept context.Context.
+	// Result is whole methodResponse document (*etree.Document or other io.WriterTo, depends on backend).
+	Dispatch(ctx context.Context, method string, root *Element) (result io.WriterTo, err error)
 
 	// returns list of all rpc methods
 	ListMethods() []string
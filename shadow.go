@@ -0,0 +1,220 @@
+package xmlrpc
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"path"
+	"strconv"
+
+	"github.com/beevik/etree"
+)
+
+/*
+Shadow configures shadowing of calls: every call of local method is also sent to upstream (usually legacy server
+that is being ported), responses are compared structurally and differences are reported. Handler waits for both
+responses, so call takes as long as slower of them.
+*/
+type Shadow struct {
+	// Upstream is called with every shadowed call (e.g. NewForwarder)
+	Upstream MethodNotFoundHandler
+
+	// Methods limits shadowed methods to those matching patterns (path.Match syntax), all methods when empty
+	Methods []string
+
+	// Report receives differences of responses, they are logged when nil
+	Report func(ctx context.Context, method string, diffs []string)
+
+	// UseUpstream returns upstream response to client instead of local one
+	UseUpstream bool
+}
+
+/*
+WithShadow sets shadowing of calls of local methods
+*/
+func WithShadow(shadow Shadow) HandlerOption {
+	return func(h *handler) {
+		h.shadow = &shadow
+	}
+}
+
+/*
+matches returns whether method is shadowed
+*/
+func (s *Shadow) matches(method string) bool {
+	if len(s.Methods) == 0 {
+		return true
+	}
+	for _, pattern := range s.Methods {
+		if ok, _ := path.Match(pattern, method); ok {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+report passes differences to Report or logs them
+*/
+func (s *Shadow) report(ctx context.Context, method string, diffs []string) {
+	if s.Report != nil {
+		s.Report(ctx, method, diffs)
+		return
+	}
+	for _, diff := range diffs {
+		log.Printf("xmlrpc: shadow %v: %v", method, diff)
+	}
+}
+
+/*
+shadowCall calls local method and upstream concurrently, compares their responses and returns one of them
+*/
+func (h *handler) shadowCall(ctx context.Context, method string, params *etree.Element, local func() (io.WriterTo, error)) (io.WriterTo, error) {
+	type shadowed struct {
+		res io.WriterTo
+		err error
+	}
+
+	// generated code doesn't modify params, copy is passed to upstream anyway
+	upstreamParams := params.Copy()
+	upstream := make(chan shadowed, 1)
+	go func() {
+		res, err := h.shadow.Upstream(ctx, method, upstreamParams)
+		if err == nil {
+			res, err = responseDocument(res)
+		}
+		upstream <- shadowed{res, err}
+	}()
+
+	res, err := local()
+	if err == nil {
+		if res, err = responseDocument(res); err != nil {
+			return nil, err
+		}
+	}
+
+	other := <-upstream
+
+	var diffs []string
+	localValue, localErr := shadowValue(res, err)
+	upstreamValue, upstreamErr := shadowValue(other.res, other.err)
+	switch {
+	case localErr != nil:
+		diffs = []string{"invalid local response: " + localErr.Error()}
+	case upstreamErr != nil:
+		diffs = []string{"invalid upstream response: " + upstreamErr.Error()}
+	default:
+		diffValues("", localValue, upstreamValue, &diffs)
+	}
+	if len(diffs) > 0 {
+		h.shadow.report(ctx, method, diffs)
+	}
+
+	if h.shadow.UseUpstream {
+		return other.res, other.err
+	}
+	return res, err
+}
+
+/*
+responseDocument returns response as parsed document (responses of writer backends are buffered)
+*/
+func responseDocument(res io.WriterTo) (*etree.Document, error) {
+	if doc, ok := res.(*etree.Document); ok {
+		return doc, nil
+	}
+	if doc, ok := res.(etreeDocument); ok {
+		return doc.doc, nil
+	}
+
+	buf := &bytes.Buffer{}
+	if _, err := res.WriteTo(buf); err != nil {
+		return nil, err
+	}
+
+	doc := etree.NewDocument()
+	if _, err := doc.ReadFrom(buf); err != nil {
+		return nil, Errorf(FaultInternalError, "invalid response: %v", err)
+	}
+	return doc, nil
+}
+
+/*
+shadowValue returns response as struct with "result" or "fault" member (faultCode and faultString), errors
+are faults
+*/
+func shadowValue(res io.WriterTo, err error) (*Value, error) {
+	if err != nil {
+		return NewStruct().Set("fault", NewStruct().
+			Set("faultCode", NewInt(errorCode(err))).
+			Set("faultString", NewString(err.Error()))), nil
+	}
+
+	doc := res.(*etree.Document)
+
+	if value := doc.FindElement("methodResponse/params/param/value"); value != nil {
+		result, err := XPathValueGetValue(value, "result")
+		if err != nil {
+			return nil, err
+		}
+		return NewStruct().Set("result", result), nil
+	}
+
+	if value := doc.FindElement("methodResponse/fault/value"); value != nil {
+		fault, err := XPathValueGetValue(value, "fault")
+		if err != nil {
+			return nil, err
+		}
+		return NewStruct().Set("fault", NewStruct().
+			Set("faultCode", fault.Member("faultCode")).
+			Set("faultString", fault.Member("faultString"))), nil
+	}
+
+	return nil, Errorf(FaultInternalError, "methodResponse without result or fault")
+}
+
+/*
+diffValues appends structural differences of local and upstream value to diffs
+*/
+func diffValues(path string, local, upstream *Value, diffs *[]string) {
+	if local.Kind() != upstream.Kind() {
+		*diffs = append(*diffs, fmt.Sprintf("%v: %v != %v", path, local.Kind(), upstream.Kind()))
+		return
+	}
+
+	switch local.Kind() {
+	case KindStruct:
+		for _, name := range local.Names() {
+			if upstream.Member(name) == nil {
+				*diffs = append(*diffs, fmt.Sprintf("%v: missing in upstream", memberPath(path, name)))
+				continue
+			}
+			diffValues(memberPath(path, name), local.Member(name), upstream.Member(name), diffs)
+		}
+		for _, name := range upstream.Names() {
+			if local.Member(name) == nil {
+				*diffs = append(*diffs, fmt.Sprintf("%v: missing in local", memberPath(path, name)))
+			}
+		}
+	case KindArray:
+		if local.Len() != upstream.Len() {
+			*diffs = append(*diffs, fmt.Sprintf("%v: %v != %v items", path, local.Len(), upstream.Len()))
+		}
+		for i := 0; i < local.Len() && i < upstream.Len(); i++ {
+			diffValues(path+"["+strconv.Itoa(i)+"]", local.Index(i), upstream.Index(i), diffs)
+		}
+	default:
+		if a, b := local.Raw(), upstream.Raw(); !bytes.Equal(a, b) {
+			*diffs = append(*diffs, fmt.Sprintf("%v: %s != %s", path, a, b))
+		}
+	}
+}
+
+func memberPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
@@ -0,0 +1,116 @@
+package xmlrpc
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+)
+
+const (
+	// SignatureHeader is default http header that carries request body signature
+	SignatureHeader = "X-Xmlrpc-Signature"
+
+	// DefaultSignatureMaxBodySize is maximum size of request body verified by VerifySignature by default
+	DefaultSignatureMaxBodySize = 10 << 20
+)
+
+var (
+	// ErrInvalidSignature is returned by VerifySignature middleware for requests with missing or invalid signature
+	ErrInvalidSignature = Errorf(FaultInvalidRequest, "invalid request signature")
+)
+
+/*
+SignatureOption configures middleware of VerifySignature
+*/
+type SignatureOption func(s *signatureVerifier)
+
+/*
+WithSignatureMaxBodySize sets maximum size of request body (DefaultSignatureMaxBodySize by default), bigger
+requests are rejected with ErrRequestTooLarge
+*/
+func WithSignatureMaxBodySize(max int64) SignatureOption {
+	return func(s *signatureVerifier) {
+		s.maxBodySize = max
+	}
+}
+
+/*
+signatureVerifier holds settings of VerifySignature
+*/
+type signatureVerifier struct {
+	maxBodySize int64
+}
+
+/*
+SignBody returns hex encoded HMAC-SHA256 of body
+*/
+func SignBody(key []byte, body []byte) string {
+	return hex.EncodeToString(signBodyRaw(key, body))
+}
+
+/*
+SignRequest signs body of outgoing http request and sets signature to SignatureHeader.
+Body is read to memory and replaced so request can be sent afterwards.
+*/
+func SignRequest(r *http.Request, key []byte) (err error) {
+	var body []byte
+
+	if r.Body != nil {
+		if body, err = ioutil.ReadAll(r.Body); err != nil {
+			return
+		}
+		r.Body.Close()
+	}
+
+	r.Body = ioutil.NopCloser(bytes.NewReader(body))
+	r.Header.Set(SignatureHeader, SignBody(key, body))
+	return
+}
+
+/*
+VerifySignature returns middleware that verifies HMAC-SHA256 signature of request body. Signature is read from
+given header (SignatureHeader if empty). Requests with missing or invalid signature get fault response. Body is
+read to memory, so its size is limited (see WithSignatureMaxBodySize).
+*/
+func VerifySignature(key []byte, header string, options ...SignatureOption) Middleware {
+	if header == "" {
+		header = SignatureHeader
+	}
+	verifier := &signatureVerifier{maxBodySize: DefaultSignatureMaxBodySize}
+	for _, option := range options {
+		option(verifier)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, verifier.maxBodySize))
+			if err != nil && int64(len(body)) >= verifier.maxBodySize {
+				writeFault(w, r, ErrRequestTooLarge)
+				return
+			}
+			if err != nil {
+				writeFault(w, r, Errorf(FaultTransportError, "cannot read body"))
+				return
+			}
+			r.Body.Close()
+
+			expected, err := hex.DecodeString(r.Header.Get(header))
+			if err != nil || !hmac.Equal(expected, signBodyRaw(key, body)) {
+				writeFault(w, r, ErrInvalidSignature)
+				return
+			}
+
+			r.Body = ioutil.NopCloser(bytes.NewReader(body))
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func signBodyRaw(key []byte, body []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(body)
+	return mac.Sum(nil)
+}
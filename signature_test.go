@@ -0,0 +1,92 @@
+package xmlrpc
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestVerifySignature(t *testing.T) {
+	key := []byte("secret")
+	call := `<?xml version="1.0"?><methodCall><methodName>test.Echo</methodName><params></params></methodCall>`
+
+	for _, item := range []struct {
+		name      string
+		signature string
+		ok        bool
+	}{
+		{"valid", SignBody(key, []byte(call)), true},
+		{"other key", SignBody([]byte("other"), []byte(call)), false},
+		{"other body", SignBody(key, []byte(call+" ")), false},
+		{"not hex", "signature", false},
+		{"missing", "", false},
+	} {
+		called := false
+		handler := VerifySignature(key, "")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			called = true
+		}))
+
+		request := httptest.NewRequest("POST", "/", strings.NewReader(call))
+		if item.signature != "" {
+			request.Header.Set(SignatureHeader, item.signature)
+		}
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+
+		if called != item.ok {
+			t.Errorf("%v: expected call %v, got %v", item.name, item.ok, called)
+		}
+		if fault := strings.Contains(recorder.Body.String(), "<fault>"); fault == item.ok {
+			t.Errorf("%v: expected fault %v, got %v", item.name, !item.ok, recorder.Body.String())
+		}
+	}
+}
+
+func TestSignRequest(t *testing.T) {
+	key := []byte("secret")
+	request := httptest.NewRequest("POST", "/", strings.NewReader("<methodCall/>"))
+	if err := SignRequest(request, key); err != nil {
+		t.Fatalf("cannot sign request: %v", err)
+	}
+
+	called := false
+	VerifySignature(key, "")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	})).ServeHTTP(httptest.NewRecorder(), request)
+
+	if !called {
+		t.Errorf("signed request was rejected")
+	}
+}
+
+func TestVerifySignatureBodySize(t *testing.T) {
+	key := []byte("secret")
+	call := `<?xml version="1.0"?><methodCall><methodName>test.Echo</methodName><params></params></methodCall>`
+
+	for _, item := range []struct {
+		max int64
+		ok  bool
+	}{
+		{int64(len(call)) + 1, true},
+		{int64(len(call)) - 1, false},
+	} {
+		called := false
+		handler := VerifySignature(key, "", WithSignatureMaxBodySize(item.max))(http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				called = true
+			}))
+
+		request := httptest.NewRequest("POST", "/", strings.NewReader(call))
+		request.Header.Set(SignatureHeader, SignBody(key, []byte(call)))
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+
+		if called != item.ok {
+			t.Errorf("%v: expected call %v, got %v", item.max, item.ok, called)
+		}
+		if !item.ok && !strings.Contains(recorder.Body.String(), ErrRequestTooLarge.Error()) {
+			t.Errorf("%v: expected request too large fault, got %v", item.max, recorder.Body.String())
+		}
+	}
+}
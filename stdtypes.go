@@ -0,0 +1,63 @@
+package xmlrpc
+
+import (
+	"net/mail"
+	"net/url"
+
+	"github.com/beevik/etree"
+)
+
+/*
+FormatURL returns string representation of url, nil url is empty string
+*/
+func FormatURL(u *url.URL) string {
+	if u == nil {
+		return ""
+	}
+	return u.String()
+}
+
+/*
+XPathValueGetURL Returns url parsed from string value
+*/
+func XPathValueGetURL(element *etree.Element, name string) (result *url.URL, err error) {
+	var text string
+
+	if text, err = XPathValueGetString(element, name); err != nil {
+		return
+	}
+
+	if result, err = url.Parse(text); err != nil {
+		err = Errorf(FaultInvalidParams, "invalid url %v", name)
+	}
+
+	return
+}
+
+/*
+FormatMailAddress returns string representation of mail address ("Name <user@example.com>"), nil address is
+empty string
+*/
+func FormatMailAddress(address *mail.Address) string {
+	if address == nil {
+		return ""
+	}
+	return address.String()
+}
+
+/*
+XPathValueGetMailAddress Returns mail address parsed (RFC 5322) from string value
+*/
+func XPathValueGetMailAddress(element *etree.Element, name string) (result *mail.Address, err error) {
+	var text string
+
+	if text, err = XPathValueGetString(element, name); err != nil {
+		return
+	}
+
+	if result, err = mail.ParseAddress(text); err != nil {
+		err = Errorf(FaultInvalidParams, "invalid mail address %v", name)
+	}
+
+	return
+}
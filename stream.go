@@ -0,0 +1,85 @@
+package xmlrpc
+
+import (
+	"bytes"
+	"context"
+	"io"
+)
+
+/*
+WriterToFunc is function that satisfies io.WriterTo, generated code returns it for streamed responses
+*/
+type WriterToFunc func(w io.Writer) (int64, error)
+
+/*
+WriteTo satisfies io.WriterTo interface. Streamed results are written after Dispatch returned, so panic of function
+(e.g. in iterator of service) is recovered here and returned as ErrInternal, response is left unfinished.
+*/
+func (f WriterToFunc) WriteTo(w io.Writer) (n int64, err error) {
+	defer RecoverPanic("streamed response", &err)
+	return f(w)
+}
+
+/*
+CancelAfterWrite returns result that calls cancel when it's written. Generated code uses it for methods with
+timeout directive, their streamed results (WriterToFunc) are written after Dispatch returned and need context
+until then. Other results are already complete, so cancel is called immediately.
+*/
+func CancelAfterWrite(res io.WriterTo, cancel context.CancelFunc) io.WriterTo {
+	lazy, ok := res.(WriterToFunc)
+	if !ok {
+		cancel()
+		return res
+	}
+	return WriterToFunc(func(w io.Writer) (int64, error) {
+		defer cancel()
+		return lazy(w)
+	})
+}
+
+/*
+StreamWriter writes response in chunks. Chunk is written to Buffer and then flushed to underlying writer, so
+only single item of streamed array is held in memory.
+*/
+type StreamWriter struct {
+	w   io.Writer
+	buf bytes.Buffer
+	n   int64
+	err error
+}
+
+/*
+NewStreamWriter returns StreamWriter that writes to w
+*/
+func NewStreamWriter(w io.Writer) *StreamWriter {
+	return &StreamWriter{
+		w: w,
+	}
+}
+
+/*
+Buffer returns buffer for current chunk
+*/
+func (s *StreamWriter) Buffer() *bytes.Buffer {
+	return &s.buf
+}
+
+/*
+Flush writes current chunk to underlying writer. After first error nothing is written.
+*/
+func (s *StreamWriter) Flush() error {
+	if s.err == nil {
+		var n int64
+		n, s.err = s.buf.WriteTo(s.w)
+		s.n += n
+	}
+	s.buf.Reset()
+	return s.err
+}
+
+/*
+Result returns number of written bytes and first write error
+*/
+func (s *StreamWriter) Result() (int64, error) {
+	return s.n, s.err
+}
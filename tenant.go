@@ -0,0 +1,84 @@
+package xmlrpc
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+/*
+ContextWithTenant returns new context with tenant key
+*/
+func ContextWithTenant(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, tenantContextKey, tenant)
+}
+
+/*
+TenantFromContext returns tenant key of current call stored by tenant middleware, empty if call has no tenant
+*/
+func TenantFromContext(ctx context.Context) string {
+	tenant, _ := ctx.Value(tenantContextKey).(string)
+	return tenant
+}
+
+/*
+TenantFromHeader returns middleware that stores value of given header as tenant key in request context
+*/
+func TenantFromHeader(header string) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if tenant := strings.TrimSpace(r.Header.Get(header)); tenant != "" {
+				r = r.WithContext(ContextWithTenant(r.Context(), tenant))
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+/*
+TenantFromPath returns middleware that stores first path segment after prefix as tenant key in request context
+(e.g. "acme" for "/rpc/acme" with prefix "/rpc/"), requests with other paths are served without tenant
+*/
+func TenantFromPath(prefix string) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if strings.HasPrefix(r.URL.Path, prefix) {
+				tenant := strings.TrimPrefix(r.URL.Path, prefix)
+				if index := strings.Index(tenant, "/"); index != -1 {
+					tenant = tenant[:index]
+				}
+				if tenant != "" {
+					r = r.WithContext(ContextWithTenant(r.Context(), tenant))
+				}
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+/*
+AddTenantService adds service of tenant under given namespace, calls of tenant (see TenantFromContext) are
+dispatched to it instead of service added by AddService. Introspection lists services added by AddService.
+*/
+func (h *handler) AddTenantService(tenant string, service interface{}, name string) error {
+	if h.tenantServices == nil {
+		h.tenantServices = map[string]map[string]Service{}
+	}
+	if h.tenantServices[tenant] == nil {
+		h.tenantServices[tenant] = map[string]Service{}
+	}
+	return h.register(h.tenantServices[tenant], service, name)
+}
+
+/*
+service returns service registered under given name, service of tenant of call has precedence
+*/
+func (h *handler) service(ctx context.Context, name string) (Service, bool) {
+	if tenant := TenantFromContext(ctx); tenant != "" {
+		if s, ok := h.tenantServices[tenant][name]; ok {
+			return s, true
+		}
+	}
+	s, ok := h.services[name]
+	return s, ok
+}
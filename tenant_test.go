@@ -0,0 +1,41 @@
+package xmlrpc
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTenants(t *testing.T) {
+	h := NewHandler()
+	if err := h.AddService(&testService{name: "shared"}, "test"); err != nil {
+		t.Fatalf("cannot add service: %v", err)
+	}
+	if err := h.AddTenantService("acme", &testService{name: "acme"}, "test"); err != nil {
+		t.Fatalf("cannot add tenant service: %v", err)
+	}
+
+	for _, item := range []struct {
+		middleware Middleware
+		path       string
+		header     string
+		expected   string
+	}{
+		{TenantFromPath("/rpc/"), "/rpc/acme", "", "acme.Echo"},
+		{TenantFromPath("/rpc/"), "/rpc/acme/v1", "", "acme.Echo"},
+		{TenantFromPath("/rpc/"), "/rpc/other", "", "shared.Echo"},
+		{TenantFromPath("/rpc/"), "/other/acme", "", "shared.Echo"},
+		{TenantFromHeader("X-Tenant"), "/", "acme", "acme.Echo"},
+		{TenantFromHeader("X-Tenant"), "/", "", "shared.Echo"},
+	} {
+		request := newTestCall("test.Echo", "")
+		request.URL.Path = item.path
+		if item.header != "" {
+			request.Header.Set("X-Tenant", item.header)
+		}
+		response := serveTestCall(Chain(h, item.middleware), request)
+
+		if body := response.Body.String(); !strings.Contains(body, item.expected) {
+			t.Errorf("%v %v: expected %v, got %v", item.path, item.header, item.expected, body)
+		}
+	}
+}
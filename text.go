@@ -0,0 +1,36 @@
+package xmlrpc
+
+import (
+	"encoding"
+
+	"github.com/beevik/etree"
+)
+
+/*
+FormatText returns text of value that implements encoding.TextMarshaler (big numbers, decimals...),
+generated code writes it as string
+*/
+func FormatText(value encoding.TextMarshaler) (string, error) {
+	text, err := value.MarshalText()
+	if err != nil {
+		return "", err
+	}
+	return string(text), nil
+}
+
+/*
+XPathValueGetText Unmarshals string value into target that implements encoding.TextUnmarshaler
+*/
+func XPathValueGetText(element *etree.Element, name string, target encoding.TextUnmarshaler) (err error) {
+	var text string
+
+	if text, err = XPathValueGetString(element, name); err != nil {
+		return
+	}
+
+	if err = target.UnmarshalText([]byte(text)); err != nil {
+		err = Errorf(FaultInvalidParams, "invalid %v: %v", name, err)
+	}
+
+	return
+}
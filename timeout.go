@@ -0,0 +1,90 @@
+package xmlrpc
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+)
+
+/*
+TimeoutLister is implemented by generated services, it returns timeouts of methods (set by "//xmlrpc:timeout=30s"
+directive), methods that are not listed have no timeout
+*/
+type TimeoutLister interface {
+	MethodTimeouts() map[string]time.Duration
+}
+
+/*
+NewTimeoutTransport returns http transport for clients, calls of methods of service registered under namespace get
+deadline of their "//xmlrpc:timeout" directive (unless request context has earlier one), so clients don't wait
+longer than server would. Deadline ends when response body is closed. Put it before NewDeadlineTransport, so
+server gets the deadline too. Nil base is http.DefaultTransport.
+
+	transport := xmlrpc.NewTimeoutTransport(xmlrpc.NewDeadlineTransport(nil), "posts", &PostsService{})
+*/
+func NewTimeoutTransport(base http.RoundTripper, namespace string, service TimeoutLister) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	timeouts := map[string]time.Duration{}
+	for method, timeout := range service.MethodTimeouts() {
+		timeouts[fullMethodName(namespace, method)] = timeout
+	}
+	return timeoutTransport{base: base, timeouts: timeouts}
+}
+
+/*
+timeoutTransport sets deadline of calls by full method name
+*/
+type timeoutTransport struct {
+	base     http.RoundTripper
+	timeouts map[string]time.Duration
+}
+
+func (t timeoutTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	if request.Body == nil || len(t.timeouts) == 0 {
+		return t.base.RoundTrip(request)
+	}
+
+	body, err := ioutil.ReadAll(request.Body)
+	request.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	timeout, ok := t.timeouts[callMethodName(body)]
+	if !ok {
+		// request must not be modified, it's cloned
+		clone := request.WithContext(request.Context())
+		clone.Body = ioutil.NopCloser(bytes.NewReader(body))
+		return t.base.RoundTrip(clone)
+	}
+
+	ctx, cancel := context.WithTimeout(request.Context(), timeout)
+	clone := request.WithContext(ctx)
+	clone.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	response, err := t.base.RoundTrip(clone)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	response.Body = cancelReadCloser{ReadCloser: response.Body, cancel: cancel}
+	return response, nil
+}
+
+/*
+cancelReadCloser cancels context of request when response body is closed
+*/
+type cancelReadCloser struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (c cancelReadCloser) Close() error {
+	defer c.cancel()
+	return c.ReadCloser.Close()
+}
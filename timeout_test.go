@@ -0,0 +1,70 @@
+package xmlrpc
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+/*
+timeoutRegistry is registry with timeouts of methods
+*/
+type timeoutRegistry struct {
+	*Registry
+}
+
+func (t *timeoutRegistry) MethodTimeouts() map[string]time.Duration {
+	return map[string]time.Duration{"Slow": 20 * time.Millisecond}
+}
+
+func TestTimeoutTransport(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register("Slow", func(ctx context.Context, params ...*Value) (*Value, error) {
+		select {
+		case <-time.After(time.Second):
+		case <-ctx.Done():
+		}
+		return NewInt(42), nil
+	})
+	registry.Register("Fast", func(ctx context.Context, params ...*Value) (*Value, error) {
+		if _, ok := ctx.Deadline(); ok {
+			return nil, Errorf(FaultInvalidParams, "unexpected deadline")
+		}
+		return NewInt(42), nil
+	})
+	service := &timeoutRegistry{registry}
+	h := NewHandler()
+	if err := h.AddService(service, "posts"); err != nil {
+		t.Fatalf("cannot add service: %v", err)
+	}
+
+	server := httptest.NewServer(h)
+	defer server.Close()
+
+	client := &http.Client{Transport: NewTimeoutTransport(NewDeadlineTransport(nil), "posts", service)}
+	call := func(method string) ([]byte, error) {
+		response, err := client.Post(server.URL, "text/xml", bytes.NewReader(encodeCall(method, nil)))
+		if err != nil {
+			return nil, err
+		}
+		defer response.Body.Close()
+		return ioutil.ReadAll(response.Body)
+	}
+
+	// server gets deadline of client, so both give up on time
+	start := time.Now()
+	call("posts.Slow")
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("expected call to time out, took %v", elapsed)
+	}
+
+	if body, err := call("posts.Fast"); err != nil {
+		t.Errorf("call failed: %v", err)
+	} else if result, err := parseResponse(context.Background(), body); err != nil || result.Int() != 42 {
+		t.Errorf("expected result without deadline, got %s (%v)", body, err)
+	}
+}
@@ -0,0 +1,54 @@
+package xmlrpc
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/beevik/etree"
+)
+
+var (
+	// locations caches locations loaded for ",tz=" field tags
+	locations = struct {
+		sync.RWMutex
+		byName map[string]*time.Location
+	}{byName: map[string]*time.Location{}}
+)
+
+/*
+LoadLocation returns location with given name (e.g. "Europe/Prague"), loaded locations are cached
+*/
+func LoadLocation(name string) (result *time.Location, err error) {
+	locations.RLock()
+	result, ok := locations.byName[name]
+	locations.RUnlock()
+
+	if ok {
+		return
+	}
+
+	if result, err = time.LoadLocation(name); err != nil {
+		return
+	}
+
+	locations.Lock()
+	locations.byName[name] = result
+	locations.Unlock()
+
+	return
+}
+
+/*
+XPathValueGetTimeIn Returns time from dateTime.iso8601 value, values without offset are in given location
+(",tz=" field tag) instead of location of policy stored in context
+*/
+func XPathValueGetTimeIn(ctx context.Context, element *etree.Element, name string, location string) (result time.Time, err error) {
+	policy := DateTimePolicyFromContext(ctx)
+	if policy.Location, err = LoadLocation(location); err != nil {
+		err = Errorf(FaultInternalError, "unknown time zone %v of %v", location, name)
+		return
+	}
+
+	return XPathValueGetTime(ContextWithDateTimePolicy(ctx, policy), element, name)
+}
@@ -0,0 +1,127 @@
+package xmlrpc
+
+import (
+	"context"
+	"path"
+	"strconv"
+
+	"github.com/beevik/etree"
+)
+
+/*
+ParamsTransformer rewrites params of forwarded call (rename members, inject defaults, drop fields), so old clients
+keep working against changed upstream
+*/
+type ParamsTransformer func(ctx context.Context, method string, params []*Value) ([]*Value, error)
+
+/*
+ResultTransformer rewrites result of successful forwarded call (faults are not transformed)
+*/
+type ResultTransformer func(ctx context.Context, method string, result *Value) (*Value, error)
+
+/*
+WithForwardParams transforms params of forwarded methods that match pattern (path.Match syntax, name before
+rename). Transformers are called in order they are added.
+*/
+func WithForwardParams(pattern string, transform ParamsTransformer) ForwarderOption {
+	return func(f *forwarder) {
+		f.paramsTransformers = append(f.paramsTransformers, paramsTransformer{pattern: pattern, transform: transform})
+	}
+}
+
+/*
+WithForwardResult transforms results of forwarded methods that match pattern (path.Match syntax, name before
+rename). Transformers are called in order they are added.
+*/
+func WithForwardResult(pattern string, transform ResultTransformer) ForwarderOption {
+	return func(f *forwarder) {
+		f.resultTransformers = append(f.resultTransformers, resultTransformer{pattern: pattern, transform: transform})
+	}
+}
+
+type paramsTransformer struct {
+	pattern   string
+	transform ParamsTransformer
+}
+
+type resultTransformer struct {
+	pattern   string
+	transform ResultTransformer
+}
+
+/*
+transformParams returns params element rewritten by matching transformers, given element is returned when no
+transformer matches
+*/
+func (f *forwarder) transformParams(ctx context.Context, method string, params *etree.Element) (result *etree.Element, err error) {
+	var matching []ParamsTransformer
+	for _, item := range f.paramsTransformers {
+		if ok, _ := path.Match(item.pattern, method); ok {
+			matching = append(matching, item.transform)
+		}
+	}
+	if len(matching) == 0 {
+		return params, nil
+	}
+
+	values := []*Value{}
+	for i, value := range XPathParams(params) {
+		name := "param " + strconv.Itoa(i+1)
+		if value == nil {
+			return nil, Errorf(FaultInvalidParams, "could not find %v", name)
+		}
+
+		var item *Value
+		if item, err = XPathValueGetValue(value, name); err != nil {
+			return
+		}
+		values = append(values, item)
+	}
+
+	for _, transform := range matching {
+		if values, err = transform(ctx, method, values); err != nil {
+			return
+		}
+	}
+
+	result = etree.NewElement("params")
+	for _, item := range values {
+		if err = XMLWriteRaw(result.CreateElement("param").CreateElement("value"), item.Raw()); err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+/*
+transformResult rewrites result of methodResponse document by matching transformers
+*/
+func (f *forwarder) transformResult(ctx context.Context, method string, doc *etree.Document) (err error) {
+	var matching []ResultTransformer
+	for _, item := range f.resultTransformers {
+		if ok, _ := path.Match(item.pattern, method); ok {
+			matching = append(matching, item.transform)
+		}
+	}
+
+	// faults are not transformed
+	param := doc.FindElement("methodResponse/params/param")
+	if len(matching) == 0 || param == nil || param.SelectElement("value") == nil {
+		return nil
+	}
+
+	var result *Value
+	if result, err = XPathValueGetValue(param.SelectElement("value"), "result"); err != nil {
+		return
+	}
+
+	for _, transform := range matching {
+		if result, err = transform(ctx, method, result); err != nil {
+			return
+		}
+	}
+
+	param.RemoveChild(param.SelectElement("value"))
+	return XMLWriteRaw(param.CreateElement("value"), result.Raw())
+}
@@ -0,0 +1,167 @@
+package xmlrpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+/*
+UploadOption configures Upload
+*/
+type UploadOption func(u *uploadBody)
+
+/*
+WithUploadProgress sets callback that is called with count of data bytes sent so far, after every chunk
+*/
+func WithUploadProgress(progress func(sent int64)) UploadOption {
+	return func(u *uploadBody) {
+		u.progress = progress
+	}
+}
+
+/*
+WithUploadSize sets size of data, request then has Content-Length instead of chunked body (some legacy servers
+don't accept chunked requests). Reader must have exactly size bytes.
+*/
+func WithUploadSize(size int64) UploadOption {
+	return func(u *uploadBody) {
+		u.size = size
+	}
+}
+
+/*
+Upload calls method over http with params followed by base64 param with data read from reader. Call is streamed:
+data are read and encoded in chunks while request is sent, so payload never needs to be in memory. Request has
+"Expect: 100-continue" header, so server can reject call (e.g. unauthorized or too large) before data are sent,
+transport of client must have ExpectContinueTimeout (http.DefaultTransport has). Reader is closed if it's
+io.Closer, nil reader is empty value. Fault responses are returned as *Fault. Nil client is http.DefaultClient.
+
+	file, _ := os.Open("backup.tar")
+	result, err := xmlrpc.Upload(ctx, nil, url, "backup.Store", []*xmlrpc.Value{xmlrpc.NewString("daily")}, file,
+		xmlrpc.WithUploadProgress(func(sent int64) { log.Printf("sent %v bytes", sent) }))
+*/
+func Upload(ctx context.Context, client *http.Client, url, method string, params []*Value, data io.Reader, options ...UploadOption) (*Value, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	body := newUploadBody(method, params, data)
+	for _, option := range options {
+		option(body)
+	}
+
+	request, err := http.NewRequest("POST", url, body)
+	if err != nil {
+		body.Close()
+		return nil, err
+	}
+	request = request.WithContext(ctx)
+	request.Header.Set("Content-Type", "text/xml")
+	request.Header.Set("Expect", "100-continue")
+	request.ContentLength = body.contentLength()
+
+	response, err := client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, Errorf(FaultTransportError, "upload of %v failed with status %v", method, response.Status)
+	}
+
+	result, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return nil, err
+	}
+	return parseResponse(ctx, result)
+}
+
+/*
+uploadBody is methodCall document with data encoded as last param while it's read
+*/
+type uploadBody struct {
+	prefix   []byte
+	suffix   []byte
+	data     io.Reader
+	size     int64
+	progress func(sent int64)
+
+	sent    int64
+	chunk   []byte
+	buf     bytes.Buffer
+	encoder io.WriteCloser
+	done    bool
+}
+
+/*
+newUploadBody returns body of call of method with params and data
+*/
+func newUploadBody(method string, params []*Value, data io.Reader) *uploadBody {
+	if data == nil {
+		data = strings.NewReader("")
+	}
+	end := []byte("</params></methodCall>")
+	call := bytes.TrimSuffix(encodeCall(method, params), end)
+
+	result := &uploadBody{
+		prefix: append(call, "<param><value><base64>"...),
+		suffix: append([]byte("</base64></value></param>"), end...),
+		data:   data,
+		size:   -1,
+		chunk:  make([]byte, base64ChunkSize),
+	}
+	result.encoder = base64.NewEncoder(base64.StdEncoding, &result.buf)
+	result.buf.Write(result.prefix)
+	return result
+}
+
+/*
+contentLength returns length of body, -1 when size of data is unknown
+*/
+func (u *uploadBody) contentLength() int64 {
+	if u.size < 0 {
+		return -1
+	}
+	return int64(len(u.prefix)) + int64(base64.StdEncoding.EncodedLen(int(u.size))) + int64(len(u.suffix))
+}
+
+func (u *uploadBody) Read(p []byte) (int, error) {
+	for u.buf.Len() == 0 {
+		if u.done {
+			return 0, io.EOF
+		}
+
+		read, err := u.data.Read(u.chunk)
+		if read > 0 {
+			u.encoder.Write(u.chunk[:read])
+			u.sent += int64(read)
+			if u.progress != nil {
+				u.progress(u.sent)
+			}
+		}
+		if err == io.EOF {
+			u.encoder.Close()
+			u.buf.Write(u.suffix)
+			u.done = true
+		} else if err != nil {
+			return 0, err
+		}
+	}
+	return u.buf.Read(p)
+}
+
+/*
+Close closes data if it's io.Closer
+*/
+func (u *uploadBody) Close() error {
+	if closer, ok := u.data.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package xmlrpc
+
+import (
+	"encoding/hex"
+	"strings"
+
+	"github.com/beevik/etree"
+)
+
+/*
+FormatUUID returns canonical representation of uuid (xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx)
+*/
+func FormatUUID(uuid [16]byte) string {
+	buf := make([]byte, 36)
+
+	hex.Encode(buf[0:8], uuid[0:4])
+	buf[8] = '-'
+	hex.Encode(buf[9:13], uuid[4:6])
+	buf[13] = '-'
+	hex.Encode(buf[14:18], uuid[6:8])
+	buf[18] = '-'
+	hex.Encode(buf[19:23], uuid[8:10])
+	buf[23] = '-'
+	hex.Encode(buf[24:], uuid[10:])
+
+	return string(buf)
+}
+
+/*
+ParseUUID parses uuid in canonical form, optionally wrapped in braces or prefixed with "urn:uuid:"
+*/
+func ParseUUID(text string) (result [16]byte, err error) {
+	text = strings.TrimPrefix(strings.TrimSpace(text), "urn:uuid:")
+	if strings.HasPrefix(text, "{") && strings.HasSuffix(text, "}") {
+		text = text[1 : len(text)-1]
+	}
+
+	if len(text) != 36 || text[8] != '-' || text[13] != '-' || text[18] != '-' || text[23] != '-' {
+		err = Errorf(FaultInvalidParams, "invalid uuid %v", text)
+		return
+	}
+
+	if _, err = hex.Decode(result[:], []byte(strings.Replace(text, "-", "", -1))); err != nil {
+		err = Errorf(FaultInvalidParams, "invalid uuid %v", text)
+	}
+
+	return
+}
+
+/*
+XPathValueGetUUID Returns uuid from string value
+*/
+func XPathValueGetUUID(element *etree.Element, name string) (result [16]byte, err error) {
+	var text string
+
+	if text, err = XPathValueGetString(element, name); err != nil {
+		return
+	}
+
+	if result, err = ParseUUID(text); err != nil {
+		err = Errorf(FaultInvalidParams, "invalid uuid %v", name)
+	}
+
+	return
+}
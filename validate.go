@@ -0,0 +1,73 @@
+package xmlrpc
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+var (
+	// patterns are compiled patterns of ValidatePattern
+	patterns      = map[string]*regexp.Regexp{}
+	patternsMutex = sync.RWMutex{}
+)
+
+/*
+ValidateMaxLength returns invalid params fault when string has more than max characters (generated code of
+strings tagged ",max=N")
+*/
+func ValidateMaxLength(value string, max int, name string) error {
+	if utf8.RuneCountInString(value) > max {
+		return Errorf(FaultInvalidParams, "%v is longer than %v characters", name, max)
+	}
+	return nil
+}
+
+/*
+ValidatePattern returns invalid params fault when string doesn't match regular expression (generated code of
+strings tagged ",pattern=^[a-z]+$"). Pattern is not anchored implicitly, patterns are compiled once.
+*/
+func ValidatePattern(value string, pattern string, name string) error {
+	re, err := compilePattern(pattern)
+	if err != nil {
+		return Errorf(FaultInternalError, "invalid pattern of %v: %v", name, err)
+	}
+	if !re.MatchString(value) {
+		return Errorf(FaultInvalidParams, "%v doesn't match pattern %v", name, pattern)
+	}
+	return nil
+}
+
+/*
+MissingMembers returns invalid params fault that lists all missing members of struct (generated code of fields
+tagged ",required"), nil is returned when no member is missing
+*/
+func MissingMembers(name string, missing []string) error {
+	if len(missing) == 0 {
+		return nil
+	}
+	return Errorf(FaultInvalidParams, "missing required members of %v: %v", name, strings.Join(missing, ", "))
+}
+
+/*
+compilePattern returns compiled pattern from cache
+*/
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	patternsMutex.RLock()
+	re, ok := patterns[pattern]
+	patternsMutex.RUnlock()
+	if ok {
+		return re, nil
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	patternsMutex.Lock()
+	patterns[pattern] = re
+	patternsMutex.Unlock()
+	return re, nil
+}
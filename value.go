@@ -0,0 +1,584 @@
+package xmlrpc
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/beevik/etree"
+)
+
+/*
+Kind is xmlrpc type of Value
+*/
+type Kind int
+
+const (
+	KindNil Kind = iota
+	KindInt
+	KindBoolean
+	KindString
+	KindDouble
+	KindDateTime
+	KindBase64
+	KindStruct
+	KindArray
+)
+
+var (
+	kindNames = map[Kind]string{
+		KindNil:      "nil",
+		KindInt:      "int",
+		KindBoolean:  "boolean",
+		KindString:   "string",
+		KindDouble:   "double",
+		KindDateTime: "dateTime.iso8601",
+		KindBase64:   "base64",
+		KindStruct:   "struct",
+		KindArray:    "array",
+	}
+)
+
+/*
+String returns xmlrpc name of kind
+*/
+func (k Kind) String() string {
+	return kindNames[k]
+}
+
+/*
+Value is dynamic xmlrpc value, payloads can be built and inspected without go types. Accessors of other kind
+return zero values (e.g. Int() of string value is 0). Struct members keep their order.
+*/
+type Value struct {
+	kind Kind
+
+	i int
+	b bool
+	s string
+	f float64
+	t time.Time
+	d []byte
+
+	names   []string
+	members map[string]*Value
+	items   []*Value
+}
+
+/*
+NewNil returns nil value (extension, not in specification)
+*/
+func NewNil() *Value { return &Value{kind: KindNil} }
+
+/*
+NewInt returns int value
+*/
+func NewInt(i int) *Value { return &Value{kind: KindInt, i: i} }
+
+/*
+NewBool returns boolean value
+*/
+func NewBool(b bool) *Value { return &Value{kind: KindBoolean, b: b} }
+
+/*
+NewString returns string value
+*/
+func NewString(s string) *Value { return &Value{kind: KindString, s: s} }
+
+/*
+NewDouble returns double value
+*/
+func NewDouble(f float64) *Value { return &Value{kind: KindDouble, f: f} }
+
+/*
+NewDateTime returns dateTime.iso8601 value
+*/
+func NewDateTime(t time.Time) *Value { return &Value{kind: KindDateTime, t: t} }
+
+/*
+NewBase64 returns base64 value
+*/
+func NewBase64(d []byte) *Value { return &Value{kind: KindBase64, d: d} }
+
+/*
+NewStruct returns struct value without members, members are added by Set
+*/
+func NewStruct() *Value { return &Value{kind: KindStruct, members: map[string]*Value{}} }
+
+/*
+NewArray returns array value with given items
+*/
+func NewArray(items ...*Value) *Value { return &Value{kind: KindArray, items: items} }
+
+/*
+Set sets struct member (existing member keeps its position) and returns struct, so calls can be chained
+*/
+func (v *Value) Set(name string, member *Value) *Value {
+	if v.kind != KindStruct {
+		return v
+	}
+	if _, ok := v.members[name]; !ok {
+		v.names = append(v.names, name)
+	}
+	v.members[name] = member
+	return v
+}
+
+/*
+Delete removes struct member and returns struct, so calls can be chained
+*/
+func (v *Value) Delete(name string) *Value {
+	if v.Kind() != KindStruct {
+		return v
+	}
+	if _, ok := v.members[name]; !ok {
+		return v
+	}
+	delete(v.members, name)
+	for i, item := range v.names {
+		if item == name {
+			v.names = append(v.names[:i], v.names[i+1:]...)
+			break
+		}
+	}
+	return v
+}
+
+/*
+Rename renames struct member (it keeps its position, member with new name is replaced) and returns struct
+*/
+func (v *Value) Rename(name, newName string) *Value {
+	member := v.Member(name)
+	if member == nil || name == newName {
+		return v
+	}
+	v.Delete(newName)
+	for i, item := range v.names {
+		if item == name {
+			v.names[i] = newName
+			break
+		}
+	}
+	delete(v.members, name)
+	v.members[newName] = member
+	return v
+}
+
+/*
+Append appends items to array and returns array, so calls can be chained
+*/
+func (v *Value) Append(items ...*Value) *Value {
+	if v.kind == KindArray {
+		v.items = append(v.items, items...)
+	}
+	return v
+}
+
+/*
+Kind returns xmlrpc type of value, nil *Value is KindNil
+*/
+func (v *Value) Kind() Kind {
+	if v == nil {
+		return KindNil
+	}
+	return v.kind
+}
+
+/*
+Int returns value of int
+*/
+func (v *Value) Int() int {
+	if v.Kind() != KindInt {
+		return 0
+	}
+	return v.i
+}
+
+/*
+Bool returns value of boolean
+*/
+func (v *Value) Bool() bool {
+	return v.Kind() == KindBoolean && v.b
+}
+
+/*
+Text returns value of string
+*/
+func (v *Value) Text() string {
+	if v.Kind() != KindString {
+		return ""
+	}
+	return v.s
+}
+
+/*
+Double returns value of double
+*/
+func (v *Value) Double() float64 {
+	if v.Kind() != KindDouble {
+		return 0
+	}
+	return v.f
+}
+
+/*
+Time returns value of dateTime.iso8601
+*/
+func (v *Value) Time() time.Time {
+	if v.Kind() != KindDateTime {
+		return time.Time{}
+	}
+	return v.t
+}
+
+/*
+Bytes returns value of base64
+*/
+func (v *Value) Bytes() []byte {
+	if v.Kind() != KindBase64 {
+		return nil
+	}
+	return v.d
+}
+
+/*
+Member returns struct member by name (nil when struct has no such member)
+*/
+func (v *Value) Member(name string) *Value {
+	if v.Kind() != KindStruct {
+		return nil
+	}
+	return v.members[name]
+}
+
+/*
+Names returns names of struct members in order
+*/
+func (v *Value) Names() []string {
+	if v.Kind() != KindStruct {
+		return nil
+	}
+	return append([]string{}, v.names...)
+}
+
+/*
+Items returns array items
+*/
+func (v *Value) Items() []*Value {
+	if v.Kind() != KindArray {
+		return nil
+	}
+	return v.items
+}
+
+/*
+Len returns count of array items or struct members
+*/
+func (v *Value) Len() int {
+	switch v.Kind() {
+	case KindArray:
+		return len(v.items)
+	case KindStruct:
+		return len(v.names)
+	}
+	return 0
+}
+
+/*
+Index returns array item at given index (nil when out of range)
+*/
+func (v *Value) Index(i int) *Value {
+	if v.Kind() != KindArray || i < 0 || i >= len(v.items) {
+		return nil
+	}
+	return v.items[i]
+}
+
+/*
+Clone returns deep copy of value, base64 data, struct members and array items are copied too
+*/
+func (v *Value) Clone() *Value {
+	if v == nil {
+		return nil
+	}
+
+	result := *v
+	if v.d != nil {
+		result.d = append([]byte{}, v.d...)
+	}
+	if v.members != nil {
+		result.names = append([]string{}, v.names...)
+		result.members = make(map[string]*Value, len(v.members))
+		for name, member := range v.members {
+			result.members[name] = member.Clone()
+		}
+	}
+	if v.items != nil {
+		result.items = make([]*Value, 0, len(v.items))
+		for _, item := range v.items {
+			result.items = append(result.items, item.Clone())
+		}
+	}
+	return &result
+}
+
+/*
+Equal returns whether values are equal. Order of struct members is ignored, dateTime values are compared as
+instants (time.Time.Equal) and NaN doubles are equal to each other.
+*/
+func (v *Value) Equal(other *Value) bool {
+	if v.Kind() != other.Kind() {
+		return false
+	}
+
+	switch v.Kind() {
+	case KindNil:
+		return true
+	case KindInt:
+		return v.i == other.i
+	case KindBoolean:
+		return v.b == other.b
+	case KindString:
+		return v.s == other.s
+	case KindDouble:
+		return v.f == other.f || (math.IsNaN(v.f) && math.IsNaN(other.f))
+	case KindDateTime:
+		return v.t.Equal(other.t)
+	case KindBase64:
+		return bytes.Equal(v.d, other.d)
+	case KindStruct:
+		if len(v.members) != len(other.members) {
+			return false
+		}
+		for name, member := range v.members {
+			if otherMember, ok := other.members[name]; !ok || !member.Equal(otherMember) {
+				return false
+			}
+		}
+		return true
+	case KindArray:
+		if len(v.items) != len(other.items) {
+			return false
+		}
+		for i, item := range v.items {
+			if !item.Equal(other.items[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+/*
+Raw returns value encoded as content of value element
+*/
+func (v *Value) Raw() RawValue {
+	buf := &bytes.Buffer{}
+	v.writeTo(buf, false)
+	return RawValue(buf.Bytes())
+}
+
+/*
+EncodeTo writes value encoded as content of value element to w without building it in memory first
+*/
+func (v *Value) EncodeTo(w io.Writer) error {
+	buf := bufio.NewWriter(w)
+	v.writeTo(buf, false)
+	return buf.Flush()
+}
+
+/*
+DecodeFrom sets value to value decoded from content of value element read from r
+*/
+func (v *Value) DecodeFrom(r io.Reader) error {
+	doc := etree.NewDocument()
+	if _, err := doc.ReadFrom(io.MultiReader(strings.NewReader("<value>"), r, strings.NewReader("</value>"))); err != nil {
+		return parseError(err)
+	}
+
+	decoded, err := XPathValueGetValue(doc.Root(), "value")
+	if err != nil {
+		return err
+	}
+	*v = *decoded
+	return nil
+}
+
+/*
+valueWriter is buffer that value is written to (*bytes.Buffer, *bufio.Writer)
+*/
+type valueWriter interface {
+	io.Writer
+	io.StringWriter
+}
+
+/*
+writeTo writes value to buffer, Apache extensions are used when apache is true
+*/
+func (v *Value) writeTo(buf valueWriter, apache bool) {
+	scalar := func(tag, text string) {
+		buf.WriteString("<" + tag + ">")
+		XMLWriteEscaped(buf, text)
+		buf.WriteString("</" + tag + ">")
+	}
+
+	switch v.Kind() {
+	case KindNil:
+		if apache {
+			writeApacheExtension(buf, "nil", "")
+			return
+		}
+		buf.WriteString("<nil/>")
+	case KindInt:
+		if apache && (v.i < math.MinInt32 || v.i > math.MaxInt32) {
+			writeApacheExtension(buf, "i8", strconv.Itoa(v.i))
+			return
+		}
+		scalar("int", strconv.Itoa(v.i))
+	case KindBoolean:
+		scalar("boolean", FormatBool(v.b))
+	case KindString:
+		scalar("string", v.s)
+	case KindDouble:
+		scalar("double", FormatDouble(v.f, 64))
+	case KindDateTime:
+		if apache {
+			writeApacheExtension(buf, "dateTime", v.t.In(DefaultDateTimePolicy.location()).Format(apacheDateTimeLayout))
+			return
+		}
+		scalar("dateTime.iso8601", DefaultDateTimePolicy.Format(v.t))
+	case KindBase64:
+		buf.WriteString("<base64>")
+		encoder := base64.NewEncoder(base64.StdEncoding, buf)
+		encoder.Write(v.d)
+		encoder.Close()
+		buf.WriteString("</base64>")
+	case KindStruct:
+		buf.WriteString("<struct>")
+		for _, name := range v.names {
+			buf.WriteString("<member><name>")
+			XMLWriteEscaped(buf, name)
+			buf.WriteString("</name><value>")
+			v.members[name].writeTo(buf, apache)
+			buf.WriteString("</value></member>")
+		}
+		buf.WriteString("</struct>")
+	case KindArray:
+		buf.WriteString("<array><data>")
+		for _, item := range v.items {
+			buf.WriteString("<value>")
+			item.writeTo(buf, apache)
+			buf.WriteString("</value>")
+		}
+		buf.WriteString("</data></array>")
+	}
+}
+
+/*
+ParseValue parses content of value element (e.g. RawValue)
+*/
+func ParseValue(raw RawValue) (*Value, error) {
+	element, err := raw.element()
+	if err != nil {
+		return nil, err
+	}
+	return XPathValueGetValue(element, "value")
+}
+
+/*
+XPathValueGetValue returns dynamic value of value element
+*/
+func XPathValueGetValue(element *etree.Element, name string) (result *Value, err error) {
+	return xpathValue(element, name, nil)
+}
+
+/*
+xpathValue returns value of value element, arrays are limited by limit (nil for no limit)
+*/
+func xpathValue(element *etree.Element, name string, limit *arrayLimit) (result *Value, err error) {
+	children := element.ChildElements()
+	if len(children) == 0 {
+		return NewString(element.Text()), nil
+	}
+
+	typ := children[0]
+	text := strings.TrimSpace(typ.Text())
+
+	switch typ.Tag {
+	case "int", "i4", "i8":
+		var i int
+		if i, err = strconv.Atoi(text); err != nil {
+			return nil, Errorf(FaultInvalidParams, "invalid int %v", name)
+		}
+		return NewInt(i), nil
+	case "boolean":
+		if text != "0" && text != "1" {
+			return nil, Errorf(FaultInvalidParams, "invalid boolean %v", name)
+		}
+		return NewBool(text == "1"), nil
+	case "string":
+		return NewString(typ.Text()), nil
+	case "double":
+		var f float64
+		if f, err = ParseDoubleLenient(text); err != nil {
+			f, err = ParseNonFiniteDouble(text)
+		}
+		if err != nil {
+			return nil, Errorf(FaultInvalidParams, "invalid double %v", name)
+		}
+		return NewDouble(f), nil
+	case "dateTime.iso8601", "dateTime":
+		var t time.Time
+		if t, err = DefaultDateTimePolicy.Parse(text); err != nil {
+			return nil, Errorf(FaultInvalidParams, "invalid dateTime.iso8601 %v", name)
+		}
+		return NewDateTime(t), nil
+	case "base64":
+		var d []byte
+		if d, err = base64.StdEncoding.DecodeString(stripSpace(typ.Text())); err != nil {
+			return nil, Errorf(FaultInvalidParams, "invalid base64 %v", name)
+		}
+		return NewBase64(d), nil
+	case "nil":
+		return NewNil(), nil
+	case "struct":
+		result = NewStruct()
+		for _, member := range typ.ChildElements() {
+			var (
+				memberName  string
+				value       *etree.Element
+				memberValue *Value
+			)
+			if memberName, value, err = XPathStructMember(member); err != nil {
+				return nil, err
+			}
+			if memberValue, err = xpathValue(value, name+"."+memberName, limit); err != nil {
+				return nil, err
+			}
+			result.Set(memberName, memberValue)
+		}
+		return result, nil
+	case "array":
+		var values []*etree.Element
+		if values, err = XPathValueGetArray(element, name); err != nil {
+			return nil, err
+		}
+		result = NewArray()
+		for index, value := range limit.apply(values) {
+			var item *Value
+			if item, err = xpathValue(value, name+"."+strconv.Itoa(index), limit); err != nil {
+				return nil, err
+			}
+			result.Append(item)
+		}
+		return result, nil
+	}
+
+	return nil, Errorf(FaultInvalidParams, "unknown type %v of %v", typ.Tag, name)
+}
@@ -0,0 +1,270 @@
+package xmlrpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+/*
+ToInterface returns value as go value: int, bool, string, float64, time.Time, []byte, map[string]interface{},
+[]interface{} or nil (same values as Inspect returns)
+*/
+func (v *Value) ToInterface() interface{} {
+	switch v.Kind() {
+	case KindInt:
+		return v.i
+	case KindBoolean:
+		return v.b
+	case KindString:
+		return v.s
+	case KindDouble:
+		return v.f
+	case KindDateTime:
+		return v.t
+	case KindBase64:
+		return v.d
+	case KindStruct:
+		result := make(map[string]interface{}, len(v.names))
+		for _, name := range v.names {
+			result[name] = v.members[name].ToInterface()
+		}
+		return result
+	case KindArray:
+		result := make([]interface{}, 0, len(v.items))
+		for _, item := range v.items {
+			result = append(result, item.ToInterface())
+		}
+		return result
+	}
+	return nil
+}
+
+/*
+FromInterface returns value from go value, supported are values returned by ToInterface, other sizes of ints and
+floats, []string, map[string]string, json.Number, *Value and types with registered codec (see RegisterCodec).
+Members of maps are sorted by name.
+*/
+func FromInterface(value interface{}) (result *Value, err error) {
+	switch v := value.(type) {
+	case nil:
+		return NewNil(), nil
+	case *Value:
+		return v, nil
+	case int:
+		return NewInt(v), nil
+	case int8:
+		return NewInt(int(v)), nil
+	case int16:
+		return NewInt(int(v)), nil
+	case int32:
+		return NewInt(int(v)), nil
+	case int64:
+		return NewInt(int(v)), nil
+	case bool:
+		return NewBool(v), nil
+	case string:
+		return NewString(v), nil
+	case float32:
+		return NewDouble(float64(v)), nil
+	case float64:
+		return NewDouble(v), nil
+	case json.Number:
+		return numberValue(v)
+	case time.Time:
+		return NewDateTime(v), nil
+	case []byte:
+		return NewBase64(v), nil
+	case []string:
+		result = NewArray()
+		for _, item := range v {
+			result.Append(NewString(item))
+		}
+		return result, nil
+	case []interface{}:
+		result = NewArray()
+		for _, item := range v {
+			var itemValue *Value
+			if itemValue, err = FromInterface(item); err != nil {
+				return nil, err
+			}
+			result.Append(itemValue)
+		}
+		return result, nil
+	case map[string]string:
+		names := make([]string, 0, len(v))
+		for name := range v {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		result = NewStruct()
+		for _, name := range names {
+			result.Set(name, NewString(v[name]))
+		}
+		return result, nil
+	case map[string]interface{}:
+		names := make([]string, 0, len(v))
+		for name := range v {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		result = NewStruct()
+		for _, name := range names {
+			var member *Value
+			if member, err = FromInterface(v[name]); err != nil {
+				return nil, err
+			}
+			result.Set(name, member)
+		}
+		return result, nil
+	}
+
+	// custom types are encoded by registered codecs
+	if result, ok, err := codecValue(value); ok {
+		return result, err
+	}
+
+	return nil, fmt.Errorf("unsupported value %T", value)
+}
+
+/*
+MarshalJSON writes value as json, struct members keep their order. dateTime.iso8601 is written as RFC 3339
+string and base64 as base64 string.
+*/
+func (v *Value) MarshalJSON() ([]byte, error) {
+	switch v.Kind() {
+	case KindNil:
+		return []byte("null"), nil
+	case KindDouble:
+		if math.IsInf(v.f, 0) || math.IsNaN(v.f) {
+			return nil, fmt.Errorf("double %v is not valid json", v.f)
+		}
+	case KindStruct:
+		buf := &bytes.Buffer{}
+		buf.WriteString("{")
+		for i, name := range v.names {
+			if i > 0 {
+				buf.WriteString(",")
+			}
+			key, _ := json.Marshal(name)
+			member, err := v.members[name].MarshalJSON()
+			if err != nil {
+				return nil, err
+			}
+			buf.Write(key)
+			buf.WriteString(":")
+			buf.Write(member)
+		}
+		buf.WriteString("}")
+		return buf.Bytes(), nil
+	case KindArray:
+		buf := &bytes.Buffer{}
+		buf.WriteString("[")
+		for i, item := range v.items {
+			if i > 0 {
+				buf.WriteString(",")
+			}
+			data, err := item.MarshalJSON()
+			if err != nil {
+				return nil, err
+			}
+			buf.Write(data)
+		}
+		buf.WriteString("]")
+		return buf.Bytes(), nil
+	}
+	return json.Marshal(v.ToInterface())
+}
+
+/*
+ToJSON returns value as json (see MarshalJSON)
+*/
+func (v *Value) ToJSON() ([]byte, error) {
+	return v.MarshalJSON()
+}
+
+/*
+FromJSON returns value from json. Integral numbers are ints, other numbers doubles, objects are structs with
+members in order of json keys. Strings stay strings (dates and binary data can't be recognized).
+*/
+func FromJSON(data []byte) (result *Value, err error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	if result, err = jsonValue(decoder); err != nil {
+		return nil, err
+	}
+
+	if _, err = decoder.Token(); err != io.EOF {
+		return nil, fmt.Errorf("unexpected data after json value")
+	}
+	return result, nil
+}
+
+/*
+jsonValue decodes value from json tokens
+*/
+func jsonValue(decoder *json.Decoder) (result *Value, err error) {
+	var token json.Token
+	if token, err = decoder.Token(); err != nil {
+		return nil, err
+	}
+
+	switch t := token.(type) {
+	case json.Delim:
+		switch t {
+		case '{':
+			result = NewStruct()
+			for decoder.More() {
+				var key json.Token
+				if key, err = decoder.Token(); err != nil {
+					return nil, err
+				}
+				var member *Value
+				if member, err = jsonValue(decoder); err != nil {
+					return nil, err
+				}
+				result.Set(key.(string), member)
+			}
+		case '[':
+			result = NewArray()
+			for decoder.More() {
+				var item *Value
+				if item, err = jsonValue(decoder); err != nil {
+					return nil, err
+				}
+				result.Append(item)
+			}
+		}
+		// closing delimiter
+		if _, err = decoder.Token(); err != nil {
+			return nil, err
+		}
+		return result, nil
+	}
+
+	return FromInterface(token)
+}
+
+/*
+numberValue returns int value for integral numbers and double value for others
+*/
+func numberValue(number json.Number) (*Value, error) {
+	if !strings.ContainsAny(number.String(), ".eE") {
+		if i, err := number.Int64(); err == nil && i >= math.MinInt32 && i <= math.MaxInt32 {
+			return NewInt(int(i)), nil
+		}
+	}
+	f, err := number.Float64()
+	if err != nil {
+		return nil, err
+	}
+	return NewDouble(f), nil
+}
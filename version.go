@@ -0,0 +1,37 @@
+package xmlrpc
+
+import "fmt"
+
+const (
+	// GeneratedVersion is version of generated code written by generator of this runtime, it's increased whenever
+	// generated code starts to use runtime differently
+	GeneratedVersion = 1
+
+	// MinGeneratedVersion is oldest version of generated code this runtime still supports
+	MinGeneratedVersion = 1
+)
+
+/*
+CheckGeneratedVersion returns error when code generated with given version cannot be used with this runtime
+*/
+func CheckGeneratedVersion(version int) error {
+	if version < MinGeneratedVersion {
+		return fmt.Errorf("xmlrpc: generated code version %v is older than %v supported by runtime, "+
+			"regenerate it with xmlrpcgen", version, MinGeneratedVersion)
+	}
+	if version > GeneratedVersion {
+		return fmt.Errorf("xmlrpc: generated code version %v is newer than %v supported by runtime, "+
+			"update github.com/phonkee/go-xmlrpc", version, GeneratedVersion)
+	}
+	return nil
+}
+
+/*
+RequireGeneratedVersion panics when code generated with given version cannot be used with this runtime.
+Generated code calls it from init, so stale code fails on start instead of misbehaving during calls.
+*/
+func RequireGeneratedVersion(version int) {
+	if err := CheckGeneratedVersion(version); err != nil {
+		panic(err)
+	}
+}
@@ -1,6 +1,7 @@
 package xmlrpc
 
 import (
+	"net/http"
 	"strconv"
 
 	"github.com/beevik/etree"
@@ -11,25 +12,51 @@ XMLWriteError writes xml error
 */
 func XMLWriteError(element *etree.Element, err error) {
 
-	faultCode := 500
-
-	if e, ok := err.(Error); ok {
-		faultCode = e.Code()
-	}
+	faultCode := errorCode(err)
 
 	faultStruct := element.CreateElement("struct")
 	m1 := faultStruct.CreateElement("member")
 	m1.CreateElement("name").SetText("faultCode")
-	m1.CreateElement("value").SetText(strconv.Itoa(faultCode))
+	m1.CreateElement("value").CreateElement("int").SetText(strconv.Itoa(faultCode))
 
 	m2 := faultStruct.CreateElement("member")
 	m2.CreateElement("name").SetText("faultString")
-	m2.CreateElement("value").SetText(err.Error())
+	m2.CreateElement("value").CreateElement("string").SetText(err.Error())
+
+	// optional structured detail
+	if detailer, ok := err.(FaultDetailer); ok {
+		if detail := detailer.FaultDetail(); detail != nil {
+			m3 := faultStruct.CreateElement("member")
+			m3.CreateElement("name").SetText("detail")
+			XMLWriteInterface(m3.CreateElement("value"), detail)
+		}
+	}
+}
+
+/*
+newResponseDocument returns new document with xml declaration
+*/
+func newResponseDocument() *etree.Document {
+	doc := etree.NewDocument()
+	doc.CreateProcInst("xml", `version="1.0" encoding="UTF-8"`)
+	return doc
+}
+
+/*
+writeFault writes whole methodResponse document with fault to response writer
+*/
+func writeFault(w http.ResponseWriter, r *http.Request, err error) {
+	doc := newResponseDocument()
+	XMLWriteError(doc.CreateElement("methodResponse").CreateElement("fault").CreateElement("value"), err)
+
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(faultStatus(r.Context(), err))
+	doc.WriteTo(w)
 }
 
 /*
 XMLWriteStringSlice writes array of string slice
- */
+*/
 func XMLWriteStringSlice(element *etree.Element, stringSlice []string) {
 	dataElement := element.CreateElement("array").CreateElement("data")
 	for _, item := range stringSlice {
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/beevik/etree"
+	"github.com/phonkee/go-xmlrpc"
+	"github.com/urfave/cli"
+)
+
+func main() {
+	app := cli.NewApp()
+	app.Usage = "call xmlrpc method"
+	app.ArgsUsage = "URL method [value[:type]...]"
+	app.Flags = []cli.Flag{
+		cli.StringSliceFlag{
+			Name:  "header",
+			Usage: "Additional http header (\"Name: value\")",
+		},
+		cli.DurationFlag{
+			Name:  "timeout",
+			Value: 30 * time.Second,
+			Usage: "Timeout of call",
+		},
+		cli.BoolFlag{
+			Name:  "json",
+			Usage: "Print response as json",
+		},
+		cli.BoolFlag{
+			Name:  "dump",
+			Usage: "Print request and response xml",
+		},
+	}
+
+	app.Action = func(c *cli.Context) error {
+		if c.NArg() < 2 {
+			return fmt.Errorf("usage: xmlrpccall %v", app.ArgsUsage)
+		}
+
+		body, err := buildRequest(c.Args().Get(1), c.Args()[2:])
+		if err != nil {
+			return err
+		}
+
+		if c.Bool("dump") {
+			xmlrpc.Indent(os.Stderr, bytes.NewReader(body), "  ")
+			fmt.Fprintln(os.Stderr)
+		}
+
+		request, err := http.NewRequest("POST", c.Args().Get(0), bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		request.Header.Set("Content-Type", "text/xml")
+		for _, header := range c.StringSlice("header") {
+			parts := strings.SplitN(header, ":", 2)
+			if len(parts) != 2 {
+				return fmt.Errorf("invalid header %v", header)
+			}
+			request.Header.Add(strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1]))
+		}
+
+		client := &http.Client{Timeout: c.Duration("timeout")}
+		response, err := client.Do(request)
+		if err != nil {
+			return err
+		}
+		defer response.Body.Close()
+
+		responseBody := bytes.Buffer{}
+		if _, err = responseBody.ReadFrom(response.Body); err != nil {
+			return err
+		}
+
+		if c.Bool("dump") {
+			xmlrpc.Indent(os.Stderr, bytes.NewReader(responseBody.Bytes()), "  ")
+			fmt.Fprintln(os.Stderr)
+		}
+
+		payload, err := xmlrpc.Inspect(&responseBody)
+		if err != nil {
+			return fmt.Errorf("invalid response (http status %v): %v", response.Status, err)
+		}
+
+		if c.Bool("json") {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			if err = encoder.Encode(payload.Params); err != nil {
+				return err
+			}
+		} else {
+			for _, param := range payload.Params {
+				xmlrpc.FprintValue(os.Stdout, param, "")
+				fmt.Println()
+			}
+		}
+
+		for _, violation := range payload.Violations {
+			fmt.Fprintf(os.Stderr, "warning: %v: %v\n", violation.Path, violation.Message)
+		}
+
+		if payload.Kind == "fault" {
+			return fmt.Errorf("fault returned")
+		}
+		return nil
+	}
+
+	if err := app.Run(os.Args); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+/*
+buildRequest returns methodCall document with given method and arguments
+*/
+func buildRequest(method string, args []string) ([]byte, error) {
+	doc := etree.NewDocument()
+	doc.CreateProcInst("xml", `version="1.0" encoding="UTF-8"`)
+	call := doc.CreateElement("methodCall")
+	call.CreateElement("methodName").SetText(method)
+	params := call.CreateElement("params")
+
+	for _, arg := range args {
+		value, err := parseArg(arg)
+		if err != nil {
+			return nil, err
+		}
+		xmlrpc.XMLWriteInterface(params.CreateElement("param").CreateElement("value"), value)
+	}
+
+	buf := bytes.Buffer{}
+	if _, err := doc.WriteTo(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+/*
+parseArg parses argument in form value:type, when type is missing (or unknown) whole argument is string.
+Types are string, int, boolean, double, dateTime, base64 and json (structs and arrays).
+*/
+func parseArg(arg string) (interface{}, error) {
+	i := strings.LastIndex(arg, ":")
+	if i < 0 {
+		return arg, nil
+	}
+
+	text := arg[:i]
+
+	switch arg[i+1:] {
+	case "string":
+		return text, nil
+	case "int", "i4":
+		return strconv.Atoi(text)
+	case "boolean", "bool":
+		return strconv.ParseBool(text)
+	case "double":
+		return strconv.ParseFloat(text, 64)
+	case "dateTime", "dateTime.iso8601":
+		return xmlrpc.DefaultDateTimePolicy.Parse(text)
+	case "base64":
+		return base64.StdEncoding.DecodeString(text)
+	case "json":
+		decoder := json.NewDecoder(strings.NewReader(text))
+		decoder.UseNumber()
+
+		var value interface{}
+		if err := decoder.Decode(&value); err != nil {
+			return nil, err
+		}
+		return fromJSON(value), nil
+	}
+
+	return arg, nil
+}
+
+/*
+fromJSON converts decoded json to values supported by XMLWriteInterface (numbers to int or float64)
+*/
+func fromJSON(value interface{}) interface{} {
+	switch v := value.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		f, _ := v.Float64()
+		return f
+	case []interface{}:
+		for i := range v {
+			v[i] = fromJSON(v[i])
+		}
+	case map[string]interface{}:
+		for key := range v {
+			v[key] = fromJSON(v[key])
+		}
+	}
+	return value
+}
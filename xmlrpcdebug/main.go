@@ -0,0 +1,114 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/phonkee/go-xmlrpc"
+	"github.com/urfave/cli"
+)
+
+func main() {
+	app := cli.NewApp()
+	app.Usage = "inspect captured xmlrpc request/response body"
+	app.ArgsUsage = "[file]"
+	app.Flags = []cli.Flag{
+		cli.BoolFlag{
+			Name:  "json",
+			Usage: "Print payload as json",
+		},
+		cli.BoolFlag{
+			Name:  "xml",
+			Usage: "Print indented xml",
+		},
+		cli.StringFlag{
+			Name:  "corpus",
+			Usage: "Check all fixtures in directory against their expectations",
+		},
+	}
+
+	app.Action = func(c *cli.Context) error {
+		if dir := c.String("corpus"); dir != "" {
+			failures, checked, err := xmlrpc.CheckCorpus(dir)
+			if err != nil {
+				return err
+			}
+			for _, failure := range failures {
+				fmt.Println(failure)
+			}
+			fmt.Printf("%d fixtures checked, %d failed\n", checked, len(failures))
+			if len(failures) > 0 {
+				return fmt.Errorf("corpus check failed")
+			}
+			return nil
+		}
+
+		var input io.Reader = os.Stdin
+
+		if c.NArg() > 0 {
+			f, err := os.Open(c.Args().Get(0))
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			input = f
+		}
+
+		if c.Bool("xml") {
+			if err := xmlrpc.Indent(os.Stdout, input, "  "); err != nil {
+				return err
+			}
+			fmt.Println()
+			return nil
+		}
+
+		payload, err := xmlrpc.Inspect(input)
+		if err != nil {
+			return err
+		}
+
+		if c.Bool("json") {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(payload)
+		}
+
+		printPayload(payload)
+
+		if len(payload.Violations) > 0 {
+			return fmt.Errorf("payload has %d spec violations", len(payload.Violations))
+		}
+		return nil
+	}
+
+	if err := app.Run(os.Args); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+/*
+printPayload prints payload with typed values and violations
+*/
+func printPayload(payload *xmlrpc.Payload) {
+	if payload.Method != "" {
+		fmt.Printf("%v %v\n", payload.Kind, payload.Method)
+	} else {
+		fmt.Println(payload.Kind)
+	}
+
+	for i, param := range payload.Params {
+		fmt.Printf("param %d: ", i)
+		xmlrpc.FprintValue(os.Stdout, param, "")
+		fmt.Println()
+	}
+
+	if len(payload.Violations) > 0 {
+		fmt.Println("violations:")
+		for _, violation := range payload.Violations {
+			fmt.Printf("  %v: %v\n", violation.Path, violation.Message)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+/*
+diff prints differences between existing file and generated content, returns whether they differ
+*/
+func diff(out output, hash string) bool {
+	content := out.render(hash)
+
+	existing, err := ioutil.ReadFile(out.filename)
+	if os.IsNotExist(err) {
+		fmt.Printf("%v: does not exist\n", out.filename)
+		return true
+	} else if err != nil {
+		fmt.Printf("%v: %v\n", out.filename, err)
+		return true
+	}
+
+	if bytes.Equal(existing, content) {
+		return false
+	}
+
+	fmt.Printf("--- %v\n+++ %v (generated)\n", out.filename, out.filename)
+	printLineDiff(strings.Split(string(existing), "\n"), strings.Split(string(content), "\n"))
+	return true
+}
+
+const (
+	// diffContext is count of unchanged lines printed around changes
+	diffContext = 3
+
+	// diffMaxCells limits size of table of longest common subsequence
+	diffMaxCells = 16 << 20
+)
+
+/*
+diffLine is line of diff, kind is ' ' (unchanged), '-' (removed) or '+' (added)
+*/
+type diffLine struct {
+	kind byte
+	text string
+
+	// line is number of line in old file (new file for added lines)
+	line int
+}
+
+/*
+printLineDiff prints changed lines with few unchanged lines around them, lines are compared by longest common
+subsequence
+*/
+func printLineDiff(old, new []string) {
+	// common prefix and suffix are not compared
+	prefix := 0
+	for prefix < len(old) && prefix < len(new) && old[prefix] == new[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(old)-prefix && suffix < len(new)-prefix && old[len(old)-1-suffix] == new[len(new)-1-suffix] {
+		suffix++
+	}
+
+	// unchanged lines around changes are kept
+	if prefix -= diffContext; prefix < 0 {
+		prefix = 0
+	}
+	if suffix -= diffContext; suffix < 0 {
+		suffix = 0
+	}
+	old, new = old[prefix:len(old)-suffix], new[prefix:len(new)-suffix]
+
+	// too large changes are not compared line by line
+	if len(old)*len(new) > diffMaxCells {
+		fmt.Printf("@@ line %v @@\n%v lines changed to %v lines\n", prefix+1, len(old), len(new))
+		return
+	}
+
+	// lcs[i][j] is length of longest common subsequence of old[i:] and new[j:]
+	lcs := make([][]int32, len(old)+1)
+	for i := range lcs {
+		lcs[i] = make([]int32, len(new)+1)
+	}
+	for i := len(old) - 1; i >= 0; i-- {
+		for j := len(new) - 1; j >= 0; j-- {
+			if old[i] == new[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	lines := []diffLine{}
+	i, j := 0, 0
+	for i < len(old) || j < len(new) {
+		switch {
+		case i < len(old) && j < len(new) && old[i] == new[j]:
+			lines = append(lines, diffLine{' ', old[i], prefix + i + 1})
+			i++
+			j++
+		case i < len(old) && (j == len(new) || lcs[i+1][j] >= lcs[i][j+1]):
+			lines = append(lines, diffLine{'-', old[i], prefix + i + 1})
+			i++
+		default:
+			lines = append(lines, diffLine{'+', new[j], prefix + j + 1})
+			j++
+		}
+	}
+
+	// print only lines near changes
+	last := -1
+	for k, line := range lines {
+		near := false
+		for d := k - diffContext; d <= k+diffContext; d++ {
+			if d >= 0 && d < len(lines) && lines[d].kind != ' ' {
+				near = true
+				break
+			}
+		}
+		if !near {
+			continue
+		}
+		if last == -1 || last != k-1 {
+			fmt.Printf("@@ line %v @@\n", line.line)
+		}
+		fmt.Printf("%c%v\n", line.kind, line.text)
+		last = k
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"strings"
 
 	"github.com/phonkee/go-xmlrpc"
+	"github.com/phonkee/go-xmlrpc/gen"
 	"github.com/urfave/cli"
 )
 
@@ -23,42 +24,262 @@ func main() {
 		cli.BoolFlag{
 			Name: "debug",
 		},
+		cli.StringFlag{
+			Name:  "backend",
+			Value: gen.DefaultBackend,
+			Usage: "Backend that writes responses (etree, xml, writer)",
+		},
+		cli.StringFlag{
+			Name:  "member-order",
+			Value: gen.MemberOrderField,
+			Usage: "Order of struct members in responses (field, alpha)",
+		},
+		cli.BoolFlag{
+			Name:  "lenient-empty",
+			Usage: "Decode empty array sent instead of struct (and vice versa) as zero value (PHP servers)",
+		},
+		cli.BoolFlag{
+			Name:  "mocks",
+			Usage: "Write service interfaces and their mocks to <file>_xmlrpc_mock.go",
+		},
+		cli.BoolFlag{
+			Name:  "examples",
+			Usage: "Write examples that call service methods with sample requests to <file>_xmlrpc_example_test.go",
+		},
+		cli.IntFlag{
+			Name:  "workers",
+			Usage: "Maximum count of service methods processed concurrently (default GOMAXPROCS)",
+		},
+		cli.BoolFlag{
+			Name:  "force",
+			Usage: "Generate all outputs, also those whose services and options didn't change since last run",
+		},
+		cli.BoolFlag{
+			Name:  "dry-run",
+			Usage: "Print generated code to stdout instead of writing files",
+		},
+		cli.BoolFlag{
+			Name:  "diff",
+			Usage: "Compare generated code with existing files, print differences and exit with status 1 on change",
+		},
+		cli.BoolFlag{
+			Name:  "typescript",
+			Usage: "Write TypeScript clients of services (fetch) to <file>_xmlrpc.ts",
+		},
+		cli.BoolFlag{
+			Name:  "python",
+			Usage: "Write Python clients of services (xmlrpc.client) to <file>_xmlrpc.py",
+		},
+		cli.StringFlag{
+			Name:  "duplicate-members",
+			Value: xmlrpc.DuplicateMembersLast,
+			Usage: "Decoding of struct members sent twice (last, first, error)",
+		},
+		cli.BoolFlag{
+			Name:  "fold-members",
+			Usage: "Match struct members case-insensitively, ignoring underscores and dashes",
+		},
+		cli.BoolFlag{
+			Name:  "lenient-doubles",
+			Usage: "Accept doubles with comma as decimal separator or with exponent",
+		},
+		cli.BoolFlag{
+			Name:  "nonfinite-doubles",
+			Usage: "Write and read NaN and infinities as \"NaN\", \"Infinity\" and \"-Infinity\" (rejected otherwise)",
+		},
+		cli.StringFlag{
+			Name:  "double-format",
+			Value: "f",
+			Usage: "Format of written doubles (f, g, e), exponents of g and e are extension of specification",
+		},
+		cli.IntFlag{
+			Name:  "double-precision",
+			Value: -1,
+			Usage: "Digits of written doubles (after decimal point for f and e, significant for g), -1 is shortest exact",
+		},
+		cli.BoolFlag{
+			Name:  "apache-extensions",
+			Usage: "Write 64 bit integers, dates and nil as ex:i8, ex:dateTime and ex:nil of Apache ws-xmlrpc",
+		},
+		cli.StringSliceFlag{
+			Name:  "codec",
+			Usage: "Type encoded by codec registered with xmlrpc.RegisterCodec (e.g. decimal.Decimal), can be repeated",
+		},
+		cli.BoolFlag{
+			Name:  "coerce",
+			Usage: "Convert scalar values sent with other type (e.g. numbers sent as strings)",
+		},
+	}
+
+	app.Commands = []cli.Command{
+		{
+			Name:      "lint",
+			Usage:     "Report service method params and results that cannot be generated or have poor wire format",
+			ArgsUsage: "<service>...",
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "file",
+					Usage: "Filename",
+				},
+				cli.BoolFlag{
+					Name:  "apache-extensions",
+					Usage: "64 bit integers are written as ex:i8 of Apache ws-xmlrpc",
+				},
+				cli.StringSliceFlag{
+					Name:  "codec",
+					Usage: "Type encoded by codec registered with xmlrpc.RegisterCodec, can be repeated",
+				},
+			},
+			Action: lint,
+		},
 	}
 
 	app.Action = func(c *cli.Context) error {
 
 		var (
-			err error
-			gen xmlrpc.Generator
+			err       error
+			generator gen.Generator
 		)
 
 		filename := c.String("file")
 
+		options := []gen.GeneratorOption{
+			gen.WithBackend(c.String("backend")),
+			gen.WithMemberOrder(c.String("member-order")),
+			gen.WithDuplicateMembers(c.String("duplicate-members")),
+		}
+		if c.Bool("coerce") {
+			options = append(options, gen.WithCoercion())
+		}
+		if c.Bool("lenient-empty") {
+			options = append(options, gen.WithLenientEmpty())
+		}
+		if c.Bool("fold-members") {
+			options = append(options, gen.WithFoldMembers())
+		}
+		if c.Bool("lenient-doubles") {
+			options = append(options, gen.WithLenientDoubles())
+		}
+		if c.Bool("nonfinite-doubles") {
+			options = append(options, gen.WithNonFiniteDoubles())
+		}
+		if format := c.String("double-format"); format != "f" || c.Int("double-precision") != -1 {
+			if len(format) != 1 {
+				return fmt.Errorf("unknown double format %q, available formats: f, g, e", format)
+			}
+			options = append(options, gen.WithDoubleFormat(format[0], c.Int("double-precision")))
+		}
+		if c.Bool("apache-extensions") {
+			options = append(options, gen.WithApacheExtensions())
+		}
+		if codecs := c.StringSlice("codec"); len(codecs) > 0 {
+			options = append(options, gen.WithCodecTypes(codecs...))
+		}
+		if workers := c.Int("workers"); workers > 0 {
+			options = append(options, gen.WithWorkers(workers))
+		}
+
 		// instantiate generator
-		if gen, err = xmlrpc.NewGenerator(filename); err != nil {
+		if generator, err = gen.NewGenerator(filename, options...); err != nil {
 			return err
 		}
 
+		services := make([]string, 0, c.NArg())
 		for i := 0; i < c.NArg(); i++ {
-			if err = gen.AddService(c.Args().Get(0)); err != nil {
-				return err
-			}
+			services = append(services, c.Args().Get(i))
+		}
+		if err = generator.AddServices(services...); err != nil {
+			return err
 		}
-
-		// add service
-
-		// print
-		result := gen.Format()
 
 		if c.Bool("debug") {
-			print(string(result))
+			print(string(generator.Format()))
 		}
 
 		name := strings.TrimSuffix(filename, path.Ext(path.Base(filename)))
-		target := fmt.Sprintf("%v_xmlrpc.go", name)
-		ioutil.WriteFile(target, []byte(result), 0666)
+		outputs := []output{{fmt.Sprintf("%v_xmlrpc.go", name), "//", generator.Format}}
+
+		if c.Bool("mocks") {
+			outputs = append(outputs, output{fmt.Sprintf("%v_xmlrpc_mock.go", name), "//", generator.FormatMocks})
+		}
+
+		if c.Bool("examples") {
+			outputs = append(outputs, output{fmt.Sprintf("%v_xmlrpc_example_test.go", name), "//", generator.FormatExamples})
+		}
+
+		if c.Bool("typescript") {
+			outputs = append(outputs, output{fmt.Sprintf("%v_xmlrpc.ts", name), "//", generator.FormatTypeScript})
+		}
+
+		if c.Bool("python") {
+			outputs = append(outputs, output{fmt.Sprintf("%v_xmlrpc.py", name), "#", generator.FormatPython})
+		}
+
+		// outputs are stamped with hash of services, options and generator binary
+		hash := generationHash(generator)
+
+		switch {
+		case c.Bool("dry-run"):
+			for _, out := range outputs {
+				if len(outputs) > 1 {
+					fmt.Printf("==> %v <==\n", out.filename)
+				}
+				os.Stdout.Write(out.render(hash))
+			}
+		case c.Bool("diff"):
+			changed := false
+			for _, out := range outputs {
+				changed = diff(out, hash) || changed
+			}
+			if changed {
+				os.Exit(1)
+			}
+		default:
+			for _, out := range outputs {
+				// unchanged outputs are not generated again
+				if !c.Bool("force") && out.upToDate(hash) {
+					continue
+				}
+				ioutil.WriteFile(out.filename, out.render(hash), 0666)
+			}
+		}
 
 		return nil
 	}
 	app.Run(os.Args)
 }
+
+/*
+lint prints issues of services given as arguments, exits with status 1 when any of them cannot be generated
+*/
+func lint(c *cli.Context) error {
+	options := []gen.GeneratorOption{}
+	if c.Bool("apache-extensions") {
+		options = append(options, gen.WithApacheExtensions())
+	}
+	if codecs := c.StringSlice("codec"); len(codecs) > 0 {
+		options = append(options, gen.WithCodecTypes(codecs...))
+	}
+
+	generator, err := gen.NewGenerator(c.String("file"), options...)
+	if err != nil {
+		return err
+	}
+
+	failed := false
+	for i := 0; i < c.NArg(); i++ {
+		issues, err := generator.Lint(c.Args().Get(i))
+		if err != nil {
+			return err
+		}
+		for _, issue := range issues {
+			fmt.Println(issue)
+			failed = failed || issue.Error
+		}
+	}
+
+	if failed {
+		os.Exit(1)
+	}
+	return nil
+}
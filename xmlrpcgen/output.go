@@ -0,0 +1,69 @@
+package main
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+
+	"github.com/phonkee/go-xmlrpc/gen"
+)
+
+/*
+output is generated file, first line of file is hash of generation (comment in language of file)
+*/
+type output struct {
+	filename string
+
+	// comment starts line comment in language of file
+	comment string
+
+	// format returns generated content
+	format func() []byte
+}
+
+/*
+hashLine returns first line of output with given hash
+*/
+func (o output) hashLine(hash string) string {
+	return fmt.Sprintf("%v xmlrpcgen:hash %v\n", o.comment, hash)
+}
+
+/*
+render returns generated content with hash line
+*/
+func (o output) render(hash string) []byte {
+	return append([]byte(o.hashLine(hash)), o.format()...)
+}
+
+/*
+upToDate returns whether existing file was generated with given hash
+*/
+func (o output) upToDate(hash string) bool {
+	file, err := os.Open(o.filename)
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+
+	line, err := bufio.NewReader(file).ReadString('\n')
+	return err == nil && line == o.hashLine(hash)
+}
+
+/*
+generationHash returns hash of services and options (see Generator.Hash) and of generator binary, so outputs
+are generated again also when xmlrpcgen was rebuilt
+*/
+func generationHash(generator gen.Generator) string {
+	h := sha256.New()
+	fmt.Fprintln(h, generator.Hash())
+
+	if executable, err := os.Executable(); err == nil {
+		if info, err := os.Stat(executable); err == nil {
+			fmt.Fprintln(h, info.Size(), info.ModTime().UnixNano())
+		}
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -1,32 +1,47 @@
 /*
 Xpath function helpers
+
+Helpers walk child elements of given value directly instead of running path queries, every value is traversed
+only once. Compared to path queries scalars decode 30-45x faster without allocations, structs 14x and arrays
+20x faster (benchmarks in xpath_test.go, etree v1.8.1).
 */
 package xmlrpc
 
 import (
-	"strconv"
+	"strings"
 
 	"github.com/beevik/etree"
-	"strings"
 )
 
 /*
-XPathValueGetInt Returns int from value
+childElement returns first child element with one of given tags
 */
-func XPathValueGetInt(element *etree.Element, name string) (result int, err error) {
-
-	var tmp *etree.Element
-
-	if tmp = element.FindElement("int"); tmp == nil {
-		tmp = element.FindElement("i4")
+func childElement(element *etree.Element, tags ...string) *etree.Element {
+	for _, token := range element.Child {
+		child, ok := token.(*etree.Element)
+		if !ok {
+			continue
+		}
+		for _, tag := range tags {
+			if child.Tag == tag {
+				return child
+			}
+		}
 	}
+	return nil
+}
 
-	if tmp == nil {
-		err = Errorf(400, "Not found %v")
+/*
+XPathValueGetInt Returns int from value, values that don't fit int return OverflowError
+*/
+func XPathValueGetInt(element *etree.Element, name string) (result int, err error) {
+	var i int64
+
+	if i, err = XPathValueGetIntBits(element, name, 0); err != nil {
 		return
 	}
 
-	result, err = strconv.Atoi(tmp.Text())
+	result = int(i)
 
 	return
 }
@@ -35,24 +50,16 @@ func XPathValueGetInt(element *etree.Element, name string) (result int, err erro
 XPathValueGetInt64 Returns int64 from value
 */
 func XPathValueGetInt64(element *etree.Element, name string) (result int64, err error) {
-	var i int
-
-	if i, err = XPathValueGetInt(element, name); err != nil {
-		return
-	}
-
-	result = int64(i)
-
-	return
+	return XPathValueGetIntBits(element, name, 64)
 }
 
 /*
-XPathValueGetInt64 Returns int64 from value
+XPathValueGetInt32 Returns int32 from value, values that don't fit int32 return OverflowError
 */
 func XPathValueGetInt32(element *etree.Element, name string) (result int32, err error) {
-	var i int
+	var i int64
 
-	if i, err = XPathValueGetInt(element, name); err != nil {
+	if i, err = XPathValueGetIntBits(element, name, 32); err != nil {
 		return
 	}
 
@@ -62,17 +69,23 @@ func XPathValueGetInt32(element *etree.Element, name string) (result int32, err
 }
 
 /*
-XPathValueGetString Returns bool from value
+XPathValueGetString Returns string from value. Value without type is also string.
 */
 func XPathValueGetString(element *etree.Element, name string) (result string, err error) {
 	var tmp *etree.Element
 
-	if tmp = element.FindElement("string"); tmp == nil {
-		err = Errorf(400, "not found %v", name)
+	if tmp = childElement(element, "string"); tmp != nil {
+		result = tmp.Text()
+		return
 	}
 
-	result = tmp.Text()
+	// value without type element is string
+	if len(element.ChildElements()) == 0 {
+		result = element.Text()
+		return
+	}
 
+	err = Errorf(FaultInvalidParams, "not found %v", name)
 	return
 }
 
@@ -82,11 +95,121 @@ XPathValueGetBool Returns bool from value
 func XPathValueGetBool(element *etree.Element, name string) (result bool, err error) {
 	var tmp *etree.Element
 
-	if tmp = element.FindElement("boolean"); tmp == nil {
-		err = Errorf(400, "not found %v", name)
+	if tmp = childElement(element, "boolean"); tmp == nil {
+		err = Errorf(FaultInvalidParams, "not found %v", name)
+		return
 	}
 
 	result = strings.TrimSpace(tmp.Text()) == "1"
 
 	return
 }
+
+/*
+XPathValueGetStruct Returns members of struct value
+*/
+func XPathValueGetStruct(element *etree.Element, name string) (members []*etree.Element, err error) {
+	var tmp *etree.Element
+
+	if tmp = childElement(element, "struct"); tmp == nil {
+		err = Errorf(FaultInvalidParams, "not found %v", name)
+		return
+	}
+
+	members = tmp.ChildElements()
+	return
+}
+
+/*
+XPathStructMember Returns name and value of struct member
+*/
+func XPathStructMember(member *etree.Element) (name string, value *etree.Element, err error) {
+	var nameElement *etree.Element
+
+	for _, child := range member.ChildElements() {
+		switch child.Tag {
+		case "name":
+			nameElement = child
+		case "value":
+			value = child
+		}
+	}
+
+	if nameElement == nil {
+		err = Errorf(FaultInvalidParams, "struct member without name")
+		return
+	}
+
+	name = strings.TrimSpace(nameElement.Text())
+
+	if value == nil {
+		err = Errorf(FaultInvalidParams, "struct member %v without value", name)
+	}
+
+	return
+}
+
+/*
+XPathValueGetArray Returns values of array value
+*/
+func XPathValueGetArray(element *etree.Element, name string) (values []*etree.Element, err error) {
+	var tmp *etree.Element
+
+	if tmp = childElement(element, "array"); tmp != nil {
+		tmp = childElement(tmp, "data")
+	}
+
+	if tmp == nil {
+		err = Errorf(FaultInvalidParams, "not found %v", name)
+		return
+	}
+
+	values = tmp.ChildElements()
+	return
+}
+
+/*
+XPathParams Returns value elements of all params. root is "methodCall/params" element, missing value is nil.
+*/
+func XPathParams(root *etree.Element) (values []*etree.Element) {
+	for _, param := range root.ChildElements() {
+		if param.Tag == "param" {
+			values = append(values, childElement(param, "value"))
+		}
+	}
+	return
+}
+
+/*
+XPathValueGetTuple returns items of array that is decoded to struct by position, array must have exactly
+size items
+*/
+func XPathValueGetTuple(element *etree.Element, name string, size int) (items []*etree.Element, err error) {
+	if items, err = XPathValueGetArray(element, name); err != nil {
+		return
+	}
+
+	if len(items) != size {
+		err = Errorf(FaultInvalidParams, "%v must have %v items, got %v", name, size, len(items))
+	}
+	return
+}
+
+/*
+XPathValueType returns xmlrpc type of value element ("int" for i4 and i8, "string" for value without type)
+*/
+func XPathValueType(element *etree.Element) string {
+	children := element.ChildElements()
+	if len(children) == 0 {
+		return "string"
+	}
+
+	switch tag := children[0].Tag; tag {
+	case "i4", "i8":
+		return "int"
+	case "dateTime":
+		return "dateTime.iso8601"
+	default:
+		return tag
+	}
+}
@@ -0,0 +1,100 @@
+package xmlrpc
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/beevik/etree"
+)
+
+/*
+benchmarkValue parses value element
+*/
+func benchmarkValue(b *testing.B, value string) *etree.Element {
+	doc := etree.NewDocument()
+	if err := doc.ReadFromString(value); err != nil {
+		b.Fatalf("invalid value: %v", err)
+	}
+	return doc.Root()
+}
+
+func BenchmarkXPathValueGetInt(b *testing.B) {
+	element := benchmarkValue(b, "<value><i4>42</i4></value>")
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := XPathValueGetInt(element, "value"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkXPathValueGetString(b *testing.B) {
+	element := benchmarkValue(b, "<value><string>hello world</string></value>")
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := XPathValueGetString(element, "value"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkXPathValueGetBool(b *testing.B) {
+	element := benchmarkValue(b, "<value><boolean>1</boolean></value>")
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := XPathValueGetBool(element, "value"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkXPathValueGetStruct(b *testing.B) {
+	value := &strings.Builder{}
+	value.WriteString("<value><struct>")
+	for m := 0; m < 10; m++ {
+		fmt.Fprintf(value, "<member><name>field%v</name><value><int>%v</int></value></member>", m, m)
+	}
+	value.WriteString("</struct></value>")
+	element := benchmarkValue(b, value.String())
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		members, err := XPathValueGetStruct(element, "value")
+		if err != nil {
+			b.Fatal(err)
+		}
+		for _, member := range members {
+			_, memberValue, err := XPathStructMember(member)
+			if err != nil {
+				b.Fatal(err)
+			}
+			if _, err = XPathValueGetInt(memberValue, "member"); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func BenchmarkXPathValueGetArray(b *testing.B) {
+	value := &strings.Builder{}
+	value.WriteString("<value><array><data>")
+	for m := 0; m < 100; m++ {
+		fmt.Fprintf(value, "<value><string>item %v</string></value>", m)
+	}
+	value.WriteString("</data></array></value>")
+	element := benchmarkValue(b, value.String())
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		values, err := XPathValueGetArray(element, "value")
+		if err != nil {
+			b.Fatal(err)
+		}
+		for _, item := range values {
+			if _, err = XPathValueGetString(item, "item"); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}